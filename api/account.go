@@ -15,7 +15,7 @@ type createAccountRequest struct {
 func (s *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -26,19 +26,24 @@ func (s *Server) createAccount(ctx *gin.Context) {
 		Currency: req.Currency,
 		Balance:  0,
 	}
-	account, err := s.store.CreateAccount(ctx, arg)
+	result, err := s.store.CreateAccountTx(ctx, arg)
 	if err != nil {
 		errCode := db.ErrorCode(err)
 		if errCode == db.UniqueViolationCode || errCode == db.ForeignKeyViolationCode {
-			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			errorResponse(ctx, http.StatusForbidden, err)
 			return
 
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, result.Account)
+}
+
+type accountResponse struct {
+	db.Account
+	PocketsBalance int64 `json:"pockets_balance"`
 }
 
 type getAccountRequest struct {
@@ -48,26 +53,34 @@ type getAccountRequest struct {
 func (s *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(ctx, http.StatusBadRequest, err)
 		return
 	}
 	account, err := s.store.GetAccount(ctx, req.ID)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			errorResponse(ctx, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); authPayload.Username != account.Owner {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, ok := s.authorizeAccountMember(ctx, membershipAccountID(account), authPayload.Username, accountViewRoles); !ok {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	rsp := accountResponse{Account: account}
+	if !account.ParentID.Valid {
+		rsp.PocketsBalance, err = s.store.GetPocketsBalance(ctx, account.ID)
+		if err != nil {
+			errorResponse(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type listAccountsRequest struct {
@@ -78,16 +91,16 @@ type listAccountsRequest struct {
 func (s *Server) listAccounts(ctx *gin.Context) {
 	var req listAccountsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	arg := db.ListAccountsParams{Owner: authPayload.Username, Limit: req.PageSize, Offset: (req.PageID - 1) * req.PageSize}
-	accounts, err := s.store.ListAccounts(ctx, arg)
+	arg := db.ListMemberAccountsParams{Username: authPayload.Username, Limit: req.PageSize, Offset: (req.PageID - 1) * req.PageSize}
+	accounts, err := s.store.ListMemberAccounts(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
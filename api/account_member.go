@@ -0,0 +1,172 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/worker"
+	"net/http"
+	"slices"
+	"time"
+)
+
+var (
+	accountViewRoles     = []string{util.AccountOwnerRole, util.AccountSignerRole, util.AccountViewerRole}
+	accountTransferRoles = []string{util.AccountOwnerRole, util.AccountSignerRole}
+	accountManageRoles   = []string{util.AccountOwnerRole}
+)
+
+// authorizeAccountMember checks that the user is a member of the account with one of the given roles
+func (s *Server) authorizeAccountMember(ctx *gin.Context, accountID int64, username string, roles []string) (db.AccountMember, bool) {
+	member, err := s.store.GetAccountMember(ctx, db.GetAccountMemberParams{AccountID: accountID, Username: username})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err := errors.New("account doesn't belong to the authenticated user")
+			errorResponse(ctx, http.StatusUnauthorized, err)
+			return member, false
+		}
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return member, false
+	}
+
+	if !slices.Contains(roles, member.Role) {
+		err := errors.New("account member doesn't have the required permission")
+		errorResponse(ctx, http.StatusUnauthorized, err)
+		return member, false
+	}
+
+	return member, true
+}
+
+type accountMembersUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) listAccountMembers(ctx *gin.Context) {
+	var uri accountMembersUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, ok := s.authorizeAccountMember(ctx, uri.ID, authPayload.Username, accountViewRoles); !ok {
+		return
+	}
+
+	members, err := s.store.ListAccountMembers(ctx, uri.ID)
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+type addAccountMemberRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Role     string `json:"role" binding:"required,account_role"`
+}
+
+// addAccountMember an account owner can invite another user to the account, the invitee is notified by email
+func (s *Server) addAccountMember(ctx *gin.Context) {
+	var uri accountMembersUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req addAccountMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, ok := s.authorizeAccountMember(ctx, uri.ID, authPayload.Username, accountManageRoles); !ok {
+		return
+	}
+
+	arg := db.AddAccountMemberTxParams{
+		CreateAccountMemberParams: db.CreateAccountMemberParams{
+			AccountID: uri.ID,
+			Username:  req.Username,
+			Role:      req.Role,
+			InvitedBy: authPayload.Username,
+		},
+		AfterCreate: func(member db.AccountMember) error {
+			taskPayload := &worker.PayloadSendAccountInvitation{AccountID: member.AccountID, Username: member.Username}
+			opts := []asynq.Option{
+				asynq.MaxRetry(10),
+				asynq.ProcessIn(10 * time.Second),
+				asynq.Queue(worker.QueueDefault),
+			}
+
+			return s.taskDistributor.DistributeTaskSendAccountInvitation(ctx, taskPayload, opts...)
+		},
+	}
+
+	result, err := s.store.AddAccountMemberTx(ctx, arg)
+	if err != nil {
+		errCode := db.ErrorCode(err)
+		if errCode == db.UniqueViolationCode || errCode == db.ForeignKeyViolationCode {
+			errorResponse(ctx, http.StatusForbidden, err)
+			return
+		}
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Member)
+}
+
+type removeAccountMemberUri struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// removeAccountMember an account owner can remove any member except the owner the account was opened by
+func (s *Server) removeAccountMember(ctx *gin.Context) {
+	var uri removeAccountMemberUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	account, err := s.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, http.StatusNotFound, err)
+			return
+		}
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, ok := s.authorizeAccountMember(ctx, account.ID, authPayload.Username, accountManageRoles); !ok {
+		return
+	}
+
+	if uri.Username == account.Owner {
+		err := errors.New("cannot remove the primary owner of the account")
+		errorResponse(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	deleted, err := s.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{AccountID: account.ID, Username: uri.Username})
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if deleted == 0 {
+		err := errors.New("user is not a member of the account")
+		errorResponse(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
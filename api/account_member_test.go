@@ -0,0 +1,288 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/worker"
+	mockwk "github.com/mariobasic/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type eqAddAccountMemberTxParamsMatcher struct {
+	arg db.AddAccountMemberTxParams
+}
+
+func (e eqAddAccountMemberTxParamsMatcher) Matches(x any) bool {
+	actualArg, ok := x.(db.AddAccountMemberTxParams)
+	if !ok {
+		return false
+	}
+
+	if !reflect.DeepEqual(e.arg.CreateAccountMemberParams, actualArg.CreateAccountMemberParams) {
+		return false
+	}
+
+	err := actualArg.AfterCreate(db.AccountMember{
+		AccountID: actualArg.AccountID,
+		Username:  actualArg.Username,
+		Role:      actualArg.Role,
+		InvitedBy: actualArg.InvitedBy,
+	})
+	return err == nil
+}
+
+func (e eqAddAccountMemberTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v", e.arg)
+}
+
+func EqAddAccountMemberTxParams(arg db.AddAccountMemberTxParams) gomock.Matcher {
+	return eqAddAccountMemberTxParamsMatcher{arg}
+}
+
+func TestServer_addAccountMember(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	tests := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"username": invitee.Username,
+				"role":     util.AccountSignerRole,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, owner.Username, owner.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: owner.Username})).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+
+				arg := db.AddAccountMemberTxParams{
+					CreateAccountMemberParams: db.CreateAccountMemberParams{
+						AccountID: account.ID,
+						Username:  invitee.Username,
+						Role:      util.AccountSignerRole,
+						InvitedBy: owner.Username,
+					},
+				}
+				store.EXPECT().
+					AddAccountMemberTx(gomock.Any(), EqAddAccountMemberTxParams(arg)).
+					Times(1).
+					Return(db.AddAccountMemberTxResult{Member: randomAccountMember(account.ID, invitee.Username, util.AccountSignerRole)}, nil)
+
+				taskPayload := &worker.PayloadSendAccountInvitation{AccountID: account.ID, Username: invitee.Username}
+				tskDist.EXPECT().
+					DistributeTaskSendAccountInvitation(gomock.Any(), taskPayload, gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "NotAnOwner",
+			body: gin.H{
+				"username": invitee.Username,
+				"role":     util.AccountViewerRole,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, owner.Username, owner.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountSignerRole), nil)
+				store.EXPECT().AddAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+				tskDist.EXPECT().DistributeTaskSendAccountInvitation(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
+		{
+			name: "AlreadyMember",
+			body: gin.H{
+				"username": invitee.Username,
+				"role":     util.AccountViewerRole,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, owner.Username, owner.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					AddAccountMemberTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AddAccountMemberTxResult{}, &pgconn.PgError{Code: db.UniqueViolationCode})
+				tskDist.EXPECT().DistributeTaskSendAccountInvitation(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "InvalidRole",
+			body: gin.H{
+				"username": invitee.Username,
+				"role":     "admin",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, owner.Username, owner.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AddAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			tskCtrl := gomock.NewController(t)
+			tskDist := mockwk.NewMockTaskDistributor(tskCtrl)
+			tt.buildStubs(store, tskDist)
+
+			server := NewTestServer(t, store, tskDist)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/members", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tt.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tt.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestServer_removeAccountMember(t *testing.T) {
+	owner, _ := randomUser(t)
+	member, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: member.Username})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name:     "NotMember",
+			username: member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, resp.Code)
+			},
+		},
+		{
+			name:     "PrimaryOwner",
+			username: owner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/members/%s", account.ID, tt.username)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, owner.Username, owner.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tt.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomAccountMember(accountID int64, username string, role string) db.AccountMember {
+	return db.AccountMember{
+		AccountID: accountID,
+		Username:  username,
+		Role:      role,
+		InvitedBy: username,
+	}
+}
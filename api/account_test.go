@@ -42,10 +42,43 @@ func TestServer_getAccount(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: user.Username})).
+					Times(1).
+					Return(randomAccountMember(account.ID, user.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					GetPocketsBalance(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(100), nil)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, resp.Code)
-				requireBodyMatch(t, resp.Body, account)
+				requireBodyMatch(t, resp.Body, accountResponse{Account: account, PocketsBalance: 100})
+			},
+		},
+		{
+			name:      "OKViewer",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "viewer_user", user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: "viewer_user"})).
+					Times(1).
+					Return(randomAccountMember(account.ID, "viewer_user", util.AccountViewerRole), nil)
+				store.EXPECT().
+					GetPocketsBalance(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(100), nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+				requireBodyMatch(t, resp.Body, accountResponse{Account: account, PocketsBalance: 100})
 			},
 		},
 		{
@@ -59,6 +92,10 @@ func TestServer_getAccount(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, pgx.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, resp.Code)
@@ -134,7 +171,7 @@ func TestServer_getAccount(t *testing.T) {
 			tt.buildStubs(store)
 
 			// start test sever and send request
-			server := NewTestServer(t, store)
+			server := NewTestServer(t, store, nil)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/accounts/%d", tt.accountID)
@@ -174,9 +211,12 @@ func TestServer_CreateAccount(t *testing.T) {
 					Balance:  0,
 				}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(account, nil)
+					Return(db.CreateAccountTxResult{
+						Account: account,
+						Member:  randomAccountMember(account.ID, account.Owner, util.AccountOwnerRole),
+					}, nil)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, resp.Code)
@@ -193,7 +233,7 @@ func TestServer_CreateAccount(t *testing.T) {
 			tt.buildStubs(store)
 
 			// start test sever and send request
-			server := NewTestServer(t, store)
+			server := NewTestServer(t, store, nil)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tt.body)
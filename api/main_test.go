@@ -4,19 +4,19 @@ import (
 	"github.com/gin-gonic/gin"
 	db "github.com/mariobasic/simplebank/db/sqlc"
 	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/worker"
 	"os"
 	"testing"
 	"time"
 )
 
-func NewTestServer(_ *testing.T, store db.Store) *Server {
-	config := util.Config{Token: struct {
-		SymmetricKey    string        `mapstructure:"symmetric_key"`
-		AccessDuration  time.Duration `mapstructure:"access_duration"`
-		RefreshDuration time.Duration `mapstructure:"refresh_duration"`
-	}{SymmetricKey: util.RandomString(32), AccessDuration: time.Minute, RefreshDuration: time.Hour}}
+func NewTestServer(_ *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
+	config := util.Config{}
+	config.Token.SymmetricKey = util.RandomString(32)
+	config.Token.AccessDuration = time.Minute
+	config.Token.RefreshDuration = time.Hour
 
-	return NewServer(config, store)
+	return NewServer(config, store, taskDistributor)
 }
 
 func TestMain(m *testing.M) {
@@ -12,25 +12,34 @@ const authorizationHeaderKey = "Authorization"
 const authorizationTypeBearer = "Bearer"
 const authorizationPayloadKey = "authorization_payload"
 
-func authMiddleware(token token.Maker) gin.HandlerFunc {
+func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		header := c.GetHeader(authorizationHeaderKey)
 		if len(header) == 0 {
 			err := errors.New("authorization header is empty")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			c.Abort()
+			errorResponse(c, http.StatusUnauthorized, err)
 			return
 		}
 
 		fields := strings.Fields(header)
 		if len(fields) != 2 || fields[0] != authorizationTypeBearer {
 			err := errors.New("invalid authorization header")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			c.Abort()
+			errorResponse(c, http.StatusUnauthorized, err)
 			return
 		}
 
-		payload, err := token.VerifyToken(fields[1])
+		payload, err := tokenMaker.VerifyToken(fields[1])
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			c.Abort()
+			errorResponse(c, http.StatusUnauthorized, err)
+			return
+		}
+		// refresh tokens of the session are rejected, they are only good for renewing it
+		if err = payload.CheckType(token.TokenTypeAccess); err != nil {
+			c.Abort()
+			errorResponse(c, http.StatusUnauthorized, err)
 			return
 		}
 		c.Set(authorizationPayloadKey, payload)
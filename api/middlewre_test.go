@@ -1,8 +1,11 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mariobasic/simplebank/problem"
 	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/stretchr/testify/require"
@@ -35,6 +38,7 @@ func Test_authMiddleware(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblem(t, recorder, problem.Unauthenticated)
 			},
 		},
 		{
@@ -64,10 +68,21 @@ func Test_authMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken("user", util.DepositorRole, uuid.New(), token.TokenTypeRefresh, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := NewTestServer(t, nil)
+			server := NewTestServer(t, nil, nil)
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
@@ -96,8 +111,19 @@ func addAuthorization(
 	role string,
 	duration time.Duration,
 ) {
-	createToken, payload, err := tokenMaker.CreateToken(username, role, duration)
+	createToken, payload, err := tokenMaker.CreateToken(username, role, uuid.New(), token.TokenTypeAccess, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, createToken))
 }
+
+func requireProblem(t *testing.T, recorder *httptest.ResponseRecorder, typ problem.Type) {
+	require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+	require.Equal(t, typ.URI(), p.Type)
+	require.Equal(t, typ.Code, p.Code)
+	require.Equal(t, recorder.Code, p.Status)
+	require.NotEmpty(t, p.Detail)
+}
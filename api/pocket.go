@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/token"
+	"net/http"
+)
+
+// membershipAccountID pockets have no members of their own, access is granted by the members of the main account
+func membershipAccountID(account db.Account) int64 {
+	if account.ParentID.Valid {
+		return account.ParentID.Int64
+	}
+	return account.ID
+}
+
+type pocketsUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type createPocketRequest struct {
+	Name       string `json:"name" binding:"required,max=64"`
+	GoalAmount int64  `json:"goal_amount" binding:"min=0"`
+}
+
+// createPocket members who can transfer money from the main account can set money aside in a new pocket
+func (s *Server) createPocket(ctx *gin.Context) {
+	var uri pocketsUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req createPocketRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	parent, err := s.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, http.StatusNotFound, err)
+			return
+		}
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if parent.ParentID.Valid {
+		err := errors.New("pockets cannot be nested")
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, ok := s.authorizeAccountMember(ctx, parent.ID, authPayload.Username, accountTransferRoles); !ok {
+		return
+	}
+
+	pocket, err := s.store.CreatePocket(ctx, db.CreatePocketParams{
+		Name:       req.Name,
+		GoalAmount: req.GoalAmount,
+		ParentID:   parent.ID,
+	})
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolationCode {
+			errorResponse(ctx, http.StatusForbidden, err)
+			return
+		}
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pocket)
+}
+
+func (s *Server) listPockets(ctx *gin.Context) {
+	var uri pocketsUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, ok := s.authorizeAccountMember(ctx, uri.ID, authPayload.Username, accountViewRoles); !ok {
+		return
+	}
+
+	pockets, err := s.store.ListPockets(ctx, uri.ID)
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pockets)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServer_createPocket(t *testing.T) {
+	owner, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	pocket := randomPocket(account)
+
+	tests := []struct {
+		name          string
+		accountID     int64
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			body:      gin.H{"name": pocket.Name, "goal_amount": pocket.GoalAmount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: owner.Username})).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					CreatePocket(gomock.Any(), gomock.Eq(db.CreatePocketParams{Name: pocket.Name, GoalAmount: pocket.GoalAmount, ParentID: account.ID})).
+					Times(1).
+					Return(pocket, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+				requireBodyMatch(t, resp.Body, pocket)
+			},
+		},
+		{
+			name:      "NestedPocket",
+			accountID: pocket.ID,
+			body:      gin.H{"name": "holidays"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name:      "ViewerNotAllowed",
+			accountID: account.ID,
+			body:      gin.H{"name": pocket.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountViewerRole), nil)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
+		{
+			name:      "DuplicateName",
+			accountID: account.ID,
+			body:      gin.H{"name": pocket.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					CreatePocket(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name:      "InvalidGoal",
+			accountID: account.ID,
+			body:      gin.H{"name": pocket.Name, "goal_amount": -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pockets", tt.accountID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, owner.Username, owner.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tt.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestServer_listPockets(t *testing.T) {
+	owner, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	pockets := []db.Account{randomPocket(account), randomPocket(account)}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: owner.Username})).
+		Times(1).
+		Return(randomAccountMember(account.ID, owner.Username, util.AccountViewerRole), nil)
+	store.EXPECT().
+		ListPockets(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return(pockets, nil)
+
+	server := NewTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/accounts/%d/pockets", account.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, owner.Username, owner.Role, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyMatch(t, recorder.Body, pockets)
+}
+
+func randomPocket(parent db.Account) db.Account {
+	return db.Account{
+		ID:         util.RandomInt(1001, 2000),
+		Owner:      parent.Owner,
+		Currency:   parent.Currency,
+		ParentID:   pgtype.Int8{Int64: parent.ID, Valid: true},
+		Name:       util.RandomString(8),
+		GoalAmount: util.RandomMoney(),
+	}
+}
@@ -1,35 +1,43 @@
 package api
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/problem"
 	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/worker"
 	"log"
 )
 
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config          util.Config
+	store           db.Store
+	tokenMaker      token.Maker
+	taskDistributor worker.TaskDistributor
+	router          *gin.Engine
 }
 
-func NewServer(config util.Config, store db.Store) *Server {
+func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) *Server {
 	tokenMaker, err := token.NewPasetoMaker(config.Token.SymmetricKey)
 	//tokenMaker, err := token.NewJWTMaker(config.Token.SymmetricKey)
 	if err != nil {
 		log.Fatal(err)
 	}
-	server := &Server{config: config, store: store, tokenMaker: tokenMaker}
+	server := &Server{config: config, store: store, tokenMaker: tokenMaker, taskDistributor: taskDistributor}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		err := v.RegisterValidation("currency", validCurrency)
 		if err != nil {
 			log.Fatalf("error registering validation: %v", err)
 		}
+		err = v.RegisterValidation("account_role", validAccountRole)
+		if err != nil {
+			log.Fatalf("error registering validation: %v", err)
+		}
 	}
 
 	setupRouter(server)
@@ -48,6 +56,11 @@ func setupRouter(server *Server) {
 	authRouter.POST("/accounts", server.createAccount)
 	authRouter.GET("/accounts/:id", server.getAccount)
 	authRouter.GET("/accounts", server.listAccounts)
+	authRouter.GET("/accounts/:id/members", server.listAccountMembers)
+	authRouter.POST("/accounts/:id/members", server.addAccountMember)
+	authRouter.DELETE("/accounts/:id/members/:username", server.removeAccountMember)
+	authRouter.GET("/accounts/:id/pockets", server.listPockets)
+	authRouter.POST("/accounts/:id/pockets", server.createPocket)
 
 	authRouter.POST("/transfers", server.createTransfer)
 
@@ -59,6 +72,21 @@ func (s *Server) Start(address string) error {
 
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// errorResponse renders the error as RFC 7807 problem details, like the errors of the gateway
+func errorResponse(ctx *gin.Context, httpStatus int, err error) {
+	p := problem.New(problem.ForHTTPStatus(httpStatus), err.Error())
+	p.Instance = ctx.Request.URL.Path
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldErr := range validationErrors {
+			p.InvalidParams = append(p.InvalidParams, problem.InvalidParam{
+				Name:   fieldErr.Field(),
+				Reason: fieldErr.Error(),
+			})
+		}
+	}
+
+	ctx.Header("Content-Type", problem.ContentType)
+	ctx.JSON(httpStatus, p)
 }
@@ -3,7 +3,10 @@ package api
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
 	"net/http"
 	"time"
 )
@@ -13,55 +16,98 @@ type renewAccessTokenRequest struct {
 }
 
 type renewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
 func (s *Server) renewAccessToken(ctx *gin.Context) {
 	var req renewAccessTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		errorResponse(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
 	refreshPayload, err := s.tokenMaker.VerifyToken(req.RefreshToken)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		errorResponse(ctx, http.StatusUnauthorized, err)
+		return
+	}
+	if err = refreshPayload.CheckType(token.TokenTypeRefresh); err != nil {
+		errorResponse(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
-	session, err := s.store.GetSession(ctx, refreshPayload.ID)
+	session, err := s.store.GetSession(ctx, refreshPayload.SessionID)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			errorResponse(ctx, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	if session.IsBlocked {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("session is blocked")))
+		errorResponse(ctx, http.StatusUnauthorized, errors.New("session is blocked"))
 		return
 	}
 
 	if session.Username != refreshPayload.Username {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("incorrect session user")))
+		errorResponse(ctx, http.StatusUnauthorized, errors.New("incorrect session user"))
+		return
+	}
+
+	// the token was already rotated, the session is revoked as it is likely stolen
+	refreshTokenHash := util.HashToken(req.RefreshToken)
+	if session.RefreshTokenHash != refreshTokenHash {
+		s.revokeReusedSession(ctx, session.ID)
 		return
 	}
 
-	if session.RefreshToken != req.RefreshToken {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("incorrect refresh token")))
+	if time.Now().After(session.ExpiredAt) {
+		errorResponse(ctx, http.StatusUnauthorized, errors.New("session has expired"))
 		return
 	}
 
-	token, accessPayload, err := s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.Role, s.config.Token.AccessDuration)
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.Role, refreshPayload.SessionID, token.TokenTypeAccess, s.config.Token.AccessDuration)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	refreshToken, newRefreshPayload, err := s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.Role, refreshPayload.SessionID, token.TokenTypeRefresh, time.Until(session.ExpiredAt))
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rotated, err := s.store.RotateSessionRefreshToken(ctx, db.RotateSessionRefreshTokenParams{
+		NewRefreshTokenHash: util.HashToken(refreshToken),
+		ID:                  session.ID,
+		RefreshTokenHash:    refreshTokenHash,
+	})
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if rotated == 0 {
+		s.revokeReusedSession(ctx, session.ID)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, &renewAccessTokenResponse{
-		AccessToken:          token,
-		AccessTokenExpiresAt: accessPayload.ExpiredAt})
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: newRefreshPayload.ExpiredAt})
+}
+
+func (s *Server) revokeReusedSession(ctx *gin.Context, sessionID uuid.UUID) {
+	if err := s.store.BlockSession(ctx, sessionID); err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	errorResponse(ctx, http.StatusUnauthorized, errors.New("refresh token has already been used, session revoked"))
 }
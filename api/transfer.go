@@ -16,11 +16,11 @@ type transferRequest struct {
 	Currency      string `json:"currency" binding:"required,currency"`
 }
 
-// createTransfer a logged-in user can only send money from theirs account
+// createTransfer a logged-in user can only send money from accounts they own or can sign for
 func (s *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -30,9 +30,7 @@ func (s *Server) createTransfer(ctx *gin.Context) {
 	}
 
 	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != payload.Username {
-		err := errors.New("from account doesn't belong to auth user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if _, ok := s.authorizeAccountMember(ctx, membershipAccountID(fromAccount), payload.Username, accountTransferRoles); !ok {
 		return
 	}
 
@@ -49,7 +47,11 @@ func (s *Server) createTransfer(ctx *gin.Context) {
 
 	rslt, err := s.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			errorResponse(ctx, http.StatusBadRequest, err)
+			return
+		}
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, rslt)
@@ -59,16 +61,16 @@ func (s *Server) validAccount(ctx *gin.Context, accountID int64, currency string
 	account, err := s.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			errorResponse(ctx, http.StatusNotFound, err)
 			return account, false
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return account, false
 	}
 
 	if account.Currency != currency {
 		err := fmt.Errorf("invalid currency, expected: '%s' for account: '%d', provided: '%s'", account.Currency, accountID, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(ctx, http.StatusBadRequest, err)
 		return account, false
 	}
 
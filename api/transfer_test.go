@@ -30,6 +30,8 @@ func TestServer_createTransfer(t *testing.T) {
 	account2.Currency = util.USD
 	account3.Currency = util.EUR
 
+	pocket := randomPocket(account1)
+
 	tests := []struct {
 		name          string
 		body          gin.H
@@ -50,6 +52,10 @@ func TestServer_createTransfer(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, Username: user1.Username})).
+					Times(1).
+					Return(randomAccountMember(account1.ID, user1.Username, util.AccountOwnerRole), nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
 				arg := db.TransferTxParams{
 					FromAccountID: account1.ID,
@@ -62,6 +68,130 @@ func TestServer_createTransfer(t *testing.T) {
 				require.Equal(t, http.StatusOK, resp.Code)
 			},
 		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account1.ID, user1.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name: "FromPocketToMainAccount",
+			body: gin.H{
+				"from_account_id": pocket.ID,
+				"to_account_id":   account1.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, Username: user1.Username})).
+					Times(1).
+					Return(randomAccountMember(account1.ID, user1.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				arg := db.TransferTxParams{
+					FromAccountID: pocket.ID,
+					ToAccountID:   account1.ID,
+					Amount:        amount,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "OKSigner",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, user3.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, Username: user3.Username})).
+					Times(1).
+					Return(randomAccountMember(account1.ID, user3.Username, util.AccountSignerRole), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "ViewerCannotTransfer",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, user3.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account1.ID, user3.Username, util.AccountViewerRole), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
+		{
+			name: "NotAMember",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, user2.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -71,7 +201,7 @@ func TestServer_createTransfer(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tt.buildStubs(store)
 
-			server := NewTestServer(t, store)
+			server := NewTestServer(t, store, nil)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tt.body)
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
 	"net/http"
 	"time"
@@ -28,13 +29,13 @@ type userResponse struct {
 func (s *Server) createUser(ctx *gin.Context) {
 	var req createUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	hp, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -48,10 +49,10 @@ func (s *Server) createUser(ctx *gin.Context) {
 	user, err := s.store.CreateUser(ctx, arg)
 	if err != nil {
 		if db.ErrorCode(err) == db.UniqueViolationCode {
-			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			errorResponse(ctx, http.StatusForbidden, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -79,62 +80,93 @@ type loginUserResponse struct {
 	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
 	RefreshToken          string    `json:"refresh_token"`
 	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	MfaEnrollmentRequired bool      `json:"mfa_enrollment_required"`
 	User                  userResponse
 }
 
 func (s *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	user, err := s.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			errorResponse(ctx, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		errorResponse(ctx, http.StatusUnauthorized, err)
 		return
 	}
-	token, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, s.config.Token.AccessDuration)
+
+	if user.IsLocked {
+		errorResponse(ctx, http.StatusForbidden, errors.New("user is locked"))
+		return
+	}
+
+	// this server has no second factor step, users with TOTP log in through the gRPC API
+	totp, err := s.store.GetTotpSecret(ctx, user.Username)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if err == nil && totp.IsConfirmed {
+		errorResponse(ctx, http.StatusForbidden, errors.New("user has two-factor login enabled"))
+		return
+	}
+
+	policy, err := s.store.GetRolePolicy(ctx, user.Role)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeAccess, s.config.Token.AccessDuration)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, s.config.Token.RefreshDuration)
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeRefresh, s.config.Token.RefreshDuration)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    ctx.Request.UserAgent(),
-		ClientIp:     ctx.ClientIP(),
-		IsBlocked:    false,
-		ExpiredAt:    refreshPayload.ExpiredAt,
+		ID:               sessionID,
+		Username:         user.Username,
+		RefreshTokenHash: util.HashToken(refreshToken),
+		UserAgent:        ctx.Request.UserAgent(),
+		ClientIp:         ctx.ClientIP(),
+		IsBlocked:        false,
+		ExpiredAt:        refreshPayload.ExpiredAt,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, &loginUserResponse{
 		SessionID:             session.ID,
-		AccessToken:           token,
+		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		MfaEnrollmentRequired: policy.MfaRequired,
 		User:                  newUserResponse(user)})
 }
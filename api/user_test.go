@@ -177,7 +177,7 @@ func TestServer_createUser(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tt.buildStubs(store)
 
-			server := NewTestServer(t, store)
+			server := NewTestServer(t, store, nil)
 			recorder := httptest.NewRecorder()
 
 			marshal, err := json.Marshal(tt.body)
@@ -212,6 +212,14 @@ func TestServer_loginUser(t *testing.T) {
 					GetUser(gomock.Any(), user.Username).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					GetTotpSecret(gomock.Any(), user.Username).
+					Times(1).
+					Return(db.TotpSecret{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetRolePolicy(gomock.Any(), user.Role).
+					Times(1).
+					Return(db.RolePolicy{}, db.ErrRecordNotFound)
 				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1)
@@ -220,6 +228,50 @@ func TestServer_loginUser(t *testing.T) {
 				require.Equal(t, http.StatusOK, r.Code)
 			},
 		},
+		{
+			name: "Locked",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				locked := user
+				locked.IsLocked = true
+				store.EXPECT().
+					GetUser(gomock.Any(), user.Username).
+					Times(1).
+					Return(locked, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponses: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name: "TotpEnabled",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetTotpSecret(gomock.Any(), user.Username).
+					Times(1).
+					Return(db.TotpSecret{Username: user.Username, IsConfirmed: true}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponses: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: gin.H{
@@ -292,7 +344,7 @@ func TestServer_loginUser(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tt.buildStubs(store)
 
-			server := NewTestServer(t, store)
+			server := NewTestServer(t, store, nil)
 			recorder := httptest.NewRecorder()
 
 			marshal, err := json.Marshal(tt.body)
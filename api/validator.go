@@ -12,3 +12,11 @@ var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
 
 	return false
 }
+
+var validAccountRole validator.Func = func(fl validator.FieldLevel) bool {
+	if role, ok := fl.Field().Interface().(string); ok {
+		return util.IsAccountRoleValid(role)
+	}
+
+	return false
+}
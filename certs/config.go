@@ -0,0 +1,55 @@
+package certs
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseMinVersion maps the configured minimum version to its crypto/tls value, TLS 1.2 by default
+func ParseMinVersion(version string) (uint16, error) {
+	minVersion, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version: %s", version)
+	}
+	return minVersion, nil
+}
+
+// NewServerConfig serves the certificates of the reloader. With client CAs it verifies client certificates,
+// which are required only when requireClientCert is set so user apps can still connect without one
+func NewServerConfig(reloader *Reloader, minVersion string, requireClientCert bool) (*tls.Config, error) {
+	version, err := ParseMinVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:     version,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	// the client CAs are looked up per handshake so a reloaded CA file applies to new connections
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		clientCAs := reloader.ClientCAs()
+		if clientCAs == nil {
+			return nil, nil
+		}
+
+		perConn := config.Clone()
+		perConn.GetConfigForClient = nil
+		perConn.ClientCAs = clientCAs
+		perConn.ClientAuth = tls.VerifyClientCertIfGiven
+		if requireClientCert {
+			perConn.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		return perConn, nil
+	}
+
+	return config, nil
+}
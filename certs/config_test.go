@@ -0,0 +1,126 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseMinVersion(t *testing.T) {
+	version, err := ParseMinVersion("")
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), version)
+
+	version, err = ParseMinVersion("1.3")
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = ParseMinVersion("1.0")
+	require.Error(t, err)
+}
+
+// handshake connects a client to a server over loopback,
+// it returns the client certificate subject the server verified
+func handshake(t *testing.T, serverConfig *tls.Config, clientCert *tls.Certificate, rootCA testCert) (string, error) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	type result struct {
+		subject string
+		err     error
+	}
+	serverResult := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverResult <- result{err: err}
+			return
+		}
+		defer conn.Close()
+
+		tlsConn := conn.(*tls.Conn)
+		if err := tlsConn.Handshake(); err != nil {
+			serverResult <- result{err: err}
+			return
+		}
+		_, _ = tlsConn.Write([]byte{0})
+
+		subject := ""
+		if chains := tlsConn.ConnectionState().VerifiedChains; len(chains) > 0 {
+			subject = chains[0][0].Subject.CommonName
+		}
+		serverResult <- result{subject: subject}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCA.cert)
+	clientConfig := &tls.Config{ServerName: "localhost", RootCAs: roots}
+	if clientCert != nil {
+		// sent even when the server doesn't list its issuer among the acceptable CAs
+		clientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return clientCert, nil
+		}
+	}
+
+	client, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err == nil {
+		// TLS 1.3 reports a rejected client certificate only on the first read
+		_, err = client.Read(make([]byte, 1))
+		client.Close()
+	}
+
+	res := <-serverResult
+	if res.err != nil {
+		return "", res.err
+	}
+	return res.subject, err
+}
+
+func TestNewServerConfigMutualTLS(t *testing.T) {
+	ca := newTestCert(t, "ca", true, nil)
+	otherCA := newTestCert(t, "other-ca", true, nil)
+	server := newTestCert(t, "localhost", false, &ca)
+	service := newTestCert(t, "payments", false, &ca)
+	stranger := newTestCert(t, "stranger", false, &otherCA)
+
+	files := writeTestFiles(t, t.TempDir(), server, &ca)
+	reloader, err := NewReloader(files.certFile, files.keyFile, files.clientCAFile)
+	require.NoError(t, err)
+
+	config, err := NewServerConfig(reloader, "1.3", false)
+	require.NoError(t, err)
+
+	subject, err := handshake(t, config, service.tlsCertificate(t), ca)
+	require.NoError(t, err)
+	require.Equal(t, "payments", subject)
+
+	subject, err = handshake(t, config, nil, ca)
+	require.NoError(t, err)
+	require.Empty(t, subject)
+
+	_, err = handshake(t, config, stranger.tlsCertificate(t), ca)
+	require.Error(t, err)
+
+	required, err := NewServerConfig(reloader, "1.3", true)
+	require.NoError(t, err)
+	_, err = handshake(t, required, nil, ca)
+	require.Error(t, err)
+}
+
+func TestNewServerConfigWithoutClientCA(t *testing.T) {
+	ca := newTestCert(t, "ca", true, nil)
+	service := newTestCert(t, "payments", false, &ca)
+	files := writeTestFiles(t, t.TempDir(), newTestCert(t, "localhost", false, &ca), nil)
+	reloader, err := NewReloader(files.certFile, files.keyFile, "")
+	require.NoError(t, err)
+
+	config, err := NewServerConfig(reloader, "", false)
+	require.NoError(t, err)
+
+	// the client certificate isn't asked for without client CAs
+	subject, err := handshake(t, config, service.tlsCertificate(t), ca)
+	require.NoError(t, err)
+	require.Empty(t, subject)
+}
@@ -0,0 +1,103 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate in memory, signed by parent or self-signed when parent is nil
+func newTestCert(t *testing.T, commonName string, isCA bool, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Simple Bank"}},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c testCert) tlsCertificate(t *testing.T) *tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return &cert
+}
+
+type testFiles struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+}
+
+// writeTestFiles writes the server certificate and the client CA, the modification time is moved
+// forward on every write so a rewrite within the file system time resolution is still a change
+func writeTestFiles(t *testing.T, dir string, server testCert, clientCA *testCert) testFiles {
+	files := testFiles{certFile: filepath.Join(dir, "server.crt"), keyFile: filepath.Join(dir, "server.key")}
+	writeFile(t, files.certFile, server.certPEM)
+	writeFile(t, files.keyFile, server.keyPEM)
+	if clientCA != nil {
+		files.clientCAFile = filepath.Join(dir, "client_ca.crt")
+		writeFile(t, files.clientCAFile, clientCA.certPEM)
+	}
+	return files
+}
+
+func writeFile(t *testing.T, file string, data []byte) {
+	modTime := time.Now()
+	if info, err := os.Stat(file); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	}
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+	require.NoError(t, os.Chtimes(file, modTime, modTime))
+}
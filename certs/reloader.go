@@ -0,0 +1,132 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves the certificate and client CAs last read from their files,
+// and reads them again when the files change so certificates can be rotated without a restart
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewReloader reads the files once, the client CA file is optional
+func NewReloader(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files, on error the previous certificates stay in use
+func (r *Reloader) Reload() error {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("cannot load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("cannot read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in client CA file %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *Reloader) fileModTimes() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, file := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot stat %s: %w", file, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) changed() bool {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		// a file being replaced may briefly be missing, the next poll picks it up
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run polls the files for changes until the context is done
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				log.Error().Err(err).Msg("cannot reload certificates, keeping the previous ones")
+				continue
+			}
+			log.Info().Str("cert_file", r.certFile).Msg("reloaded certificates")
+		}
+	}
+}
+
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clientCAs
+}
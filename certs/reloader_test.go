@@ -0,0 +1,60 @@
+package certs
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func currentCommonName(t *testing.T, reloader *Reloader) string {
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.NotNil(t, cert.Leaf)
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	ca := newTestCert(t, "ca", true, nil)
+	dir := t.TempDir()
+	files := writeTestFiles(t, dir, newTestCert(t, "server-1", false, &ca), &ca)
+
+	reloader, err := NewReloader(files.certFile, files.keyFile, files.clientCAFile)
+	require.NoError(t, err)
+	require.Equal(t, "server-1", currentCommonName(t, reloader))
+	require.NotNil(t, reloader.ClientCAs())
+	require.False(t, reloader.changed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Run(ctx, 10*time.Millisecond)
+
+	writeTestFiles(t, dir, newTestCert(t, "server-2", false, &ca), &ca)
+	require.Eventually(t, func() bool {
+		return currentCommonName(t, reloader) == "server-2"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReloaderKeepsPreviousOnError(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestFiles(t, dir, newTestCert(t, "server-1", false, nil), nil)
+
+	reloader, err := NewReloader(files.certFile, files.keyFile, "")
+	require.NoError(t, err)
+	require.Nil(t, reloader.ClientCAs())
+
+	writeFile(t, files.certFile, []byte("not a certificate"))
+	require.True(t, reloader.changed())
+	require.Error(t, reloader.Reload())
+	require.Equal(t, "server-1", currentCommonName(t, reloader))
+}
+
+func TestNewReloaderMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestFiles(t, dir, newTestCert(t, "server", false, nil), nil)
+	require.NoError(t, os.Remove(files.keyFile))
+
+	_, err := NewReloader(files.certFile, files.keyFile, "")
+	require.Error(t, err)
+}
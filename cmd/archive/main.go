@@ -0,0 +1,107 @@
+// Command archive exports the monthly partitions of entries and transfers that are older than the
+// configured retention to gzip compressed CSV files, then detaches and drops them.
+//
+// Run it from the repository root so app.yml is found: go run ./cmd/archive
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+func main() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal().Msgf("Error loading config: %s", err)
+	}
+
+	if config.Env == "dev" {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := pgxpool.New(ctx, config.DB.Source)
+	if err != nil {
+		log.Fatal().Msgf("cannot connect to db: %s", err)
+	}
+	defer pool.Close()
+
+	if err = os.MkdirAll(config.Partition.ArchiveDir, 0o750); err != nil {
+		log.Fatal().Msgf("cannot create archive dir: %s", err)
+	}
+
+	store := db.NewStore(pool)
+	now := time.Now().UTC()
+	before := time.Date(now.Year(), now.Month()-time.Month(config.Partition.RetentionMonths), 1, 0, 0, 0, 0, time.UTC)
+
+	for _, parent := range db.PartitionedTables {
+		partitions, err := store.ListExpiredPartitions(ctx, db.ListExpiredPartitionsParams{Parent: parent, Before: before})
+		if err != nil {
+			log.Fatal().Msgf("cannot list %s partitions: %s", parent, err)
+		}
+
+		for _, partition := range partitions {
+			path := filepath.Join(config.Partition.ArchiveDir, partition+".csv.gz")
+			if err = archivePartition(ctx, store, parent, partition, path); err != nil {
+				log.Fatal().Msgf("cannot archive partition %s: %s", partition, err)
+			}
+			log.Info().Str("partition", partition).Str("file", path).Msg("partition archived")
+		}
+	}
+}
+
+// archivePartition exports the partition to path and drops it only once the file is safely on disk;
+// the file is removed when the export fails so a rerun never sees a partial export, and kept once it
+// succeeded even if the drop fails, the partition is then exported again by the next run
+func archivePartition(ctx context.Context, store db.Store, parent string, partition string, path string) error {
+	if err := exportPartition(ctx, store, partition, path); err != nil {
+		return err
+	}
+
+	if err := store.DropPartition(ctx, parent, partition); err != nil {
+		return fmt.Errorf("partition exported to %s but not dropped: %w", path, err)
+	}
+
+	return nil
+}
+
+func exportPartition(ctx context.Context, store db.Store, partition string, path string) (err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(path)
+		}
+	}()
+
+	zw := gzip.NewWriter(file)
+	zw.Name = partition + ".csv"
+
+	if err = store.ExportPartition(ctx, partition, zw); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return fmt.Errorf("cannot compress archive: %w", err)
+	}
+	if err = file.Sync(); err != nil {
+		return fmt.Errorf("cannot sync archive: %w", err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,21 @@
+CREATE TABLE "account_members"
+(
+    "account_id" bigint      NOT NULL,
+    "username"   varchar     NOT NULL,
+    "role"       varchar     NOT NULL,
+    "invited_by" varchar     NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("account_id", "username")
+);
+
+CREATE INDEX ON "account_members" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
+INSERT INTO "account_members" ("account_id", "username", "role", "invited_by")
+SELECT "id", "owner", 'owner', "owner"
+FROM "accounts";
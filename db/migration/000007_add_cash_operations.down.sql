@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "cash_operations";
+
+DELETE FROM "account_members" WHERE "username" = 'bank_cash';
+
+DELETE FROM "accounts" WHERE "owner" = 'bank_cash';
+
+DELETE FROM "users" WHERE "username" = 'bank_cash';
@@ -0,0 +1,42 @@
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bank_cash', '', 'Simple Bank Cash', 'cash@simplebank.local');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_cash', 0, 'USD'),
+       ('bank_cash', 0, 'EUR'),
+       ('bank_cash', 0, 'CAD');
+
+INSERT INTO "account_members" ("account_id", "username", "role", "invited_by")
+SELECT "id", "owner", 'owner', "owner"
+FROM "accounts"
+WHERE "owner" = 'bank_cash';
+
+CREATE TABLE "cash_operations"
+(
+    "id"              bigserial PRIMARY KEY,
+    "type"            varchar        NOT NULL,
+    "account_id"      bigint         NOT NULL,
+    "cash_account_id" bigint         NOT NULL,
+    "transfer_id"     bigint         NOT NULL,
+    "amount"          bigint         NOT NULL,
+    "reference"       varchar UNIQUE NOT NULL,
+    "memo"            varchar        NOT NULL,
+    "banker"          varchar        NOT NULL,
+    "created_at"      timestamptz    NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "cash_operations" ("account_id");
+
+CREATE INDEX ON "cash_operations" ("banker");
+
+COMMENT ON COLUMN "cash_operations"."type" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "cash_operations"."amount" IS 'must be positive';
+
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("cash_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("banker") REFERENCES "users" ("username");
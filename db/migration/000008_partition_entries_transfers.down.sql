@@ -0,0 +1,58 @@
+ALTER TABLE "entries" RENAME TO "entries_partitioned";
+
+ALTER TABLE "transfers" RENAME TO "transfers_partitioned";
+
+ALTER TABLE "entries_partitioned" RENAME CONSTRAINT "entries_pkey" TO "entries_partitioned_pkey";
+
+ALTER TABLE "transfers_partitioned" RENAME CONSTRAINT "transfers_pkey" TO "transfers_partitioned_pkey";
+
+CREATE TABLE "entries"
+(
+    "id"         bigint PRIMARY KEY DEFAULT nextval('entries_id_seq'),
+    "account_id" bigint      NOT NULL,
+    "amount"     bigint      NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfers"
+(
+    "id"              bigint PRIMARY KEY DEFAULT nextval('transfers_id_seq'),
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER SEQUENCE "entries_id_seq" OWNED BY "entries"."id";
+
+ALTER SEQUENCE "transfers_id_seq" OWNED BY "transfers"."id";
+
+INSERT INTO "entries" SELECT * FROM "entries_partitioned";
+
+INSERT INTO "transfers" SELECT * FROM "transfers_partitioned";
+
+DROP TABLE "entries_partitioned";
+
+DROP TABLE "transfers_partitioned";
+
+DROP FUNCTION IF EXISTS create_monthly_partition(text, timestamptz);
+
+CREATE INDEX ON "entries" ("account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id");
+
+CREATE INDEX ON "transfers" ("to_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
+
+COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,99 @@
+-- transfers can no longer be referenced by id alone once the primary key includes the partition key.
+-- Nothing replaces the foreign key: once a transfers partition is archived, the transfer_id of the cash
+-- operations of that month points at a transfer that only exists in the archive
+ALTER TABLE "cash_operations" DROP CONSTRAINT IF EXISTS "cash_operations_transfer_id_fkey";
+
+CREATE OR REPLACE FUNCTION create_monthly_partition(parent text, month timestamptz) RETURNS text AS
+$$
+DECLARE
+    start_at       timestamptz := date_trunc('month', month AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+    end_at         timestamptz := (date_trunc('month', month AT TIME ZONE 'UTC') + interval '1 month') AT TIME ZONE 'UTC';
+    partition_name text        := format('%s_%s', parent, to_char(start_at AT TIME ZONE 'UTC', 'YYYY_MM'));
+BEGIN
+    EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                   partition_name, parent, start_at, end_at);
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE "entries" RENAME TO "entries_legacy";
+
+ALTER TABLE "transfers" RENAME TO "transfers_legacy";
+
+ALTER TABLE "entries_legacy" RENAME CONSTRAINT "entries_pkey" TO "entries_legacy_pkey";
+
+ALTER TABLE "transfers_legacy" RENAME CONSTRAINT "transfers_pkey" TO "transfers_legacy_pkey";
+
+CREATE TABLE "entries"
+(
+    "id"         bigint      NOT NULL DEFAULT nextval('entries_id_seq'),
+    "account_id" bigint      NOT NULL,
+    "amount"     bigint      NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("id", "created_at")
+) PARTITION BY RANGE ("created_at");
+
+CREATE TABLE "transfers"
+(
+    "id"              bigint      NOT NULL DEFAULT nextval('transfers_id_seq'),
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("id", "created_at")
+) PARTITION BY RANGE ("created_at");
+
+ALTER SEQUENCE "entries_id_seq" OWNED BY "entries"."id";
+
+ALTER SEQUENCE "transfers_id_seq" OWNED BY "transfers"."id";
+
+-- catches rows outside the pre-created months so writes never fail
+CREATE TABLE "entries_default" PARTITION OF "entries" DEFAULT;
+
+CREATE TABLE "transfers_default" PARTITION OF "transfers" DEFAULT;
+
+DO
+$$
+    DECLARE
+        month timestamptz;
+    BEGIN
+        FOR month IN SELECT generate_series(
+                                    date_trunc('month', COALESCE(LEAST((SELECT min("created_at") FROM "entries_legacy"),
+                                                                       (SELECT min("created_at") FROM "transfers_legacy")),
+                                                                 now())),
+                                    now() + interval '3 months',
+                                    interval '1 month')
+            LOOP
+                PERFORM create_monthly_partition('entries', month);
+                PERFORM create_monthly_partition('transfers', month);
+            END LOOP;
+    END
+$$;
+
+INSERT INTO "entries" SELECT * FROM "entries_legacy";
+
+INSERT INTO "transfers" SELECT * FROM "transfers_legacy";
+
+DROP TABLE "entries_legacy";
+
+DROP TABLE "transfers_legacy";
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("from_account_id");
+
+CREATE INDEX ON "transfers" ("to_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
+
+COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "cash_operations"."transfer_id" IS 'no foreign key, the transfer may be archived';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
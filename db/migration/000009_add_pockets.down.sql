@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "owner_currency_key";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "goal_amount";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "name";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "parent_id";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
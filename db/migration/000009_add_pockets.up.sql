@@ -0,0 +1,18 @@
+ALTER TABLE "accounts" ADD COLUMN "parent_id" bigint;
+
+ALTER TABLE "accounts" ADD COLUMN "name" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "accounts" ADD COLUMN "goal_amount" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "accounts"."parent_id" IS 'set for pockets, references the main account';
+
+COMMENT ON COLUMN "accounts"."goal_amount" IS '0 means no goal';
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("parent_id") REFERENCES "accounts" ("id");
+
+-- only main accounts are limited to one per owner and currency, pockets are unique by name
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner", "currency") WHERE "parent_id" IS NULL;
+
+CREATE UNIQUE INDEX ON "accounts" ("parent_id", "name");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "sessions_username_idx";
+
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "last_used_at";
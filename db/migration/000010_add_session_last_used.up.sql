@@ -0,0 +1,3 @@
+ALTER TABLE "sessions" ADD COLUMN "last_used_at" timestamptz NOT NULL DEFAULT now();
+
+CREATE INDEX ON "sessions" ("username");
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "audit_logs";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "must_reset_password";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "is_locked";
@@ -0,0 +1,21 @@
+ALTER TABLE "users" ADD COLUMN "is_locked" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "users" ADD COLUMN "must_reset_password" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "audit_logs"
+(
+    "id"         bigserial PRIMARY KEY,
+    "banker"     varchar     NOT NULL,
+    "action"     varchar     NOT NULL,
+    "target"     varchar     NOT NULL,
+    "details"    varchar     NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_logs" ("banker");
+
+CREATE INDEX ON "audit_logs" ("target");
+
+COMMENT ON COLUMN "audit_logs"."target" IS 'username the action was applied to, empty for searches';
+
+ALTER TABLE "audit_logs" ADD FOREIGN KEY ("banker") REFERENCES "users" ("username");
@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "version";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "version";
@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "accounts" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "users"."version" IS 'incremented by every update, guards concurrent updates';
+
+COMMENT ON COLUMN "accounts"."version" IS 'incremented by every update, guards concurrent updates';
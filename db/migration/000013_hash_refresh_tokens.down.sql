@@ -0,0 +1,6 @@
+-- the raw tokens can't be restored from their hashes, so the sessions are logged out
+UPDATE "sessions" SET "is_blocked" = true;
+
+COMMENT ON COLUMN "sessions"."refresh_token_hash" IS NULL;
+
+ALTER TABLE "sessions" RENAME COLUMN "refresh_token_hash" TO "refresh_token";
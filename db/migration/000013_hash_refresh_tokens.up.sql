@@ -0,0 +1,6 @@
+ALTER TABLE "sessions" RENAME COLUMN "refresh_token" TO "refresh_token_hash";
+
+UPDATE "sessions"
+SET "refresh_token_hash" = encode(sha256(convert_to("refresh_token_hash", 'UTF8')), 'hex');
+
+COMMENT ON COLUMN "sessions"."refresh_token_hash" IS 'sha256 of the current refresh token, replaced on every renewal';
@@ -0,0 +1,9 @@
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "is_mfa_verified";
+
+DROP TABLE IF EXISTS "role_policies";
+
+DROP TABLE IF EXISTS "mfa_challenges";
+
+DROP TABLE IF EXISTS "recovery_codes";
+
+DROP TABLE IF EXISTS "totp_secrets";
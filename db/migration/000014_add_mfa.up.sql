@@ -0,0 +1,56 @@
+CREATE TABLE "totp_secrets"
+(
+    "username"       varchar PRIMARY KEY,
+    "secret"         varchar     NOT NULL,
+    "is_confirmed"   boolean     NOT NULL DEFAULT false,
+    "last_used_step" bigint      NOT NULL DEFAULT 0,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes"
+(
+    "id"         bigserial PRIMARY KEY,
+    "username"   varchar     NOT NULL,
+    "code_hash"  varchar     NOT NULL,
+    "is_used"    boolean     NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_challenges"
+(
+    "id"         bigserial PRIMARY KEY,
+    "username"   varchar     NOT NULL,
+    "token_hash" varchar     NOT NULL,
+    "attempts"   integer     NOT NULL DEFAULT 0,
+    "is_used"    boolean     NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "expired_at" timestamptz NOT NULL
+);
+
+CREATE TABLE "role_policies"
+(
+    "role"         varchar PRIMARY KEY,
+    "mfa_required" boolean     NOT NULL DEFAULT false,
+    "updated_by"   varchar     NOT NULL,
+    "updated_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "sessions" ADD COLUMN "is_mfa_verified" boolean NOT NULL DEFAULT false;
+
+CREATE INDEX ON "recovery_codes" ("username");
+
+CREATE UNIQUE INDEX ON "mfa_challenges" ("token_hash");
+
+COMMENT ON COLUMN "totp_secrets"."last_used_step" IS 'time step of the last accepted code, a code can''t be replayed';
+
+COMMENT ON COLUMN "recovery_codes"."code_hash" IS 'sha256 of the recovery code';
+
+COMMENT ON COLUMN "mfa_challenges"."token_hash" IS 'sha256 of the challenge token returned by the login';
+
+ALTER TABLE "totp_secrets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "role_policies" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("username");
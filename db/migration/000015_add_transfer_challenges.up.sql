@@ -0,0 +1,23 @@
+CREATE TABLE "transfer_challenges"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "email"           varchar     NOT NULL,
+    "secret_code"     varchar     NOT NULL,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "attempts"        integer     NOT NULL DEFAULT 0,
+    "is_used"         bool        NOT NULL DEFAULT false,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "expired_at"      timestamptz NOT NULL
+);
+
+COMMENT ON COLUMN "transfer_challenges"."secret_code" IS 'emailed 6-digit code, it only confirms the transfer it was issued for';
+
+ALTER TABLE "transfer_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_challenges" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_challenges" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1 @@
+ALTER TABLE "totp_secrets" DROP COLUMN IF EXISTS "confirm_attempts";
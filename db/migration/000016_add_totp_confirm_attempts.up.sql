@@ -0,0 +1,3 @@
+ALTER TABLE "totp_secrets" ADD COLUMN "confirm_attempts" integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "totp_secrets"."confirm_attempts" IS 'codes tried to confirm the pending secret, enrolling again resets them';
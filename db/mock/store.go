@@ -6,6 +6,7 @@ package mockdb
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -51,6 +52,140 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
 }
 
+// AddAccountMemberTx mocks base method.
+func (m *MockStore) AddAccountMemberTx(arg0 context.Context, arg1 db.AddAccountMemberTxParams) (db.AddAccountMemberTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountMemberTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AddAccountMemberTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountMemberTx indicates an expected call of AddAccountMemberTx.
+func (mr *MockStoreMockRecorder) AddAccountMemberTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountMemberTx", reflect.TypeOf((*MockStore)(nil).AddAccountMemberTx), arg0, arg1)
+}
+
+// AdminUpdateUserTx mocks base method.
+func (m *MockStore) AdminUpdateUserTx(arg0 context.Context, arg1 db.AdminUpdateUserTxParams) (db.AdminUpdateUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminUpdateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AdminUpdateUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminUpdateUserTx indicates an expected call of AdminUpdateUserTx.
+func (mr *MockStoreMockRecorder) AdminUpdateUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminUpdateUserTx", reflect.TypeOf((*MockStore)(nil).AdminUpdateUserTx), arg0, arg1)
+}
+
+// AttemptMfaChallenge mocks base method.
+func (m *MockStore) AttemptMfaChallenge(arg0 context.Context, arg1 db.AttemptMfaChallengeParams) (db.MfaChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptMfaChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.MfaChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptMfaChallenge indicates an expected call of AttemptMfaChallenge.
+func (mr *MockStoreMockRecorder) AttemptMfaChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptMfaChallenge", reflect.TypeOf((*MockStore)(nil).AttemptMfaChallenge), arg0, arg1)
+}
+
+// AttemptTotpConfirmation mocks base method.
+func (m *MockStore) AttemptTotpConfirmation(arg0 context.Context, arg1 db.AttemptTotpConfirmationParams) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptTotpConfirmation", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptTotpConfirmation indicates an expected call of AttemptTotpConfirmation.
+func (mr *MockStoreMockRecorder) AttemptTotpConfirmation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptTotpConfirmation", reflect.TypeOf((*MockStore)(nil).AttemptTotpConfirmation), arg0, arg1)
+}
+
+// AttemptTransferChallenge mocks base method.
+func (m *MockStore) AttemptTransferChallenge(arg0 context.Context, arg1 db.AttemptTransferChallengeParams) (db.TransferChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptTransferChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptTransferChallenge indicates an expected call of AttemptTransferChallenge.
+func (mr *MockStoreMockRecorder) AttemptTransferChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptTransferChallenge", reflect.TypeOf((*MockStore)(nil).AttemptTransferChallenge), arg0, arg1)
+}
+
+// BlockSession mocks base method.
+func (m *MockStore) BlockSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSession indicates an expected call of BlockSession.
+func (mr *MockStoreMockRecorder) BlockSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockStore)(nil).BlockSession), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// CashOperationTx mocks base method.
+func (m *MockStore) CashOperationTx(arg0 context.Context, arg1 db.CashOperationTxParams) (db.CashOperationTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CashOperationTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CashOperationTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CashOperationTx indicates an expected call of CashOperationTx.
+func (mr *MockStoreMockRecorder) CashOperationTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CashOperationTx", reflect.TypeOf((*MockStore)(nil).CashOperationTx), arg0, arg1)
+}
+
+// ConfirmTotpTx mocks base method.
+func (m *MockStore) ConfirmTotpTx(arg0 context.Context, arg1 db.ConfirmTotpTxParams) (db.ConfirmTotpTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTotpTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ConfirmTotpTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTotpTx indicates an expected call of ConfirmTotpTx.
+func (mr *MockStoreMockRecorder) ConfirmTotpTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTotpTx", reflect.TypeOf((*MockStore)(nil).ConfirmTotpTx), arg0, arg1)
+}
+
 // CreateAccount mocks base method.
 func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +201,66 @@ func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
 }
 
+// CreateAccountMember mocks base method.
+func (m *MockStore) CreateAccountMember(arg0 context.Context, arg1 db.CreateAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountMember indicates an expected call of CreateAccountMember.
+func (mr *MockStoreMockRecorder) CreateAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountMember", reflect.TypeOf((*MockStore)(nil).CreateAccountMember), arg0, arg1)
+}
+
+// CreateAccountTx mocks base method.
+func (m *MockStore) CreateAccountTx(arg0 context.Context, arg1 db.CreateAccountParams) (db.CreateAccountTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateAccountTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTx indicates an expected call of CreateAccountTx.
+func (mr *MockStoreMockRecorder) CreateAccountTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTx", reflect.TypeOf((*MockStore)(nil).CreateAccountTx), arg0, arg1)
+}
+
+// CreateAuditLog mocks base method.
+func (m *MockStore) CreateAuditLog(arg0 context.Context, arg1 db.CreateAuditLogParams) (db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockStoreMockRecorder) CreateAuditLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockStore)(nil).CreateAuditLog), arg0, arg1)
+}
+
+// CreateCashOperation mocks base method.
+func (m *MockStore) CreateCashOperation(arg0 context.Context, arg1 db.CreateCashOperationParams) (db.CashOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCashOperation", arg0, arg1)
+	ret0, _ := ret[0].(db.CashOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCashOperation indicates an expected call of CreateCashOperation.
+func (mr *MockStoreMockRecorder) CreateCashOperation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCashOperation", reflect.TypeOf((*MockStore)(nil).CreateCashOperation), arg0, arg1)
+}
+
 // CreateEntry mocks base method.
 func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +276,66 @@ func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
 }
 
+// CreateMfaChallenge mocks base method.
+func (m *MockStore) CreateMfaChallenge(arg0 context.Context, arg1 db.CreateMfaChallengeParams) (db.MfaChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMfaChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.MfaChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMfaChallenge indicates an expected call of CreateMfaChallenge.
+func (mr *MockStoreMockRecorder) CreateMfaChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMfaChallenge", reflect.TypeOf((*MockStore)(nil).CreateMfaChallenge), arg0, arg1)
+}
+
+// CreateMonthlyPartition mocks base method.
+func (m *MockStore) CreateMonthlyPartition(arg0 context.Context, arg1 db.CreateMonthlyPartitionParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMonthlyPartition", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMonthlyPartition indicates an expected call of CreateMonthlyPartition.
+func (mr *MockStoreMockRecorder) CreateMonthlyPartition(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMonthlyPartition", reflect.TypeOf((*MockStore)(nil).CreateMonthlyPartition), arg0, arg1)
+}
+
+// CreatePocket mocks base method.
+func (m *MockStore) CreatePocket(arg0 context.Context, arg1 db.CreatePocketParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePocket", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePocket indicates an expected call of CreatePocket.
+func (mr *MockStoreMockRecorder) CreatePocket(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePocket", reflect.TypeOf((*MockStore)(nil).CreatePocket), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +351,21 @@ func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
 }
 
+// CreateTotpSecret mocks base method.
+func (m *MockStore) CreateTotpSecret(arg0 context.Context, arg1 db.CreateTotpSecretParams) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTotpSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTotpSecret indicates an expected call of CreateTotpSecret.
+func (mr *MockStoreMockRecorder) CreateTotpSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTotpSecret", reflect.TypeOf((*MockStore)(nil).CreateTotpSecret), arg0, arg1)
+}
+
 // CreateTransfer mocks base method.
 func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
 	m.ctrl.T.Helper()
@@ -111,6 +381,21 @@ func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
 }
 
+// CreateTransferChallenge mocks base method.
+func (m *MockStore) CreateTransferChallenge(arg0 context.Context, arg1 db.CreateTransferChallengeParams) (db.TransferChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferChallenge indicates an expected call of CreateTransferChallenge.
+func (mr *MockStoreMockRecorder) CreateTransferChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferChallenge", reflect.TypeOf((*MockStore)(nil).CreateTransferChallenge), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -170,6 +455,63 @@ func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
 }
 
+// DeleteAccountMember mocks base method.
+func (m *MockStore) DeleteAccountMember(arg0 context.Context, arg1 db.DeleteAccountMemberParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccountMember indicates an expected call of DeleteAccountMember.
+func (mr *MockStoreMockRecorder) DeleteAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountMember", reflect.TypeOf((*MockStore)(nil).DeleteAccountMember), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
+// DropPartition mocks base method.
+func (m *MockStore) DropPartition(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropPartition", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropPartition indicates an expected call of DropPartition.
+func (mr *MockStoreMockRecorder) DropPartition(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropPartition", reflect.TypeOf((*MockStore)(nil).DropPartition), arg0, arg1, arg2)
+}
+
+// ExportPartition mocks base method.
+func (m *MockStore) ExportPartition(arg0 context.Context, arg1 string, arg2 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPartition", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportPartition indicates an expected call of ExportPartition.
+func (mr *MockStoreMockRecorder) ExportPartition(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPartition", reflect.TypeOf((*MockStore)(nil).ExportPartition), arg0, arg1, arg2)
+}
+
 // GetAccount mocks base method.
 func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -185,6 +527,21 @@ func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
 }
 
+// GetAccountBalanceAt mocks base method.
+func (m *MockStore) GetAccountBalanceAt(arg0 context.Context, arg1 db.GetAccountBalanceAtParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalanceAt", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalanceAt indicates an expected call of GetAccountBalanceAt.
+func (mr *MockStoreMockRecorder) GetAccountBalanceAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalanceAt", reflect.TypeOf((*MockStore)(nil).GetAccountBalanceAt), arg0, arg1)
+}
+
 // GetAccountForUpdate mocks base method.
 func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +557,36 @@ func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
 }
 
+// GetAccountMember mocks base method.
+func (m *MockStore) GetAccountMember(arg0 context.Context, arg1 db.GetAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountMember indicates an expected call of GetAccountMember.
+func (mr *MockStoreMockRecorder) GetAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountMember", reflect.TypeOf((*MockStore)(nil).GetAccountMember), arg0, arg1)
+}
+
+// GetAuthSession mocks base method.
+func (m *MockStore) GetAuthSession(arg0 context.Context, arg1 uuid.UUID) (db.GetAuthSessionRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthSession", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAuthSessionRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthSession indicates an expected call of GetAuthSession.
+func (mr *MockStoreMockRecorder) GetAuthSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthSession", reflect.TypeOf((*MockStore)(nil).GetAuthSession), arg0, arg1)
+}
+
 // GetEntry mocks base method.
 func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
 	m.ctrl.T.Helper()
@@ -215,6 +602,81 @@ func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
 }
 
+// GetLoginHistory mocks base method.
+func (m *MockStore) GetLoginHistory(arg0 context.Context, arg1 db.GetLoginHistoryParams) (db.GetLoginHistoryRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginHistory", arg0, arg1)
+	ret0, _ := ret[0].(db.GetLoginHistoryRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginHistory indicates an expected call of GetLoginHistory.
+func (mr *MockStoreMockRecorder) GetLoginHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginHistory", reflect.TypeOf((*MockStore)(nil).GetLoginHistory), arg0, arg1)
+}
+
+// GetOldestPartition mocks base method.
+func (m *MockStore) GetOldestPartition(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOldestPartition", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOldestPartition indicates an expected call of GetOldestPartition.
+func (mr *MockStoreMockRecorder) GetOldestPartition(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOldestPartition", reflect.TypeOf((*MockStore)(nil).GetOldestPartition), arg0, arg1)
+}
+
+// GetOwnerAccount mocks base method.
+func (m *MockStore) GetOwnerAccount(arg0 context.Context, arg1 db.GetOwnerAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwnerAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnerAccount indicates an expected call of GetOwnerAccount.
+func (mr *MockStoreMockRecorder) GetOwnerAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnerAccount", reflect.TypeOf((*MockStore)(nil).GetOwnerAccount), arg0, arg1)
+}
+
+// GetPocketsBalance mocks base method.
+func (m *MockStore) GetPocketsBalance(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPocketsBalance", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPocketsBalance indicates an expected call of GetPocketsBalance.
+func (mr *MockStoreMockRecorder) GetPocketsBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPocketsBalance", reflect.TypeOf((*MockStore)(nil).GetPocketsBalance), arg0, arg1)
+}
+
+// GetRolePolicy mocks base method.
+func (m *MockStore) GetRolePolicy(arg0 context.Context, arg1 string) (db.RolePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRolePolicy", arg0, arg1)
+	ret0, _ := ret[0].(db.RolePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRolePolicy indicates an expected call of GetRolePolicy.
+func (mr *MockStoreMockRecorder) GetRolePolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRolePolicy", reflect.TypeOf((*MockStore)(nil).GetRolePolicy), arg0, arg1)
+}
+
 // GetSession mocks base method.
 func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -230,6 +692,21 @@ func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
 }
 
+// GetTotpSecret mocks base method.
+func (m *MockStore) GetTotpSecret(arg0 context.Context, arg1 string) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotpSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotpSecret indicates an expected call of GetTotpSecret.
+func (mr *MockStoreMockRecorder) GetTotpSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotpSecret", reflect.TypeOf((*MockStore)(nil).GetTotpSecret), arg0, arg1)
+}
+
 // GetTransfer mocks base method.
 func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
 	m.ctrl.T.Helper()
@@ -245,6 +722,21 @@ func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
 }
 
+// GetTransferChallenge mocks base method.
+func (m *MockStore) GetTransferChallenge(arg0 context.Context, arg1 int64) (db.TransferChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferChallenge indicates an expected call of GetTransferChallenge.
+func (mr *MockStoreMockRecorder) GetTransferChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferChallenge", reflect.TypeOf((*MockStore)(nil).GetTransferChallenge), arg0, arg1)
+}
+
 // GetUser mocks base method.
 func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -260,6 +752,21 @@ func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
 }
 
+// ListAccountMembers mocks base method.
+func (m *MockStore) ListAccountMembers(arg0 context.Context, arg1 int64) ([]db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountMembers", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountMembers indicates an expected call of ListAccountMembers.
+func (mr *MockStoreMockRecorder) ListAccountMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountMembers", reflect.TypeOf((*MockStore)(nil).ListAccountMembers), arg0, arg1)
+}
+
 // ListAccounts mocks base method.
 func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
 	m.ctrl.T.Helper()
@@ -275,6 +782,36 @@ func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
 }
 
+// ListAuditLogs mocks base method.
+func (m *MockStore) ListAuditLogs(arg0 context.Context, arg1 db.ListAuditLogsParams) ([]db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogs indicates an expected call of ListAuditLogs.
+func (mr *MockStoreMockRecorder) ListAuditLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogs", reflect.TypeOf((*MockStore)(nil).ListAuditLogs), arg0, arg1)
+}
+
+// ListCashOperations mocks base method.
+func (m *MockStore) ListCashOperations(arg0 context.Context, arg1 db.ListCashOperationsParams) ([]db.CashOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCashOperations", arg0, arg1)
+	ret0, _ := ret[0].([]db.CashOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCashOperations indicates an expected call of ListCashOperations.
+func (mr *MockStoreMockRecorder) ListCashOperations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCashOperations", reflect.TypeOf((*MockStore)(nil).ListCashOperations), arg0, arg1)
+}
+
 // ListEntries mocks base method.
 func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
 	m.ctrl.T.Helper()
@@ -290,6 +827,51 @@ func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
 }
 
+// ListExpiredPartitions mocks base method.
+func (m *MockStore) ListExpiredPartitions(arg0 context.Context, arg1 db.ListExpiredPartitionsParams) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredPartitions", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredPartitions indicates an expected call of ListExpiredPartitions.
+func (mr *MockStoreMockRecorder) ListExpiredPartitions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredPartitions", reflect.TypeOf((*MockStore)(nil).ListExpiredPartitions), arg0, arg1)
+}
+
+// ListMemberAccounts mocks base method.
+func (m *MockStore) ListMemberAccounts(arg0 context.Context, arg1 db.ListMemberAccountsParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMemberAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMemberAccounts indicates an expected call of ListMemberAccounts.
+func (mr *MockStoreMockRecorder) ListMemberAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMemberAccounts", reflect.TypeOf((*MockStore)(nil).ListMemberAccounts), arg0, arg1)
+}
+
+// ListPockets mocks base method.
+func (m *MockStore) ListPockets(arg0 context.Context, arg1 int64) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPockets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPockets indicates an expected call of ListPockets.
+func (mr *MockStoreMockRecorder) ListPockets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPockets", reflect.TypeOf((*MockStore)(nil).ListPockets), arg0, arg1)
+}
+
 // ListTransfers mocks base method.
 func (m *MockStore) ListTransfers(arg0 context.Context, arg1 db.ListTransfersParams) ([]db.Transfer, error) {
 	m.ctrl.T.Helper()
@@ -305,6 +887,94 @@ func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
 }
 
+// ListUserSessions mocks base method.
+func (m *MockStore) ListUserSessions(arg0 context.Context, arg1 string) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSessions indicates an expected call of ListUserSessions.
+func (mr *MockStoreMockRecorder) ListUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockStore)(nil).ListUserSessions), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+}
+
+// MarkSessionMfaVerified mocks base method.
+func (m *MockStore) MarkSessionMfaVerified(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSessionMfaVerified", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSessionMfaVerified indicates an expected call of MarkSessionMfaVerified.
+func (mr *MockStoreMockRecorder) MarkSessionMfaVerified(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSessionMfaVerified", reflect.TypeOf((*MockStore)(nil).MarkSessionMfaVerified), arg0, arg1)
+}
+
+// RotateSessionRefreshToken mocks base method.
+func (m *MockStore) RotateSessionRefreshToken(arg0 context.Context, arg1 db.RotateSessionRefreshTokenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSessionRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSessionRefreshToken indicates an expected call of RotateSessionRefreshToken.
+func (mr *MockStoreMockRecorder) RotateSessionRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSessionRefreshToken", reflect.TypeOf((*MockStore)(nil).RotateSessionRefreshToken), arg0, arg1)
+}
+
+// SetRolePolicyTx mocks base method.
+func (m *MockStore) SetRolePolicyTx(arg0 context.Context, arg1 db.SetRolePolicyTxParams) (db.SetRolePolicyTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRolePolicyTx", arg0, arg1)
+	ret0, _ := ret[0].(db.SetRolePolicyTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRolePolicyTx indicates an expected call of SetRolePolicyTx.
+func (mr *MockStoreMockRecorder) SetRolePolicyTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRolePolicyTx", reflect.TypeOf((*MockStore)(nil).SetRolePolicyTx), arg0, arg1)
+}
+
+// TouchSession mocks base method.
+func (m *MockStore) TouchSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockStoreMockRecorder) TouchSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockStore)(nil).TouchSession), arg0, arg1)
+}
+
 // TransferTx mocks base method.
 func (m *MockStore) TransferTx(arg0 context.Context, arg1 db.TransferTxParams) (db.TransferTxResult, error) {
 	m.ctrl.T.Helper()
@@ -365,6 +1035,81 @@ func (mr *MockStoreMockRecorder) UpdateVerifyEmail(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVerifyEmail", reflect.TypeOf((*MockStore)(nil).UpdateVerifyEmail), arg0, arg1)
 }
 
+// UpsertRolePolicy mocks base method.
+func (m *MockStore) UpsertRolePolicy(arg0 context.Context, arg1 db.UpsertRolePolicyParams) (db.RolePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertRolePolicy", arg0, arg1)
+	ret0, _ := ret[0].(db.RolePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertRolePolicy indicates an expected call of UpsertRolePolicy.
+func (mr *MockStoreMockRecorder) UpsertRolePolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertRolePolicy", reflect.TypeOf((*MockStore)(nil).UpsertRolePolicy), arg0, arg1)
+}
+
+// UseMfaChallenge mocks base method.
+func (m *MockStore) UseMfaChallenge(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseMfaChallenge", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseMfaChallenge indicates an expected call of UseMfaChallenge.
+func (mr *MockStoreMockRecorder) UseMfaChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseMfaChallenge", reflect.TypeOf((*MockStore)(nil).UseMfaChallenge), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTotpStep mocks base method.
+func (m *MockStore) UseTotpStep(arg0 context.Context, arg1 db.UseTotpStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTotpStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTotpStep indicates an expected call of UseTotpStep.
+func (mr *MockStoreMockRecorder) UseTotpStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTotpStep", reflect.TypeOf((*MockStore)(nil).UseTotpStep), arg0, arg1)
+}
+
+// UseTransferChallenge mocks base method.
+func (m *MockStore) UseTransferChallenge(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTransferChallenge", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTransferChallenge indicates an expected call of UseTransferChallenge.
+func (mr *MockStoreMockRecorder) UseTransferChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTransferChallenge", reflect.TypeOf((*MockStore)(nil).UseTransferChallenge), arg0, arg1)
+}
+
 // VerifyEmailTx mocks base method.
 func (m *MockStore) VerifyEmailTx(arg0 context.Context, arg1 db.VerifyEmailTxParams) (db.VerifyEmailTxResult, error) {
 	m.ctrl.T.Helper()
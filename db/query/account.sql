@@ -14,7 +14,8 @@ FOR NO KEY UPDATE;
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-set balance = balance + sqlc.arg(amount)
+set balance = balance + sqlc.arg(amount),
+    version = version + 1
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
@@ -27,10 +28,47 @@ OFFSET $3;
 
 -- name: UpdateAccount :one
 UPDATE accounts
-set balance = $2
+set balance = $2,
+    version = version + 1
 WHERE id = $1
 RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListMemberAccounts :many
+SELECT accounts.* FROM accounts
+JOIN account_members ON account_members.account_id = accounts.id
+WHERE account_members.username = $1
+ORDER BY accounts.id
+LIMIT $2
+OFFSET $3;
+
+-- name: GetOwnerAccount :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND parent_id IS NULL LIMIT 1;
+
+-- name: GetAccountBalanceAt :one
+SELECT (accounts.balance - COALESCE((SELECT SUM(entries.amount)
+                                     FROM entries
+                                     WHERE entries.account_id = accounts.id
+                                       AND entries.created_at > sqlc.arg(as_of)), 0))::bigint AS balance
+FROM accounts
+WHERE accounts.id = sqlc.arg(account_id);
+
+-- name: CreatePocket :one
+INSERT INTO accounts (owner, balance, currency, parent_id, name, goal_amount)
+SELECT owner, 0, currency, id, sqlc.arg(name)::varchar, sqlc.arg(goal_amount)::bigint
+FROM accounts
+WHERE id = sqlc.arg(parent_id) AND parent_id IS NULL
+RETURNING *;
+
+-- name: ListPockets :many
+SELECT * FROM accounts
+WHERE parent_id = sqlc.arg(parent_id)::bigint
+ORDER BY id;
+
+-- name: GetPocketsBalance :one
+SELECT COALESCE(SUM(balance), 0)::bigint AS total FROM accounts
+WHERE parent_id = sqlc.arg(parent_id)::bigint;
@@ -0,0 +1,14 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (banker,
+                        action,
+                        target,
+                        details)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_logs
+WHERE target = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
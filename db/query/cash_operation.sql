@@ -0,0 +1,18 @@
+-- name: CreateCashOperation :one
+INSERT INTO cash_operations (type,
+                             account_id,
+                             cash_account_id,
+                             transfer_id,
+                             amount,
+                             reference,
+                             memo,
+                             banker)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListCashOperations :many
+SELECT * FROM cash_operations
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,65 @@
+-- name: CreateTotpSecret :one
+INSERT INTO totp_secrets (username, secret)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+    SET secret         = EXCLUDED.secret,
+        last_used_step   = 0,
+        confirm_attempts = 0,
+        created_at       = now()
+WHERE totp_secrets.is_confirmed = false
+RETURNING *;
+
+-- name: GetTotpSecret :one
+SELECT * FROM totp_secrets
+WHERE username = $1 LIMIT 1;
+
+-- name: AttemptTotpConfirmation :one
+UPDATE totp_secrets
+SET confirm_attempts = confirm_attempts + 1
+WHERE username = sqlc.arg(username)
+  AND is_confirmed = false
+  AND confirm_attempts < sqlc.arg(max_attempts)::integer
+RETURNING *;
+
+-- name: UseTotpStep :execrows
+UPDATE totp_secrets
+SET last_used_step = sqlc.arg(step),
+    is_confirmed   = true
+WHERE username = sqlc.arg(username)
+  AND last_used_step < sqlc.arg(step);
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (username, code_hash)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET is_used = true
+WHERE username = $1
+  AND code_hash = $2
+  AND is_used = false;
+
+-- name: CreateMfaChallenge :one
+INSERT INTO mfa_challenges (username, token_hash, expired_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: AttemptMfaChallenge :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE token_hash = sqlc.arg(token_hash)
+  AND is_used = false
+  AND expired_at > now()
+  AND attempts < sqlc.arg(max_attempts)::integer
+RETURNING *;
+
+-- name: UseMfaChallenge :execrows
+UPDATE mfa_challenges
+SET is_used = true
+WHERE id = $1
+  AND is_used = false;
@@ -0,0 +1,18 @@
+-- name: CreateMonthlyPartition :one
+SELECT create_monthly_partition(sqlc.arg(parent)::text, sqlc.arg(month)::timestamptz)::text AS partition_name;
+
+-- name: ListExpiredPartitions :many
+SELECT relname::text AS partition_name
+FROM pg_class
+WHERE relnamespace = current_schema()::regnamespace
+  AND relkind = 'r'
+  AND relname ~ ('^' || sqlc.arg(parent)::text || '_[0-9]{4}_[0-9]{2}$')
+  AND relname < sqlc.arg(parent)::text || '_' || to_char(sqlc.arg(before)::timestamptz AT TIME ZONE 'UTC', 'YYYY_MM')
+ORDER BY relname;
+
+-- name: GetOldestPartition :one
+SELECT COALESCE(min(c.relname::text), '')::text AS partition_name
+FROM pg_inherits i
+         JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = sqlc.arg(parent)::text::regclass
+  AND c.relname ~ ('^' || sqlc.arg(parent)::text || '_[0-9]{4}_[0-9]{2}$');
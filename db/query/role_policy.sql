@@ -0,0 +1,12 @@
+-- name: GetRolePolicy :one
+SELECT * FROM role_policies
+WHERE role = $1 LIMIT 1;
+
+-- name: UpsertRolePolicy :one
+INSERT INTO role_policies (role, mfa_required, updated_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (role) DO UPDATE
+    SET mfa_required = EXCLUDED.mfa_required,
+        updated_by   = EXCLUDED.updated_by,
+        updated_at   = now()
+RETURNING *;
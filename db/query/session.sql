@@ -1,14 +1,66 @@
 -- name: CreateSession :one
 INSERT INTO sessions (id,
                       username,
-                      refresh_token,
+                      refresh_token_hash,
                       user_agent,
                       client_ip,
                       is_blocked,
-                      expired_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+                      expired_at,
+                      is_mfa_verified)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
+
+-- name: GetAuthSession :one
+SELECT sqlc.embed(sessions),
+       users.is_locked,
+       users.must_reset_password,
+       COALESCE(role_policies.mfa_required, false)::boolean AS mfa_required
+FROM sessions
+JOIN users ON users.username = sessions.username
+LEFT JOIN role_policies ON role_policies.role = users.role
+WHERE sessions.id = $1 LIMIT 1;
+
+-- name: BlockSession :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1
+  AND is_blocked = false;
+
+-- name: ListUserSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND expired_at > now()
+ORDER BY last_used_at DESC;
+
+-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions
+SET refresh_token_hash = sqlc.arg(new_refresh_token_hash),
+    last_used_at       = now()
+WHERE id = sqlc.arg(id)
+  AND refresh_token_hash = sqlc.arg(refresh_token_hash)
+  AND is_blocked = false;
+
+-- name: MarkSessionMfaVerified :exec
+UPDATE sessions
+SET is_mfa_verified = true
+WHERE id = $1;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = now()
+WHERE id = $1;
+
+-- name: GetLoginHistory :one
+SELECT EXISTS(SELECT 1 FROM sessions s WHERE s.username = $1)                         AS has_sessions,
+       EXISTS(SELECT 1 FROM sessions s WHERE s.username = $1 AND s.user_agent = $2) AS known_device,
+       EXISTS(SELECT 1 FROM sessions s WHERE s.username = $1 AND s.client_ip = $3)  AS known_ip;
@@ -0,0 +1,31 @@
+-- name: CreateTransferChallenge :one
+INSERT INTO transfer_challenges (username,
+                                 email,
+                                 secret_code,
+                                 from_account_id,
+                                 to_account_id,
+                                 amount,
+                                 currency,
+                                 expired_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetTransferChallenge :one
+SELECT * FROM transfer_challenges
+WHERE id = $1 LIMIT 1;
+
+-- name: AttemptTransferChallenge :one
+UPDATE transfer_challenges
+SET attempts = attempts + 1
+WHERE id = @id
+  AND username = @username
+  AND is_used = false
+  AND expired_at > now()
+  AND attempts < @max_attempts::integer
+RETURNING *;
+
+-- name: UseTransferChallenge :execrows
+UPDATE transfer_challenges
+SET is_used = true
+WHERE id = $1
+  AND is_used = false;
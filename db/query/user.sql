@@ -14,7 +14,24 @@ SET
     password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at),
     full_name = COALESCE(sqlc.narg(full_name), full_name),
     email = COALESCE(sqlc.narg(email), email),
-    is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified)
+    is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified),
+    role = COALESCE(sqlc.narg(role), role),
+    is_locked = COALESCE(sqlc.narg(is_locked), is_locked),
+    -- setting a new password fulfils a forced reset
+    must_reset_password = COALESCE(sqlc.narg(must_reset_password), sqlc.narg(hashed_password) IS NULL AND must_reset_password),
+    version = version + 1
 WHERE
     username = sqlc.arg(username)
-RETURNING *;
\ No newline at end of file
+    -- a stale version matches no row
+    AND version = COALESCE(sqlc.narg(version), version)
+RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE sqlc.arg(search)::text = ''
+   OR username ILIKE '%' || sqlc.arg(search) || '%' ESCAPE '\'
+   OR full_name ILIKE '%' || sqlc.arg(search) || '%' ESCAPE '\'
+   OR email ILIKE '%' || sqlc.arg(search) || '%' ESCAPE '\'
+ORDER BY username
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
@@ -7,13 +7,15 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-set balance = balance + $1
+set balance = balance + $1,
+    version = version + 1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, parent_id, name, goal_amount, version
 `
 
 type AddAccountBalanceParams struct {
@@ -30,6 +32,10 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ParentID,
+		&i.Name,
+		&i.GoalAmount,
+		&i.Version,
 	)
 	return i, err
 }
@@ -37,7 +43,7 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (owner, balance, currency)
 VALUES ($1, $2, $3)
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, parent_id, name, goal_amount, version
 `
 
 type CreateAccountParams struct {
@@ -55,6 +61,41 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ParentID,
+		&i.Name,
+		&i.GoalAmount,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createPocket = `-- name: CreatePocket :one
+INSERT INTO accounts (owner, balance, currency, parent_id, name, goal_amount)
+SELECT owner, 0, currency, id, $1::varchar, $2::bigint
+FROM accounts
+WHERE id = $3 AND parent_id IS NULL
+RETURNING id, owner, balance, currency, created_at, parent_id, name, goal_amount, version
+`
+
+type CreatePocketParams struct {
+	Name       string `json:"name"`
+	GoalAmount int64  `json:"goal_amount"`
+	ParentID   int64  `json:"parent_id"`
+}
+
+func (q *Queries) CreatePocket(ctx context.Context, arg CreatePocketParams) (Account, error) {
+	row := q.db.QueryRow(ctx, createPocket, arg.Name, arg.GoalAmount, arg.ParentID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.ParentID,
+		&i.Name,
+		&i.GoalAmount,
+		&i.Version,
 	)
 	return i, err
 }
@@ -70,7 +111,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, parent_id, name, goal_amount, version FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -83,12 +124,37 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ParentID,
+		&i.Name,
+		&i.GoalAmount,
+		&i.Version,
 	)
 	return i, err
 }
 
+const getAccountBalanceAt = `-- name: GetAccountBalanceAt :one
+SELECT (accounts.balance - COALESCE((SELECT SUM(entries.amount)
+                                     FROM entries
+                                     WHERE entries.account_id = accounts.id
+                                       AND entries.created_at > $1), 0))::bigint AS balance
+FROM accounts
+WHERE accounts.id = $2
+`
+
+type GetAccountBalanceAtParams struct {
+	AsOf      time.Time `json:"as_of"`
+	AccountID int64     `json:"account_id"`
+}
+
+func (q *Queries) GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getAccountBalanceAt, arg.AsOf, arg.AccountID)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, parent_id, name, goal_amount, version FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -102,12 +168,55 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ParentID,
+		&i.Name,
+		&i.GoalAmount,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getOwnerAccount = `-- name: GetOwnerAccount :one
+SELECT id, owner, balance, currency, created_at, parent_id, name, goal_amount, version FROM accounts
+WHERE owner = $1 AND currency = $2 AND parent_id IS NULL LIMIT 1
+`
+
+type GetOwnerAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetOwnerAccount(ctx context.Context, arg GetOwnerAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getOwnerAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.ParentID,
+		&i.Name,
+		&i.GoalAmount,
+		&i.Version,
 	)
 	return i, err
 }
 
+const getPocketsBalance = `-- name: GetPocketsBalance :one
+SELECT COALESCE(SUM(balance), 0)::bigint AS total FROM accounts
+WHERE parent_id = $1::bigint
+`
+
+func (q *Queries) GetPocketsBalance(ctx context.Context, parentID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getPocketsBalance, parentID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, parent_id, name, goal_amount, version FROM accounts
 WHERE owner = $1
 ORDER BY id
 LIMIT $2
@@ -135,6 +244,91 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.ParentID,
+			&i.Name,
+			&i.GoalAmount,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMemberAccounts = `-- name: ListMemberAccounts :many
+SELECT accounts.id, accounts.owner, accounts.balance, accounts.currency, accounts.created_at, accounts.parent_id, accounts.name, accounts.goal_amount, accounts.version FROM accounts
+JOIN account_members ON account_members.account_id = accounts.id
+WHERE account_members.username = $1
+ORDER BY accounts.id
+LIMIT $2
+OFFSET $3
+`
+
+type ListMemberAccountsParams struct {
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+func (q *Queries) ListMemberAccounts(ctx context.Context, arg ListMemberAccountsParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listMemberAccounts, arg.Username, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.ParentID,
+			&i.Name,
+			&i.GoalAmount,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPockets = `-- name: ListPockets :many
+SELECT id, owner, balance, currency, created_at, parent_id, name, goal_amount, version FROM accounts
+WHERE parent_id = $1::bigint
+ORDER BY id
+`
+
+func (q *Queries) ListPockets(ctx context.Context, parentID int64) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listPockets, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.ParentID,
+			&i.Name,
+			&i.GoalAmount,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -148,9 +342,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
-set balance = $2
+set balance = $2,
+    version = version + 1
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, parent_id, name, goal_amount, version
 `
 
 type UpdateAccountParams struct {
@@ -167,6 +362,10 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ParentID,
+		&i.Name,
+		&i.GoalAmount,
+		&i.Version,
 	)
 	return i, err
 }
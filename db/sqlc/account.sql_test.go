@@ -87,6 +87,7 @@ func TestQueries_UpdateAccount(t *testing.T) {
 			require.Equal(t, tt.arg.Balance, got.Balance)
 			require.Equal(t, tt.account.Currency, got.Currency)
 			require.Equal(t, tt.account.Owner, got.Owner)
+			require.Equal(t, tt.account.Version+1, got.Version)
 			require.WithinDuration(t, tt.account.CreatedAt, got.CreatedAt, time.Second)
 		})
 	}
@@ -151,3 +152,37 @@ func TestQueries_ListAccounts(t *testing.T) {
 		})
 	}
 }
+
+func TestQueries_Pockets(t *testing.T) {
+	parent := fundAccount(t, createRandomAccountWithOwner(t).Account, 15)
+
+	pocket1, err := testStore.CreatePocket(context.Background(), CreatePocketParams{Name: "holidays", GoalAmount: 1000, ParentID: parent.ID})
+	require.NoError(t, err)
+	require.Equal(t, parent.Owner, pocket1.Owner)
+	require.Equal(t, parent.Currency, pocket1.Currency)
+	require.Equal(t, parent.ID, pocket1.ParentID.Int64)
+	require.Zero(t, pocket1.Balance)
+
+	pocket2, err := testStore.CreatePocket(context.Background(), CreatePocketParams{Name: "car", ParentID: parent.ID})
+	require.NoError(t, err)
+
+	_, err = testStore.CreatePocket(context.Background(), CreatePocketParams{Name: "holidays", ParentID: parent.ID})
+	require.Equal(t, UniqueViolationCode, ErrorCode(err))
+
+	// pockets cannot be nested
+	_, err = testStore.CreatePocket(context.Background(), CreatePocketParams{Name: "nested", ParentID: pocket1.ID})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{FromAccountID: parent.ID, ToAccountID: pocket1.ID, Amount: 10})
+	require.NoError(t, err)
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{FromAccountID: parent.ID, ToAccountID: pocket2.ID, Amount: 5})
+	require.NoError(t, err)
+
+	total, err := testStore.GetPocketsBalance(context.Background(), parent.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), total)
+
+	pockets, err := testStore.ListPockets(context.Background(), parent.ID)
+	require.NoError(t, err)
+	require.Len(t, pockets, 2)
+}
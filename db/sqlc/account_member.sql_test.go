@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createRandomAccountWithOwner(t *testing.T) CreateAccountTxResult {
+	user := createRandomUser(t)
+	arg := CreateAccountParams{Owner: user.Username, Balance: util.RandomMoney(), Currency: util.RandomCurrency()}
+
+	result, err := testStore.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+
+	require.Equal(t, arg.Owner, result.Account.Owner)
+	require.Equal(t, result.Account.ID, result.Member.AccountID)
+	require.Equal(t, arg.Owner, result.Member.Username)
+	require.Equal(t, util.AccountOwnerRole, result.Member.Role)
+	require.NotZero(t, result.Member.CreatedAt)
+
+	return result
+}
+
+func TestStore_CreateAccountTx(t *testing.T) {
+	createRandomAccountWithOwner(t)
+}
+
+func TestQueries_AccountMembers(t *testing.T) {
+	owner := createRandomAccountWithOwner(t)
+	signer := createRandomUser(t)
+
+	member, err := testStore.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID: owner.Account.ID,
+		Username:  signer.Username,
+		Role:      util.AccountSignerRole,
+		InvitedBy: owner.Member.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.AccountSignerRole, member.Role)
+	require.Equal(t, owner.Member.Username, member.InvitedBy)
+
+	got, err := testStore.GetAccountMember(context.Background(), GetAccountMemberParams{AccountID: owner.Account.ID, Username: signer.Username})
+	require.NoError(t, err)
+	require.Equal(t, member, got)
+
+	members, err := testStore.ListAccountMembers(context.Background(), owner.Account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	accounts, err := testStore.ListMemberAccounts(context.Background(), ListMemberAccountsParams{Username: signer.Username, Limit: 5, Offset: 0})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, owner.Account.ID, accounts[0].ID)
+
+	deleted, err := testStore.DeleteAccountMember(context.Background(), DeleteAccountMemberParams{AccountID: owner.Account.ID, Username: signer.Username})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	deleted, err = testStore.DeleteAccountMember(context.Background(), DeleteAccountMemberParams{AccountID: owner.Account.ID, Username: signer.Username})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	_, err = testStore.GetAccountMember(context.Background(), GetAccountMemberParams{AccountID: owner.Account.ID, Username: signer.Username})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
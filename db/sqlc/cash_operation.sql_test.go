@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_CashOperationTx(t *testing.T) {
+	account := createRandomAccountWithOwner(t).Account
+	banker := createRandomUser(t)
+	amount := int64(10)
+
+	deposit, err := testStore.CashOperationTx(context.Background(), CashOperationTxParams{
+		Type:      util.DepositOperation,
+		AccountID: account.ID,
+		Amount:    amount,
+		Reference: util.RandomString(12),
+		Memo:      "counter deposit",
+		Banker:    banker.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+amount, deposit.Account.Balance)
+	require.Equal(t, util.BankCashUsername, deposit.Transfer.FromAccount.Owner)
+	require.Equal(t, account.Currency, deposit.Transfer.FromAccount.Currency)
+	require.Equal(t, deposit.Transfer.Transfer.ID, deposit.CashOperation.TransferID)
+	require.Equal(t, banker.Username, deposit.CashOperation.Banker)
+
+	withdrawal, err := testStore.CashOperationTx(context.Background(), CashOperationTxParams{
+		Type:      util.WithdrawalOperation,
+		AccountID: account.ID,
+		Amount:    amount,
+		Reference: util.RandomString(12),
+		Banker:    banker.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, withdrawal.Account.Balance)
+	require.Equal(t, deposit.CashOperation.CashAccountID, withdrawal.Transfer.ToAccount.ID)
+
+	_, err = testStore.CashOperationTx(context.Background(), CashOperationTxParams{
+		Type:      util.WithdrawalOperation,
+		AccountID: account.ID,
+		Amount:    account.Balance + 1,
+		Reference: util.RandomString(12),
+		Banker:    banker.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = testStore.CashOperationTx(context.Background(), CashOperationTxParams{
+		Type:      util.DepositOperation,
+		AccountID: account.ID,
+		Amount:    amount,
+		Reference: deposit.CashOperation.Reference,
+		Banker:    banker.Username,
+	})
+	require.Equal(t, UniqueViolationCode, ErrorCode(err))
+
+	operations, err := testStore.ListCashOperations(context.Background(), ListCashOperationsParams{AccountID: account.ID, Limit: 5, Offset: 0})
+	require.NoError(t, err)
+	require.Len(t, operations, 2)
+}
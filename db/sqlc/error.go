@@ -12,6 +12,9 @@ const (
 )
 
 var ErrRecordNotFound = pgx.ErrNoRows
+var ErrInsufficientFunds = errors.New("insufficient funds")
+var ErrChallengeUsed = errors.New("challenge already used")
+var ErrHistoryArchived = errors.New("history is archived")
 var ErrUniqueViolation = &pgconn.PgError{Code: UniqueViolationCode, Message: "unique violation"}
 
 func ErrorCode(err error) string {
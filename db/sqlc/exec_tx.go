@@ -3,9 +3,20 @@ package db
 import (
 	"context"
 	"fmt"
+	"go.opentelemetry.io/otel/codes"
 )
 
-func (s *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+// execTx runs fn in a transaction, traced as a span with the given name
+func (s *SQLStore) execTx(ctx context.Context, name string, fn func(*Queries) error) (err error) {
+	ctx, span := tracer.Start(ctx, name)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	tx, err := s.connPool.Begin(ctx)
 	if err != nil {
 		return err
@@ -0,0 +1,10 @@
+package db
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike makes the wildcards of a search match themselves in a LIKE pattern escaped by '\'
+func EscapeLike(search string) string {
+	return likeEscaper.Replace(search)
+}
@@ -0,0 +1,13 @@
+package db
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEscapeLike(t *testing.T) {
+	require.Equal(t, "john", EscapeLike("john"))
+	require.Equal(t, `100\%`, EscapeLike("100%"))
+	require.Equal(t, `john\_doe`, EscapeLike("john_doe"))
+	require.Equal(t, `a\\b`, EscapeLike(`a\b`))
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomTotpSecret(t *testing.T, username string) TotpSecret {
+	arg := CreateTotpSecretParams{Username: username, Secret: util.RandomString(32)}
+
+	secret, err := testStore.CreateTotpSecret(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, secret.Username)
+	require.Equal(t, arg.Secret, secret.Secret)
+	require.False(t, secret.IsConfirmed)
+	require.Zero(t, secret.LastUsedStep)
+
+	return secret
+}
+
+func TestQueries_CreateTotpSecret(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTotpSecret(t, user.Username)
+
+	// a pending secret is replaced by enrolling again
+	replaced := createRandomTotpSecret(t, user.Username)
+
+	rows, err := testStore.UseTotpStep(context.Background(), UseTotpStepParams{Step: 10, Username: user.Username})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// a confirmed secret is kept
+	_, err = testStore.CreateTotpSecret(context.Background(), CreateTotpSecretParams{Username: user.Username, Secret: util.RandomString(32)})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	secret, err := testStore.GetTotpSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, replaced.Secret, secret.Secret)
+	require.True(t, secret.IsConfirmed)
+	require.Equal(t, int64(10), secret.LastUsedStep)
+}
+
+func TestQueries_UseTotpStep(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTotpSecret(t, user.Username)
+
+	rows, err := testStore.UseTotpStep(context.Background(), UseTotpStepParams{Step: 10, Username: user.Username})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// a code of the same or an earlier step is a replay
+	for _, step := range []int64{10, 9} {
+		rows, err = testStore.UseTotpStep(context.Background(), UseTotpStepParams{Step: step, Username: user.Username})
+		require.NoError(t, err)
+		require.Zero(t, rows)
+	}
+}
+
+func TestQueries_UseRecoveryCode(t *testing.T) {
+	user := createRandomUser(t)
+	codeHash := util.HashToken(util.RandomString(16))
+
+	_, err := testStore.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{Username: user.Username, CodeHash: codeHash})
+	require.NoError(t, err)
+
+	arg := UseRecoveryCodeParams{Username: user.Username, CodeHash: codeHash}
+	rows, err := testStore.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testStore.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestQueries_AttemptMfaChallenge(t *testing.T) {
+	user := createRandomUser(t)
+	tokenHash := util.HashToken(util.RandomString(32))
+
+	challenge, err := testStore.CreateMfaChallenge(context.Background(), CreateMfaChallengeParams{
+		Username:  user.Username,
+		TokenHash: tokenHash,
+		ExpiredAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, challenge.Attempts)
+
+	arg := AttemptMfaChallengeParams{TokenHash: tokenHash, MaxAttempts: 2}
+	for attempts := int32(1); attempts <= 2; attempts++ {
+		attempted, err := testStore.AttemptMfaChallenge(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, challenge.ID, attempted.ID)
+		require.Equal(t, attempts, attempted.Attempts)
+	}
+
+	_, err = testStore.AttemptMfaChallenge(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	rows, err := testStore.UseMfaChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testStore.UseMfaChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestStore_ConfirmTotpTx(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+	createRandomTotpSecret(t, user.Username)
+
+	arg := ConfirmTotpTxParams{
+		Username:           user.Username,
+		Step:               10,
+		RecoveryCodeHashes: []string{util.HashToken("a"), util.HashToken("b")},
+		SessionID:          session.ID,
+	}
+	result, err := testStore.ConfirmTotpTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.RecoveryCodes, 2)
+
+	secret, err := testStore.GetTotpSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, secret.IsConfirmed)
+
+	verified, err := testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, verified.IsMfaVerified)
+
+	// the code can't confirm twice
+	_, err = testStore.ConfirmTotpTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
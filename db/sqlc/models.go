@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Account struct {
@@ -16,6 +17,47 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// set for pockets, references the main account
+	ParentID pgtype.Int8 `json:"parent_id"`
+	Name     string      `json:"name"`
+	// 0 means no goal
+	GoalAmount int64 `json:"goal_amount"`
+	// incremented by every update, guards concurrent updates
+	Version int64 `json:"version"`
+}
+
+type AccountMember struct {
+	AccountID int64     `json:"account_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	InvitedBy string    `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID     int64  `json:"id"`
+	Banker string `json:"banker"`
+	Action string `json:"action"`
+	// username the action was applied to, empty for searches
+	Target    string    `json:"target"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CashOperation struct {
+	ID int64 `json:"id"`
+	// deposit or withdrawal
+	Type          string `json:"type"`
+	AccountID     int64  `json:"account_id"`
+	CashAccountID int64  `json:"cash_account_id"`
+	// no foreign key, the transfer may be archived
+	TransferID int64 `json:"transfer_id"`
+	// must be positive
+	Amount    int64     `json:"amount"`
+	Reference string    `json:"reference"`
+	Memo      string    `json:"memo"`
+	Banker    string    `json:"banker"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -26,15 +68,56 @@ type Entry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type MfaChallenge struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the challenge token returned by the login
+	TokenHash string    `json:"token_hash"`
+	Attempts  int32     `json:"attempts"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type RecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the recovery code
+	CodeHash  string    `json:"code_hash"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RolePolicy struct {
+	Role        string    `json:"role"`
+	MfaRequired bool      `json:"mfa_required"`
+	UpdatedBy   string    `json:"updated_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiredAt    time.Time `json:"expired_at"`
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// sha256 of the current refresh token, replaced on every renewal
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	UserAgent        string    `json:"user_agent"`
+	ClientIp         string    `json:"client_ip"`
+	IsBlocked        bool      `json:"is_blocked"`
+	ExpiredAt        time.Time `json:"expired_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	LastUsedAt       time.Time `json:"last_used_at"`
+	IsMfaVerified    bool      `json:"is_mfa_verified"`
+}
+
+type TotpSecret struct {
+	Username    string `json:"username"`
+	Secret      string `json:"secret"`
+	IsConfirmed bool   `json:"is_confirmed"`
+	// time step of the last accepted code, a code can't be replayed
+	LastUsedStep int64     `json:"last_used_step"`
 	CreatedAt    time.Time `json:"created_at"`
+	// codes tried to confirm the pending secret, enrolling again resets them
+	ConfirmAttempts int32 `json:"confirm_attempts"`
 }
 
 type Transfer struct {
@@ -46,6 +129,22 @@ type Transfer struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type TransferChallenge struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	// emailed 6-digit code, it only confirms the transfer it was issued for
+	SecretCode    string    `json:"secret_code"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Attempts      int32     `json:"attempts"`
+	IsUsed        bool      `json:"is_used"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiredAt     time.Time `json:"expired_at"`
+}
+
 type User struct {
 	Username          string    `json:"username"`
 	HashedPassword    string    `json:"hashed_password"`
@@ -55,6 +154,10 @@ type User struct {
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	IsEmailVerified   bool      `json:"is_email_verified"`
 	Role              string    `json:"role"`
+	IsLocked          bool      `json:"is_locked"`
+	MustResetPassword bool      `json:"must_reset_password"`
+	// incremented by every update, guards concurrent updates
+	Version int64 `json:"version"`
 }
 
 type VerifyEmail struct {
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"strings"
+	"time"
+)
+
+// PartitionedTables are split into monthly partitions by created_at
+var PartitionedTables = []string{"entries", "transfers"}
+
+// ExportPartition writes the rows of the partition to w as CSV, the partition stays attached so its rows
+// are still listed if the export fails. Expired months don't get new rows, the export is complete.
+// A partition left detached by an older run is exported as well.
+func (s *SQLStore) ExportPartition(ctx context.Context, partition string, w io.Writer) error {
+	conn, err := s.connPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	partitionIdent := pgx.Identifier{partition}.Sanitize()
+	if _, err = conn.Conn().PgConn().CopyTo(ctx, w, fmt.Sprintf("COPY %s TO STDOUT WITH (FORMAT csv, HEADER)", partitionIdent)); err != nil {
+		return fmt.Errorf("cannot export partition: %w", err)
+	}
+
+	return nil
+}
+
+// DropPartition detaches an exported partition from its parent and drops it in one tx,
+// the rows never disappear from the parent without being gone for good
+func (s *SQLStore) DropPartition(ctx context.Context, parent string, partition string) error {
+	return s.execTx(ctx, "DropPartition", func(q *Queries) error {
+		var attached bool
+		err := q.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_inherits WHERE inhrelid = $1::regclass)", partition).Scan(&attached)
+		if err != nil {
+			return err
+		}
+
+		partitionIdent := pgx.Identifier{partition}.Sanitize()
+		if attached {
+			if _, err = q.db.Exec(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", pgx.Identifier{parent}.Sanitize(), partitionIdent)); err != nil {
+				return fmt.Errorf("cannot detach partition: %w", err)
+			}
+		}
+
+		_, err = q.db.Exec(ctx, fmt.Sprintf("DROP TABLE %s", partitionIdent))
+		return err
+	})
+}
+
+// GetAccountBalanceAt rewinds the balance by the entries made after asOf. The entries of archived
+// partitions are gone, so asOf before the oldest attached month returns ErrHistoryArchived
+func (s *SQLStore) GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error) {
+	oldest, err := s.GetOldestPartition(ctx, "entries")
+	if err != nil {
+		return 0, err
+	}
+	if oldest != "" {
+		start, err := time.Parse("2006_01", strings.TrimPrefix(oldest, "entries_"))
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse partition %s: %w", oldest, err)
+		}
+		if arg.AsOf.Before(start) {
+			return 0, ErrHistoryArchived
+		}
+	}
+
+	return s.Queries.GetAccountBalanceAt(ctx, arg)
+}
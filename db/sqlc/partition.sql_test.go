@@ -0,0 +1,80 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueries_CreateMonthlyPartition(t *testing.T) {
+	month := time.Date(2100, time.February, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, parent := range PartitionedTables {
+		partition, err := testStore.CreateMonthlyPartition(context.Background(), CreateMonthlyPartitionParams{Parent: parent, Month: month})
+		require.NoError(t, err)
+		require.Equal(t, parent+"_2100_02", partition)
+
+		// creating it again is a no-op
+		again, err := testStore.CreateMonthlyPartition(context.Background(), CreateMonthlyPartitionParams{Parent: parent, Month: month})
+		require.NoError(t, err)
+		require.Equal(t, partition, again)
+
+		expired, err := testStore.ListExpiredPartitions(context.Background(), ListExpiredPartitionsParams{Parent: parent, Before: month})
+		require.NoError(t, err)
+		require.NotContains(t, expired, partition)
+		require.NotContains(t, expired, parent+"_default")
+	}
+}
+
+func TestQueries_GetAccountBalanceAt(t *testing.T) {
+	account := createRandomAccountWithOwner(t).Account
+	before := time.Now()
+
+	_, err := testStore.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 10})
+	require.NoError(t, err)
+	account, err = testStore.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: 10})
+	require.NoError(t, err)
+
+	balance, err := testStore.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{AsOf: before, AccountID: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-10, balance)
+
+	balance, err = testStore.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{AsOf: time.Now(), AccountID: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance)
+
+	// no partition holds the entries of that time anymore
+	oldest, err := testStore.GetOldestPartition(context.Background(), "entries")
+	require.NoError(t, err)
+	require.Regexp(t, `^entries_\d{4}_\d{2}$`, oldest)
+
+	_, err = testStore.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{AsOf: time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC), AccountID: account.ID})
+	require.ErrorIs(t, err, ErrHistoryArchived)
+}
+
+func TestStore_ExportAndDropPartition(t *testing.T) {
+	month := time.Date(2101, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, parent := range PartitionedTables {
+		partition, err := testStore.CreateMonthlyPartition(context.Background(), CreateMonthlyPartitionParams{Parent: parent, Month: month})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, testStore.ExportPartition(context.Background(), partition, &buf))
+		require.True(t, strings.HasPrefix(buf.String(), "id,"))
+
+		arg := ListExpiredPartitionsParams{Parent: parent, Before: month.AddDate(0, 1, 0)}
+		expired, err := testStore.ListExpiredPartitions(context.Background(), arg)
+		require.NoError(t, err)
+		require.Contains(t, expired, partition)
+
+		require.NoError(t, testStore.DropPartition(context.Background(), parent, partition))
+
+		expired, err = testStore.ListExpiredPartitions(context.Background(), arg)
+		require.NoError(t, err)
+		require.NotContains(t, expired, partition)
+	}
+}
@@ -12,25 +12,67 @@ import (
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AttemptMfaChallenge(ctx context.Context, arg AttemptMfaChallengeParams) (MfaChallenge, error)
+	AttemptTotpConfirmation(ctx context.Context, arg AttemptTotpConfirmationParams) (TotpSecret, error)
+	AttemptTransferChallenge(ctx context.Context, arg AttemptTransferChallengeParams) (TransferChallenge, error)
+	BlockSession(ctx context.Context, id uuid.UUID) error
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateCashOperation(ctx context.Context, arg CreateCashOperationParams) (CashOperation, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateMfaChallenge(ctx context.Context, arg CreateMfaChallengeParams) (MfaChallenge, error)
+	CreateMonthlyPartition(ctx context.Context, arg CreateMonthlyPartitionParams) (string, error)
+	CreatePocket(ctx context.Context, arg CreatePocketParams) (Account, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTotpSecret(ctx context.Context, arg CreateTotpSecretParams) (TotpSecret, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferChallenge(ctx context.Context, arg CreateTransferChallengeParams) (TransferChallenge, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) (int64, error)
+	DeleteRecoveryCodes(ctx context.Context, username string) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetAuthSession(ctx context.Context, id uuid.UUID) (GetAuthSessionRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetLoginHistory(ctx context.Context, arg GetLoginHistoryParams) (GetLoginHistoryRow, error)
+	GetOldestPartition(ctx context.Context, parent string) (string, error)
+	GetOwnerAccount(ctx context.Context, arg GetOwnerAccountParams) (Account, error)
+	GetPocketsBalance(ctx context.Context, parentID int64) (int64, error)
+	GetRolePolicy(ctx context.Context, role string) (RolePolicy, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetTotpSecret(ctx context.Context, username string) (TotpSecret, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferChallenge(ctx context.Context, id int64) (TransferChallenge, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListCashOperations(ctx context.Context, arg ListCashOperationsParams) ([]CashOperation, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredPartitions(ctx context.Context, arg ListExpiredPartitionsParams) ([]string, error)
+	ListMemberAccounts(ctx context.Context, arg ListMemberAccountsParams) ([]Account, error)
+	ListPockets(ctx context.Context, parentID int64) ([]Account, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUserSessions(ctx context.Context, username string) ([]Session, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	MarkSessionMfaVerified(ctx context.Context, id uuid.UUID) error
+	RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (int64, error)
+	TouchSession(ctx context.Context, id uuid.UUID) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	UpsertRolePolicy(ctx context.Context, arg UpsertRolePolicyParams) (RolePolicy, error)
+	UseMfaChallenge(ctx context.Context, id int64) (int64, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTotpStep(ctx context.Context, arg UseTotpStepParams) (int64, error)
+	UseTransferChallenge(ctx context.Context, id int64) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+	"math"
+	"time"
+)
+
+const replicaLagQuery = `
+SELECT CASE
+           WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+           ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+           END::float8
+`
+
+var (
+	replicaLagSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simplebank_db_replica_lag_seconds",
+		Help: "Replication lag of the read replica, +Inf when it cannot be measured.",
+	})
+	replicaReadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "simplebank_db_replica_reads_total",
+		Help: "Read-only queries by the pool that served them.",
+	}, []string{"pool"})
+)
+
+type primaryKey struct{}
+
+// WithPrimary forces every read made with the returned context to go to the primary,
+// so a request can read its own writes
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func isPrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// NewStoreWithReplica creates a store that sends read-only queries made outside transactions to the replica
+// as long as its lag stays under maxLag. Run MonitorReplicaLag to keep the lag up to date.
+func NewStoreWithReplica(connPool *pgxpool.Pool, replicaPool *pgxpool.Pool, maxLag time.Duration) *SQLStore {
+	store := &SQLStore{
+		connPool:      connPool,
+		Queries:       New(connPool),
+		replicaPool:   replicaPool,
+		replica:       New(replicaPool),
+		maxReplicaLag: maxLag,
+	}
+	// until the first measurement the replica is considered behind
+	store.replicaLag.Store(math.MaxInt64)
+	return store
+}
+
+// MonitorReplicaLag measures the replica lag every interval until ctx is done
+func (s *SQLStore) MonitorReplicaLag(ctx context.Context, interval time.Duration) {
+	if s.replicaPool == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.measureReplicaLag(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SQLStore) measureReplicaLag(ctx context.Context) {
+	var seconds float64
+	if err := s.replicaPool.QueryRow(ctx, replicaLagQuery).Scan(&seconds); err != nil {
+		log.Error().Err(err).Msg("cannot measure replica lag")
+		s.replicaLag.Store(math.MaxInt64)
+		replicaLagSeconds.Set(math.Inf(1))
+		return
+	}
+
+	s.replicaLag.Store(int64(seconds * float64(time.Second)))
+	replicaLagSeconds.Set(seconds)
+}
+
+// reader returns the queries that should serve a read-only query, reads are only counted
+// when there is a replica to choose from
+func (s *SQLStore) reader(ctx context.Context) *Queries {
+	if s.replica == nil {
+		return s.Queries
+	}
+
+	if isPrimaryForced(ctx) || time.Duration(s.replicaLag.Load()) > s.maxReplicaLag {
+		replicaReadsTotal.WithLabelValues("primary").Inc()
+		return s.Queries
+	}
+
+	replicaReadsTotal.WithLabelValues("replica").Inc()
+	return s.replica
+}
+
+func (s *SQLStore) GetAccount(ctx context.Context, id int64) (Account, error) {
+	return s.reader(ctx).GetAccount(ctx, id)
+}
+
+func (s *SQLStore) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	return s.reader(ctx).ListAccounts(ctx, arg)
+}
+
+func (s *SQLStore) ListMemberAccounts(ctx context.Context, arg ListMemberAccountsParams) ([]Account, error) {
+	return s.reader(ctx).ListMemberAccounts(ctx, arg)
+}
+
+func (s *SQLStore) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	return s.reader(ctx).ListEntries(ctx, arg)
+}
+
+func (s *SQLStore) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	return s.reader(ctx).ListTransfers(ctx, arg)
+}
+
+func (s *SQLStore) GetUser(ctx context.Context, username string) (User, error) {
+	return s.reader(ctx).GetUser(ctx, username)
+}
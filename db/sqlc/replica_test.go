@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSQLStore_reader(t *testing.T) {
+	store := NewStoreWithReplica(nil, nil, time.Second)
+	ctx := context.Background()
+
+	// lag is unknown until measured
+	require.Same(t, store.Queries, store.reader(ctx))
+
+	store.replicaLag.Store(int64(100 * time.Millisecond))
+	require.Same(t, store.replica, store.reader(ctx))
+	require.Same(t, store.Queries, store.reader(WithPrimary(ctx)))
+
+	store.replicaLag.Store(int64(2 * time.Second))
+	require.Same(t, store.Queries, store.reader(ctx))
+
+	store.replicaLag.Store(math.MaxInt64)
+	require.Same(t, store.Queries, store.reader(ctx))
+
+	// a store without a replica has no reads to count
+	primaryReads := testutil.ToFloat64(replicaReadsTotal.WithLabelValues("primary"))
+	withoutReplica := NewStore(nil).(*SQLStore)
+	require.Same(t, withoutReplica.Queries, withoutReplica.reader(ctx))
+	require.Equal(t, primaryReads, testutil.ToFloat64(replicaReadsTotal.WithLabelValues("primary")))
+}
@@ -12,54 +12,149 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockSession = `-- name: BlockSession :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1
+`
+
+func (q *Queries) BlockSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, blockSession, id)
+	return err
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1
+  AND is_blocked = false
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, blockUserSessions, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (id,
                       username,
-                      refresh_token,
+                      refresh_token_hash,
                       user_agent,
                       client_ip,
                       is_blocked,
-                      expired_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at
+                      expired_at,
+                      is_mfa_verified)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, username, refresh_token_hash, user_agent, client_ip, is_blocked, expired_at, created_at, last_used_at, is_mfa_verified
 `
 
 type CreateSessionParams struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiredAt    time.Time `json:"expired_at"`
+	ID               uuid.UUID `json:"id"`
+	Username         string    `json:"username"`
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	UserAgent        string    `json:"user_agent"`
+	ClientIp         string    `json:"client_ip"`
+	IsBlocked        bool      `json:"is_blocked"`
+	ExpiredAt        time.Time `json:"expired_at"`
+	IsMfaVerified    bool      `json:"is_mfa_verified"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
 	row := q.db.QueryRow(ctx, createSession,
 		arg.ID,
 		arg.Username,
-		arg.RefreshToken,
+		arg.RefreshTokenHash,
 		arg.UserAgent,
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiredAt,
+		arg.IsMfaVerified,
 	)
 	var i Session
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
-		&i.RefreshToken,
+		&i.RefreshTokenHash,
 		&i.UserAgent,
 		&i.ClientIp,
 		&i.IsBlocked,
 		&i.ExpiredAt,
 		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.IsMfaVerified,
+	)
+	return i, err
+}
+
+const getAuthSession = `-- name: GetAuthSession :one
+SELECT sessions.id, sessions.username, sessions.refresh_token_hash, sessions.user_agent, sessions.client_ip, sessions.is_blocked, sessions.expired_at, sessions.created_at, sessions.last_used_at, sessions.is_mfa_verified,
+       users.is_locked,
+       users.must_reset_password,
+       COALESCE(role_policies.mfa_required, false)::boolean AS mfa_required
+FROM sessions
+JOIN users ON users.username = sessions.username
+LEFT JOIN role_policies ON role_policies.role = users.role
+WHERE sessions.id = $1 LIMIT 1
+`
+
+type GetAuthSessionRow struct {
+	Session           Session `json:"session"`
+	IsLocked          bool    `json:"is_locked"`
+	MustResetPassword bool    `json:"must_reset_password"`
+	MfaRequired       bool    `json:"mfa_required"`
+}
+
+func (q *Queries) GetAuthSession(ctx context.Context, id uuid.UUID) (GetAuthSessionRow, error) {
+	row := q.db.QueryRow(ctx, getAuthSession, id)
+	var i GetAuthSessionRow
+	err := row.Scan(
+		&i.Session.ID,
+		&i.Session.Username,
+		&i.Session.RefreshTokenHash,
+		&i.Session.UserAgent,
+		&i.Session.ClientIp,
+		&i.Session.IsBlocked,
+		&i.Session.ExpiredAt,
+		&i.Session.CreatedAt,
+		&i.Session.LastUsedAt,
+		&i.Session.IsMfaVerified,
+		&i.IsLocked,
+		&i.MustResetPassword,
+		&i.MfaRequired,
 	)
 	return i, err
 }
 
+const getLoginHistory = `-- name: GetLoginHistory :one
+SELECT EXISTS(SELECT 1 FROM sessions s WHERE s.username = $1)                         AS has_sessions,
+       EXISTS(SELECT 1 FROM sessions s WHERE s.username = $1 AND s.user_agent = $2) AS known_device,
+       EXISTS(SELECT 1 FROM sessions s WHERE s.username = $1 AND s.client_ip = $3)  AS known_ip
+`
+
+type GetLoginHistoryParams struct {
+	Username  string `json:"username"`
+	UserAgent string `json:"user_agent"`
+	ClientIp  string `json:"client_ip"`
+}
+
+type GetLoginHistoryRow struct {
+	HasSessions bool `json:"has_sessions"`
+	KnownDevice bool `json:"known_device"`
+	KnownIp     bool `json:"known_ip"`
+}
+
+func (q *Queries) GetLoginHistory(ctx context.Context, arg GetLoginHistoryParams) (GetLoginHistoryRow, error) {
+	row := q.db.QueryRow(ctx, getLoginHistory, arg.Username, arg.UserAgent, arg.ClientIp)
+	var i GetLoginHistoryRow
+	err := row.Scan(&i.HasSessions, &i.KnownDevice, &i.KnownIp)
+	return i, err
+}
+
 const getSession = `-- name: GetSession :one
-SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at FROM sessions
+SELECT id, username, refresh_token_hash, user_agent, client_ip, is_blocked, expired_at, created_at, last_used_at, is_mfa_verified FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
@@ -69,12 +164,98 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
-		&i.RefreshToken,
+		&i.RefreshTokenHash,
 		&i.UserAgent,
 		&i.ClientIp,
 		&i.IsBlocked,
 		&i.ExpiredAt,
 		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.IsMfaVerified,
 	)
 	return i, err
 }
+
+const listUserSessions = `-- name: ListUserSessions :many
+SELECT id, username, refresh_token_hash, user_agent, client_ip, is_blocked, expired_at, created_at, last_used_at, is_mfa_verified FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND expired_at > now()
+ORDER BY last_used_at DESC
+`
+
+func (q *Queries) ListUserSessions(ctx context.Context, username string) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listUserSessions, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshTokenHash,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiredAt,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.IsMfaVerified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSessionRefreshToken = `-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions
+SET refresh_token_hash = $1,
+    last_used_at       = now()
+WHERE id = $2
+  AND refresh_token_hash = $3
+  AND is_blocked = false
+`
+
+type RotateSessionRefreshTokenParams struct {
+	NewRefreshTokenHash string    `json:"new_refresh_token_hash"`
+	ID                  uuid.UUID `json:"id"`
+	RefreshTokenHash    string    `json:"refresh_token_hash"`
+}
+
+func (q *Queries) RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rotateSessionRefreshToken, arg.NewRefreshTokenHash, arg.ID, arg.RefreshTokenHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markSessionMfaVerified = `-- name: MarkSessionMfaVerified :exec
+UPDATE sessions
+SET is_mfa_verified = true
+WHERE id = $1
+`
+
+func (q *Queries) MarkSessionMfaVerified(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markSessionMfaVerified, id)
+	return err
+}
+
+const touchSession = `-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) TouchSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchSession, id)
+	return err
+}
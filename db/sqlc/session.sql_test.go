@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomSession(t *testing.T, username string) Session {
+	return createSessionFrom(t, username, util.RandomString(10), "127.0.0.1")
+}
+
+func createSessionFrom(t *testing.T, username, userAgent, clientIP string) Session {
+	arg := CreateSessionParams{
+		ID:               uuid.New(),
+		Username:         username,
+		RefreshTokenHash: util.HashToken(util.RandomString(32)),
+		UserAgent:        userAgent,
+		ClientIp:         clientIP,
+		ExpiredAt:        time.Now().Add(time.Hour).UTC(),
+	}
+
+	session, err := testStore.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, session.CreatedAt, session.LastUsedAt, time.Second)
+
+	return session
+}
+
+func TestQueries_BlockSession(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	session2 := createRandomSession(t, user.Username)
+
+	err := testStore.BlockSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+
+	blocked, err := testStore.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	active, err := testStore.GetSession(context.Background(), session2.ID)
+	require.NoError(t, err)
+	require.False(t, active.IsBlocked)
+}
+
+func TestQueries_RotateSessionRefreshToken(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+
+	arg := RotateSessionRefreshTokenParams{
+		NewRefreshTokenHash: util.HashToken(util.RandomString(32)),
+		ID:                  session.ID,
+		RefreshTokenHash:    session.RefreshTokenHash,
+	}
+	rows, err := testStore.RotateSessionRefreshToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rotated, err := testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewRefreshTokenHash, rotated.RefreshTokenHash)
+
+	// the old token was replaced, rotating it again changes nothing
+	rows, err = testStore.RotateSessionRefreshToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	// a blocked session can't be renewed
+	err = testStore.BlockSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	arg.RefreshTokenHash = rotated.RefreshTokenHash
+	rows, err = testStore.RotateSessionRefreshToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestQueries_BlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	otherSession := createRandomSession(t, other.Username)
+
+	n := 3
+	sessions := make([]Session, n)
+	for i := range sessions {
+		sessions[i] = createRandomSession(t, user.Username)
+	}
+
+	rows, err := testStore.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(n), rows)
+
+	for _, session := range sessions {
+		blocked, err := testStore.GetSession(context.Background(), session.ID)
+		require.NoError(t, err)
+		require.True(t, blocked.IsBlocked)
+	}
+
+	active, err := testStore.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.False(t, active.IsBlocked)
+
+	// already blocked sessions are not counted again
+	rows, err = testStore.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestQueries_ListUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	session2 := createRandomSession(t, user.Username)
+	blocked := createRandomSession(t, user.Username)
+	require.NoError(t, testStore.BlockSession(context.Background(), blocked.ID))
+
+	// session1 becomes the most recently used one
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, testStore.TouchSession(context.Background(), session1.ID))
+
+	sessions, err := testStore.ListUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, session1.ID, sessions[0].ID)
+	require.Equal(t, session2.ID, sessions[1].ID)
+	require.True(t, sessions[0].LastUsedAt.After(session1.LastUsedAt))
+}
+
+func TestQueries_GetLoginHistory(t *testing.T) {
+	user := createRandomUser(t)
+	userAgent := util.RandomString(10)
+
+	history, err := testStore.GetLoginHistory(context.Background(), GetLoginHistoryParams{
+		Username:  user.Username,
+		UserAgent: userAgent,
+		ClientIp:  "10.0.0.1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, GetLoginHistoryRow{}, history)
+
+	createSessionFrom(t, user.Username, userAgent, "10.0.0.1")
+
+	history, err = testStore.GetLoginHistory(context.Background(), GetLoginHistoryParams{
+		Username:  user.Username,
+		UserAgent: userAgent,
+		ClientIp:  "10.0.0.2",
+	})
+	require.NoError(t, err)
+	require.Equal(t, GetLoginHistoryRow{HasSessions: true, KnownDevice: true, KnownIp: false}, history)
+}
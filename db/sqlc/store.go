@@ -3,18 +3,33 @@ package db
 import (
 	"context"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"io"
+	"sync/atomic"
+	"time"
 )
 
 type Store interface {
 	Querier
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (CreateAccountTxResult, error)
+	AddAccountMemberTx(ctx context.Context, arg AddAccountMemberTxParams) (AddAccountMemberTxResult, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	CashOperationTx(ctx context.Context, arg CashOperationTxParams) (CashOperationTxResult, error)
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
 	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	AdminUpdateUserTx(ctx context.Context, arg AdminUpdateUserTxParams) (AdminUpdateUserTxResult, error)
+	ConfirmTotpTx(ctx context.Context, arg ConfirmTotpTxParams) (ConfirmTotpTxResult, error)
+	SetRolePolicyTx(ctx context.Context, arg SetRolePolicyTxParams) (SetRolePolicyTxResult, error)
+	ExportPartition(ctx context.Context, partition string, w io.Writer) error
+	DropPartition(ctx context.Context, parent string, partition string) error
 }
 
 type SQLStore struct {
 	*Queries
-	connPool *pgxpool.Pool
+	connPool      *pgxpool.Pool
+	replica       *Queries
+	replicaPool   *pgxpool.Pool
+	maxReplicaLag time.Duration
+	replicaLag    atomic.Int64 // last measured lag in nanoseconds
 }
 
 func NewStore(connPool *pgxpool.Pool) Store {
@@ -3,21 +3,22 @@ package db
 import (
 	"context"
 	"fmt"
+	"github.com/mariobasic/simplebank/util"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
 
 func TestStore_TransferTx(t *testing.T) {
-	account1 := createRandomAccount(t)[0]
+	amount := int64(10)
+	n := 10
+
+	account1 := fundAccount(t, createRandomAccount(t)[0], int64(n)*amount)
 	account2 := createRandomAccount(t)[0]
 	fmt.Println(">>before:", account1.Balance, account2.Balance)
 
-	amount := int64(10)
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
 
-	n := 10
-
 	for range n {
 		go func() {
 			result, err := testStore.TransferTx(context.Background(), TransferTxParams{
@@ -106,15 +107,15 @@ func TestStore_TransferTx(t *testing.T) {
 }
 
 func TestStore_TransferTxDeadlock(t *testing.T) {
-	account1 := createRandomAccount(t)[0]
-	account2 := createRandomAccount(t)[0]
+	amount := int64(10)
+	n := 10
+
+	account1 := fundAccount(t, createRandomAccount(t)[0], int64(n)*amount)
+	account2 := fundAccount(t, createRandomAccount(t)[0], int64(n)*amount)
 	fmt.Println(">>before:", account1.Balance, account2.Balance)
 
-	amount := int64(10)
 	errs := make(chan error)
 
-	n := 10
-
 	for i := range n {
 		fromAccountID := account1.ID
 		toAccountID := account2.ID
@@ -148,3 +149,57 @@ func TestStore_TransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestStore_TransferTxInsufficientFunds(t *testing.T) {
+	amount := int64(10)
+	n := 10
+	funded := 4
+
+	user := createRandomUser(t)
+	account1, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  int64(funded) * amount,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	account2 := createRandomAccount(t)[0]
+
+	errs := make(chan error)
+	for range n {
+		go func() {
+			_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	// every transfer saw enough money before it started, only the funded ones go through
+	succeeded := 0
+	for range n {
+		err := <-errs
+		if err != nil {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+			continue
+		}
+		succeeded++
+	}
+	require.Equal(t, funded, succeeded)
+
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, updatedAccount1.Balance)
+
+	updatedAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+int64(funded)*amount, updatedAccount2.Balance)
+}
+
+// fundAccount adds money so the account can send it regardless of its random balance
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	account, err := testStore.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: amount})
+	require.NoError(t, err)
+	return account
+}
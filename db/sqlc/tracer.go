@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+)
+
+const tracerName = "github.com/mariobasic/simplebank/db"
+
+var tracer = otel.Tracer(tracerName)
+
+// queryName matches the name sqlc puts in the first line of every query
+var queryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+// QueryTracer traces every query of a pool, set it on the pool config before connecting
+type QueryTracer struct{}
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := "query"
+	if match := queryName.FindStringSubmatch(data.SQL); match != nil {
+		name = match[1]
+	}
+
+	ctx, _ = tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(name), semconv.DBQueryText(data.SQL)),
+	)
+	return ctx
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomTransferChallenge(t *testing.T, from, to Account) TransferChallenge {
+	arg := CreateTransferChallengeParams{
+		Username:      from.Owner,
+		Email:         util.RandomEmail(),
+		SecretCode:    "123456",
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      from.Currency,
+		ExpiredAt:     time.Now().Add(time.Minute),
+	}
+
+	challenge, err := testStore.CreateTransferChallenge(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, challenge.ID)
+	require.Equal(t, arg.Username, challenge.Username)
+	require.Equal(t, arg.SecretCode, challenge.SecretCode)
+	require.Equal(t, arg.Amount, challenge.Amount)
+	require.Zero(t, challenge.Attempts)
+	require.False(t, challenge.IsUsed)
+
+	return challenge
+}
+
+func TestQueries_AttemptTransferChallenge(t *testing.T) {
+	from := createRandomAccount(t)[0]
+	to := createRandomAccount(t)[0]
+	challenge := createRandomTransferChallenge(t, from, to)
+
+	// another user can't attempt the challenge
+	_, err := testStore.AttemptTransferChallenge(context.Background(), AttemptTransferChallengeParams{
+		ID:          challenge.ID,
+		Username:    to.Owner,
+		MaxAttempts: 2,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	arg := AttemptTransferChallengeParams{ID: challenge.ID, Username: from.Owner, MaxAttempts: 2}
+	for attempts := int32(1); attempts <= 2; attempts++ {
+		attempted, err := testStore.AttemptTransferChallenge(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, attempts, attempted.Attempts)
+	}
+
+	_, err = testStore.AttemptTransferChallenge(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestStore_TransferTxChallenge(t *testing.T) {
+	from := fundAccount(t, createRandomAccount(t)[0], 10)
+	to := createRandomAccount(t)[0]
+	challenge := createRandomTransferChallenge(t, from, to)
+
+	arg := TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10, ChallengeID: challenge.ID}
+	result, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-10, result.FromAccount.Balance)
+
+	used, err := testStore.GetTransferChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.True(t, used.IsUsed)
+
+	// the challenge confirms a single transfer, the second one is rolled back
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrChallengeUsed)
+
+	account, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-10, account.Balance)
+}
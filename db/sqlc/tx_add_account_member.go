@@ -0,0 +1,28 @@
+package db
+
+import "context"
+
+type AddAccountMemberTxParams struct {
+	CreateAccountMemberParams
+	AfterCreate func(member AccountMember) error
+}
+
+type AddAccountMemberTxResult struct {
+	Member AccountMember
+}
+
+func (s *SQLStore) AddAccountMemberTx(ctx context.Context, arg AddAccountMemberTxParams) (AddAccountMemberTxResult, error) {
+	var result AddAccountMemberTxResult
+	err := s.execTx(ctx, "AddAccountMemberTx", func(q *Queries) error {
+		var err error
+
+		result.Member, err = q.CreateAccountMember(ctx, arg.CreateAccountMemberParams)
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterCreate(result.Member)
+	})
+
+	return result, err
+}
@@ -0,0 +1,48 @@
+package db
+
+import "context"
+
+// AdminUpdateUserTxParams describe a change a banker makes to another user, it is recorded in the audit log
+type AdminUpdateUserTxParams struct {
+	UpdateUserParams
+	Banker  string
+	Action  string
+	Details string
+	// BlockSessions logs the user out of every device, so the change applies to tokens issued before it
+	BlockSessions bool
+}
+
+type AdminUpdateUserTxResult struct {
+	User            User
+	AuditLog        AuditLog
+	BlockedSessions int64
+}
+
+func (s *SQLStore) AdminUpdateUserTx(ctx context.Context, arg AdminUpdateUserTxParams) (AdminUpdateUserTxResult, error) {
+	var result AdminUpdateUserTxResult
+	err := s.execTx(ctx, "AdminUpdateUserTx", func(q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.BlockSessions {
+			result.BlockedSessions, err = q.BlockUserSessions(ctx, result.User.Username)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.AuditLog, err = q.CreateAuditLog(ctx, CreateAuditLogParams{
+			Banker:  arg.Banker,
+			Action:  arg.Action,
+			Target:  result.User.Username,
+			Details: arg.Details,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_AdminUpdateUserTx(t *testing.T) {
+	banker := createRandomUser(t)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+
+	result, err := testStore.AdminUpdateUserTx(context.Background(), AdminUpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: user.Username,
+			IsLocked: pgtype.Bool{Bool: true, Valid: true},
+		},
+		Banker:        banker.Username,
+		Action:        util.AuditLockUser,
+		Details:       "fraud investigation",
+		BlockSessions: true,
+	})
+	require.NoError(t, err)
+	require.True(t, result.User.IsLocked)
+	require.Equal(t, int64(1), result.BlockedSessions)
+
+	require.NotZero(t, result.AuditLog.ID)
+	require.Equal(t, banker.Username, result.AuditLog.Banker)
+	require.Equal(t, util.AuditLockUser, result.AuditLog.Action)
+	require.Equal(t, user.Username, result.AuditLog.Target)
+	require.Equal(t, "fraud investigation", result.AuditLog.Details)
+
+	blocked, err := testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	logs, err := testStore.ListAuditLogs(context.Background(), ListAuditLogsParams{Target: user.Username, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, result.AuditLog.ID, logs[0].ID)
+}
+
+func TestStore_AdminUpdateUserTxUnknownUser(t *testing.T) {
+	banker := createRandomUser(t)
+
+	_, err := testStore.AdminUpdateUserTx(context.Background(), AdminUpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: util.RandomOwner(),
+			IsLocked: pgtype.Bool{Bool: true, Valid: true},
+		},
+		Banker: banker.Username,
+		Action: util.AuditLockUser,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+)
+
+type CashOperationTxParams struct {
+	Type      string `json:"type"`
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+	Memo      string `json:"memo"`
+	Banker    string `json:"banker"`
+}
+
+type CashOperationTxResult struct {
+	CashOperation CashOperation    `json:"cash_operation"`
+	Account       Account          `json:"account"`
+	Transfer      TransferTxResult `json:"transfer"`
+}
+
+// CashOperationTx moves money between the customer account and the bank cash account of the same currency,
+// and records who performed the operation. Withdrawals that would overdraw the account are rolled back.
+func (s *SQLStore) CashOperationTx(ctx context.Context, arg CashOperationTxParams) (CashOperationTxResult, error) {
+	var result CashOperationTxResult
+	err := s.execTx(ctx, "CashOperationTx", func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		cashAccount, err := q.GetOwnerAccount(ctx, GetOwnerAccountParams{
+			Owner:    util.BankCashUsername,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		transferArg := TransferTxParams{
+			FromAccountID: cashAccount.ID,
+			ToAccountID:   account.ID,
+			Amount:        arg.Amount,
+		}
+		if arg.Type == util.WithdrawalOperation {
+			transferArg.FromAccountID, transferArg.ToAccountID = account.ID, cashAccount.ID
+		}
+
+		result.Transfer, err = transferMoney(ctx, q, transferArg)
+		if err != nil {
+			return err
+		}
+
+		result.Account = result.Transfer.ToAccount
+		if arg.Type == util.WithdrawalOperation {
+			result.Account = result.Transfer.FromAccount
+			if result.Account.Balance < 0 {
+				return ErrInsufficientFunds
+			}
+		}
+
+		result.CashOperation, err = q.CreateCashOperation(ctx, CreateCashOperationParams{
+			Type:          arg.Type,
+			AccountID:     account.ID,
+			CashAccountID: cashAccount.ID,
+			TransferID:    result.Transfer.Transfer.ID,
+			Amount:        arg.Amount,
+			Reference:     arg.Reference,
+			Memo:          arg.Memo,
+			Banker:        arg.Banker,
+		})
+
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+// ConfirmTotpTxParams enable a pending TOTP secret with the first code of the authenticator app
+type ConfirmTotpTxParams struct {
+	Username string
+	// Step is the time step of the code, it must be later than the last accepted one
+	Step int64
+	// RecoveryCodeHashes replace the recovery codes of the user
+	RecoveryCodeHashes []string
+	// SessionID is the session that enrolled, it counts as verified from now on
+	SessionID uuid.UUID
+}
+
+type ConfirmTotpTxResult struct {
+	RecoveryCodes []RecoveryCode
+}
+
+func (s *SQLStore) ConfirmTotpTx(ctx context.Context, arg ConfirmTotpTxParams) (ConfirmTotpTxResult, error) {
+	var result ConfirmTotpTxResult
+	err := s.execTx(ctx, "ConfirmTotpTx", func(q *Queries) error {
+		rows, err := q.UseTotpStep(ctx, UseTotpStepParams{Step: arg.Step, Username: arg.Username})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			// the code was replayed or the secret is gone
+			return ErrRecordNotFound
+		}
+
+		if err = q.DeleteRecoveryCodes(ctx, arg.Username); err != nil {
+			return err
+		}
+		result.RecoveryCodes = make([]RecoveryCode, 0, len(arg.RecoveryCodeHashes))
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			code, err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{Username: arg.Username, CodeHash: codeHash})
+			if err != nil {
+				return err
+			}
+			result.RecoveryCodes = append(result.RecoveryCodes, code)
+		}
+
+		return q.MarkSessionMfaVerified(ctx, arg.SessionID)
+	})
+
+	return result, err
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+)
+
+type CreateAccountTxResult struct {
+	Account Account
+	Member  AccountMember
+}
+
+// CreateAccountTx creates the account and registers its owner as the first account member
+func (s *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (CreateAccountTxResult, error) {
+	var result CreateAccountTxResult
+	err := s.execTx(ctx, "CreateAccountTx", func(q *Queries) error {
+		var err error
+
+		result.Account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		result.Member, err = q.CreateAccountMember(ctx, CreateAccountMemberParams{
+			AccountID: result.Account.ID,
+			Username:  result.Account.Owner,
+			Role:      util.AccountOwnerRole,
+			InvitedBy: result.Account.Owner,
+		})
+
+		return err
+	})
+
+	return result, err
+}
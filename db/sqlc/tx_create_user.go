@@ -13,7 +13,7 @@ type CreateUserTxResult struct {
 
 func (s *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
 	var result CreateUserTxResult
-	err := s.execTx(ctx, func(q *Queries) error {
+	err := s.execTx(ctx, "CreateUserTx", func(q *Queries) error {
 		var err error
 
 		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
@@ -0,0 +1,37 @@
+package db
+
+import "context"
+
+// SetRolePolicyTxParams describe a change a banker makes to the policy of a role, it is recorded in the audit log
+type SetRolePolicyTxParams struct {
+	UpsertRolePolicyParams
+	Action  string
+	Details string
+}
+
+type SetRolePolicyTxResult struct {
+	RolePolicy RolePolicy
+	AuditLog   AuditLog
+}
+
+func (s *SQLStore) SetRolePolicyTx(ctx context.Context, arg SetRolePolicyTxParams) (SetRolePolicyTxResult, error) {
+	var result SetRolePolicyTxResult
+	err := s.execTx(ctx, "SetRolePolicyTx", func(q *Queries) error {
+		var err error
+
+		result.RolePolicy, err = q.UpsertRolePolicy(ctx, arg.UpsertRolePolicyParams)
+		if err != nil {
+			return err
+		}
+
+		result.AuditLog, err = q.CreateAuditLog(ctx, CreateAuditLogParams{
+			Banker:  arg.UpdatedBy,
+			Action:  arg.Action,
+			Target:  result.RolePolicy.Role,
+			Details: arg.Details,
+		})
+		return err
+	})
+
+	return result, err
+}
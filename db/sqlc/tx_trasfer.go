@@ -6,6 +6,8 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// ChallengeID is the step-up challenge that confirmed the transfer, it is used up with the transfer
+	ChallengeID int64 `json:"challenge_id"`
 }
 
 type TransferTxResult struct {
@@ -16,47 +18,71 @@ type TransferTxResult struct {
 	ToEntry     Entry    `json:"to_entry"`
 }
 
+// TransferTx moves money between two accounts, transfers that would overdraw the sending account are rolled back
 func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
-	err := s.execTx(ctx, func(q *Queries) error {
-		var err error
-
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
-		if err != nil {
-			return err
+	err := s.execTx(ctx, "TransferTx", func(q *Queries) error {
+		if arg.ChallengeID != 0 {
+			rows, err := q.UseTransferChallenge(ctx, arg.ChallengeID)
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				return ErrChallengeUsed
+			}
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+		var err error
+		result, err = transferMoney(ctx, q, arg)
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
-		if err != nil {
-			return err
+		// the balance read before the transaction may be stale, the locked row is the one that counts
+		if result.FromAccount.Balance < 0 {
+			return ErrInsufficientFunds
 		}
+		return nil
+	})
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-		}
-		if err != nil {
-			return err
-		}
+	return result, err
+}
 
-		return nil
+// transferMoney records the transfer with its two entries and updates both balances using the given queries
+func transferMoney(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.FromAccountID,
+		Amount:    -arg.Amount,
 	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.ToAccountID,
+		Amount:    arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+	}
 
 	return result, err
 }
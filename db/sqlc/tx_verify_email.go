@@ -17,7 +17,7 @@ type VerifyEmailTxResult struct {
 
 func (s *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
 	var result VerifyEmailTxResult
-	err := s.execTx(ctx, func(q *Queries) error {
+	err := s.execTx(ctx, "VerifyEmailTx", func(q *Queries) error {
 		var err error
 
 		result.VerifyEmail, err = q.UpdateVerifyEmail(ctx, UpdateVerifyEmailParams{ID: arg.EmailId, SecretCode: arg.SecretCode})
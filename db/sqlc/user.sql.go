@@ -14,7 +14,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, hashed_password, full_name, email)
 VALUES ($1, $2, $3, $4)
-RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role
+RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, is_locked, must_reset_password, version
 `
 
 type CreateUserParams struct {
@@ -41,12 +41,15 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.IsLocked,
+		&i.MustResetPassword,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role FROM users
+SELECT username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, is_locked, must_reset_password, version FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -62,10 +65,62 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.PasswordChangedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.IsLocked,
+		&i.MustResetPassword,
+		&i.Version,
 	)
 	return i, err
 }
 
+const listUsers = `-- name: ListUsers :many
+SELECT username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, is_locked, must_reset_password, version FROM users
+WHERE $1::text = ''
+   OR username ILIKE '%' || $1 || '%' ESCAPE '\'
+   OR full_name ILIKE '%' || $1 || '%' ESCAPE '\'
+   OR email ILIKE '%' || $1 || '%' ESCAPE '\'
+ORDER BY username
+LIMIT $2
+OFFSET $3
+`
+
+type ListUsersParams struct {
+	Search string `json:"search"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers, arg.Search, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.CreatedAt,
+			&i.PasswordChangedAt,
+			&i.IsEmailVerified,
+			&i.Role,
+			&i.IsLocked,
+			&i.MustResetPassword,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
@@ -73,10 +128,17 @@ SET
     password_changed_at = COALESCE($2, password_changed_at),
     full_name = COALESCE($3, full_name),
     email = COALESCE($4, email),
-    is_email_verified = COALESCE($5, is_email_verified)
+    is_email_verified = COALESCE($5, is_email_verified),
+    role = COALESCE($6, role),
+    is_locked = COALESCE($7, is_locked),
+    -- setting a new password fulfils a forced reset
+    must_reset_password = COALESCE($8, $1 IS NULL AND must_reset_password),
+    version = version + 1
 WHERE
-    username = $6
-RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role
+    username = $9
+    -- a stale version matches no row
+    AND version = COALESCE($10, version)
+RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, is_locked, must_reset_password, version
 `
 
 type UpdateUserParams struct {
@@ -85,7 +147,11 @@ type UpdateUserParams struct {
 	FullName          pgtype.Text        `json:"full_name"`
 	Email             pgtype.Text        `json:"email"`
 	IsEmailVerified   pgtype.Bool        `json:"is_email_verified"`
+	Role              pgtype.Text        `json:"role"`
+	IsLocked          pgtype.Bool        `json:"is_locked"`
+	MustResetPassword pgtype.Bool        `json:"must_reset_password"`
 	Username          string             `json:"username"`
+	Version           pgtype.Int8        `json:"version"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -95,7 +161,11 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.FullName,
 		arg.Email,
 		arg.IsEmailVerified,
+		arg.Role,
+		arg.IsLocked,
+		arg.MustResetPassword,
 		arg.Username,
+		arg.Version,
 	)
 	var i User
 	err := row.Scan(
@@ -107,6 +177,9 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.IsLocked,
+		&i.MustResetPassword,
+		&i.Version,
 	)
 	return i, err
 }
@@ -168,3 +168,82 @@ func TestQueries_UpdateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestQueries_UpdateUserClearsPasswordReset(t *testing.T) {
+	user := createRandomUser(t)
+
+	got, err := testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username:          user.Username,
+		MustResetPassword: pgtype.Bool{Bool: true, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, got.MustResetPassword)
+
+	// other changes keep the pending reset
+	got, err = testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		FullName: pgtype.Text{String: util.RandomOwner(), Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, got.MustResetPassword)
+
+	password, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+	got, err = testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username:       user.Username,
+		HashedPassword: pgtype.Text{String: password, Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, got.MustResetPassword)
+}
+
+func TestQueries_UpdateUserVersion(t *testing.T) {
+	user := createRandomUser(t)
+	require.Equal(t, int64(1), user.Version)
+
+	got, err := testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		FullName: pgtype.Text{String: util.RandomOwner(), Valid: true},
+		Version:  pgtype.Int8{Int64: user.Version, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Version+1, got.Version)
+
+	// the first version is stale now
+	_, err = testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		Email:    pgtype.Text{String: util.RandomEmail(), Valid: true},
+		Version:  pgtype.Int8{Int64: user.Version, Valid: true},
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	// updates without a version always apply
+	got, err = testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		Email:    pgtype.Text{String: util.RandomEmail(), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Version+2, got.Version)
+}
+
+func TestQueries_ListUsers(t *testing.T) {
+	user := createRandomUser(t)
+
+	users, err := testStore.ListUsers(context.Background(), ListUsersParams{Search: user.Email, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, user.Username, users[0].Username)
+
+	users, err = testStore.ListUsers(context.Background(), ListUsersParams{Search: user.Username[1:4], Limit: 5})
+	require.NoError(t, err)
+	require.NotEmpty(t, users)
+
+	// wildcards of the search match themselves
+	users, err = testStore.ListUsers(context.Background(), ListUsersParams{Search: EscapeLike("%"), Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	users, err = testStore.ListUsers(context.Background(), ListUsersParams{Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, users, 5)
+}
@@ -9,8 +9,11 @@ CREATE TABLE "users" (
   "full_name" varchar NOT NULL,
   "email" varchar UNIQUE NOT NULL,
   "is_email_verified" bool NOT NULL DEFAULT false,
+  "is_locked" bool NOT NULL DEFAULT false,
+  "must_reset_password" bool NOT NULL DEFAULT false,
   "created_at" timestamptz NOT NULL DEFAULT (now()),
-  "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+  "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "version" bigint NOT NULL DEFAULT 1
 );
 
 CREATE TABLE "verify_emails" (
@@ -28,40 +31,132 @@ CREATE TABLE "accounts" (
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
   "currency" varchar NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "parent_id" bigint,
+  "name" varchar NOT NULL DEFAULT '',
+  "goal_amount" bigint NOT NULL DEFAULT 0,
+  "version" bigint NOT NULL DEFAULT 1
+);
+
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "invited_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
 );
 
 CREATE TABLE "entries" (
-  "id" bigserial PRIMARY KEY,
+  "id" bigserial NOT NULL,
   "account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id", "created_at")
 );
 
 CREATE TABLE "transfers" (
-  "id" bigserial PRIMARY KEY,
+  "id" bigserial NOT NULL,
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id", "created_at")
+);
+
+CREATE TABLE "cash_operations" (
+  "id" bigserial PRIMARY KEY,
+  "type" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "cash_account_id" bigint NOT NULL,
+  "transfer_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reference" varchar UNIQUE NOT NULL,
+  "memo" varchar NOT NULL,
+  "banker" varchar NOT NULL,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
 CREATE TABLE "sessions" (
   "id" uuid PRIMARY KEY,
   "username" varchar NOT NULL,
-  "refresh_token" varchar NOT NULL,
+  "refresh_token_hash" varchar NOT NULL,
   "user_agent" varchar NOT NULL,
   "client_ip" varchar NOT NULL,
   "is_blocked" boolean NOT NULL DEFAULT false,
   "expired_at" timestamptz NOT NULL,
+  "last_used_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "is_mfa_verified" boolean NOT NULL DEFAULT false
+);
+
+CREATE TABLE "audit_logs" (
+  "id" bigserial PRIMARY KEY,
+  "banker" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "target" varchar NOT NULL,
+  "details" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "totp_secrets" (
+  "username" varchar PRIMARY KEY,
+  "secret" varchar NOT NULL,
+  "is_confirmed" boolean NOT NULL DEFAULT false,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "confirm_attempts" integer NOT NULL DEFAULT 0
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
+CREATE TABLE "mfa_challenges" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL
+);
+
+CREATE TABLE "role_policies" (
+  "role" varchar PRIMARY KEY,
+  "mfa_required" boolean NOT NULL DEFAULT false,
+  "updated_by" varchar NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_challenges" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "secret_code" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "is_used" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL
+);
+
 CREATE INDEX ON "accounts" ("owner");
 
 CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
 
-CREATE INDEX ON "entries" ("account_id");
+CREATE UNIQUE INDEX ON "accounts" ("parent_id", "name");
+
+CREATE INDEX ON "account_members" ("username");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
 
 CREATE INDEX ON "transfers" ("from_account_id");
 
@@ -69,18 +164,92 @@ CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "cash_operations" ("account_id");
+
+CREATE INDEX ON "cash_operations" ("banker");
+
+CREATE INDEX ON "sessions" ("username");
+
+CREATE INDEX ON "audit_logs" ("banker");
+
+CREATE INDEX ON "audit_logs" ("target");
+
+CREATE INDEX ON "recovery_codes" ("username");
+
+COMMENT ON TABLE "entries" IS 'partitioned by range on created_at, one partition per month';
+
+COMMENT ON TABLE "transfers" IS 'partitioned by range on created_at, one partition per month';
+
+COMMENT ON COLUMN "users"."version" IS 'incremented by every update, guards concurrent updates';
+
+COMMENT ON COLUMN "accounts"."parent_id" IS 'set for pockets, references the main account';
+
+COMMENT ON COLUMN "accounts"."goal_amount" IS '0 means no goal';
+
+COMMENT ON COLUMN "accounts"."version" IS 'incremented by every update, guards concurrent updates';
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, signer or viewer';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
 COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "cash_operations"."type" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "cash_operations"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "sessions"."refresh_token_hash" IS 'sha256 of the current refresh token, replaced on every renewal';
+
+COMMENT ON COLUMN "audit_logs"."target" IS 'username the action was applied to, empty for searches';
+
+COMMENT ON COLUMN "totp_secrets"."last_used_step" IS 'time step of the last accepted code, a code can''t be replayed';
+
+COMMENT ON COLUMN "totp_secrets"."confirm_attempts" IS 'codes tried to confirm the pending secret, enrolling again resets them';
+
+COMMENT ON COLUMN "recovery_codes"."code_hash" IS 'sha256 of the recovery code';
+
+COMMENT ON COLUMN "mfa_challenges"."token_hash" IS 'sha256 of the challenge token returned by the login';
+
+COMMENT ON COLUMN "transfer_challenges"."secret_code" IS 'emailed 6-digit code, it only confirms the transfer it was issued for';
+
 ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
 
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
+ALTER TABLE "accounts" ADD FOREIGN KEY ("parent_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("cash_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_operations" ADD FOREIGN KEY ("banker") REFERENCES "users" ("username");
+
 ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "audit_logs" ADD FOREIGN KEY ("banker") REFERENCES "users" ("username");
+
+ALTER TABLE "totp_secrets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "role_policies" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_challenges" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_challenges" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
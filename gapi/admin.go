@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// adminUserAccountsLimit caps the accounts shown with a user in the admin view
+const adminUserAccountsLimit = 100
+
+// adminUpdateUser applies a banker's change to a user and records it in the audit log in the same tx
+func (s *Server) adminUpdateUser(ctx context.Context, arg db.AdminUpdateUserTxParams) (db.AdminUpdateUserTxResult, error) {
+	if arg.Banker == arg.Username {
+		return db.AdminUpdateUserTxResult{}, status.Error(codes.FailedPrecondition, "bankers cannot apply admin actions to their own user")
+	}
+
+	result, err := s.store.AdminUpdateUserTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return result, status.Error(codes.NotFound, "user not found")
+		}
+		return result, status.Errorf(codes.Internal, "failed to update user: %s", err)
+	}
+
+	return result, nil
+}
+
+// auditView records a banker looking at user data, the request fails if it can't be recorded
+func (s *Server) auditView(ctx context.Context, banker, action, target, details string) error {
+	_, err := s.store.CreateAuditLog(ctx, db.CreateAuditLogParams{
+		Banker:  banker,
+		Action:  action,
+		Target:  target,
+		Details: details,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to create audit log: %s", err)
+	}
+	return nil
+}
+
+func validateAdminUsername(username string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(username); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+	return violations
+}
@@ -0,0 +1,177 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"slices"
+)
+
+// accessPolicy is who may call a gRPC method
+type accessPolicy struct {
+	public bool
+	roles  []string
+	// allowPendingReset lets in users a banker forced to reset their password,
+	// only the RPCs they need to set a new one have it
+	allowPendingReset bool
+	// allowPendingMfa lets in sessions of a role that requires a second factor before the user enrolled,
+	// only the RPCs they need to enroll have it
+	allowPendingMfa bool
+	// services lets in the services of config.TLS.Services with their client certificate, without an access token
+	services bool
+}
+
+var (
+	publicAccess       = accessPolicy{public: true}
+	userAccess         = accessPolicy{roles: []string{util.BankerRole, util.DepositorRole}}
+	pendingResetAccess = accessPolicy{roles: []string{util.BankerRole, util.DepositorRole}, allowPendingReset: true}
+	pendingMfaAccess   = accessPolicy{roles: []string{util.BankerRole, util.DepositorRole}, allowPendingMfa: true}
+	bankerAccess       = accessPolicy{roles: []string{util.BankerRole}}
+	// userOrServiceAccess also lets in trusted services, e.g. to read accounts for reconciliation
+	userOrServiceAccess = accessPolicy{roles: []string{util.BankerRole, util.DepositorRole}, services: true}
+	// logoutAccess lets in every session whatever it waits for, a user can always log out
+	logoutAccess = accessPolicy{roles: []string{util.BankerRole, util.DepositorRole}, allowPendingReset: true, allowPendingMfa: true}
+)
+
+// accessPolicies lists the policy of every method served, a method missing from it is denied
+var accessPolicies = map[string]accessPolicy{
+	pb.SimpleBank_CreateUser_FullMethodName:            publicAccess,
+	pb.SimpleBank_LoginUser_FullMethodName:             publicAccess,
+	pb.SimpleBank_VerifyEmail_FullMethodName:           publicAccess,
+	pb.SimpleBank_VerifyLoginMfa_FullMethodName:        publicAccess,
+	pb.SimpleBank_RenewAccessToken_FullMethodName:      publicAccess,
+	pb.SimpleBank_UpdateUser_FullMethodName:            pendingResetAccess,
+	pb.SimpleBank_Logout_FullMethodName:                logoutAccess,
+	pb.SimpleBank_EnrollTotp_FullMethodName:            pendingMfaAccess,
+	pb.SimpleBank_ConfirmTotp_FullMethodName:           pendingMfaAccess,
+	pb.SimpleBank_LogoutAll_FullMethodName:             userAccess,
+	pb.SimpleBank_CreateAccount_FullMethodName:         userAccess,
+	pb.SimpleBank_GetAccount_FullMethodName:            userOrServiceAccess,
+	pb.SimpleBank_ListAccounts_FullMethodName:          userAccess,
+	pb.SimpleBank_ListAccountMembers_FullMethodName:    userAccess,
+	pb.SimpleBank_AddAccountMember_FullMethodName:      userAccess,
+	pb.SimpleBank_RemoveAccountMember_FullMethodName:   userAccess,
+	pb.SimpleBank_CreatePocket_FullMethodName:          userAccess,
+	pb.SimpleBank_ListPockets_FullMethodName:           userAccess,
+	pb.SimpleBank_CreateTransfer_FullMethodName:        userAccess,
+	pb.SimpleBank_ListSessions_FullMethodName:          userAccess,
+	pb.SimpleBank_RevokeSession_FullMethodName:         userAccess,
+	pb.SimpleBank_Deposit_FullMethodName:               bankerAccess,
+	pb.SimpleBank_Withdraw_FullMethodName:              bankerAccess,
+	pb.SimpleBank_ListUsers_FullMethodName:             bankerAccess,
+	pb.SimpleBank_GetUser_FullMethodName:               bankerAccess,
+	pb.SimpleBank_LockUser_FullMethodName:              bankerAccess,
+	pb.SimpleBank_UnlockUser_FullMethodName:            bankerAccess,
+	pb.SimpleBank_ChangeUserRole_FullMethodName:        bankerAccess,
+	pb.SimpleBank_ForcePasswordReset_FullMethodName:    bankerAccess,
+	pb.SimpleBank_SetRoleMfaRequirement_FullMethodName: bankerAccess,
+
+	healthpb.Health_Check_FullMethodName: publicAccess,
+	healthpb.Health_Watch_FullMethodName: publicAccess,
+
+	reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName:      publicAccess,
+	reflectionv1alpha.ServerReflection_ServerReflectionInfo_FullMethodName: publicAccess,
+}
+
+type authPayloadKey struct{}
+
+// authPayloadFromContext returns the payload of the access token the auth interceptor verified
+func authPayloadFromContext(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(authPayloadKey{}).(*token.Payload)
+	if !ok {
+		return nil, fmt.Errorf("missing access token payload")
+	}
+	return payload, nil
+}
+
+// authCallerFromContext returns the token payload of a user, or the identity of the trusted service
+// calling a method open to services without an access token
+func authCallerFromContext(ctx context.Context) (*token.Payload, string, error) {
+	if payload, err := authPayloadFromContext(ctx); err == nil {
+		return payload, "", nil
+	}
+	if identity, ok := serviceIdentityFromContext(ctx); ok {
+		return nil, identity, nil
+	}
+	return nil, "", fmt.Errorf("missing access token payload or service identity")
+}
+
+// authenticate enforces the access policy of the method, the returned context carries
+// the token payload for authenticated methods or the identity of a trusted service
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	policy, ok := accessPolicies[method]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "no access policy for method %s", method)
+	}
+
+	if policy.public {
+		return ctx, nil
+	}
+
+	if identity := serviceIdentity(ctx); policy.services && identity != "" && !hasAuthorizationHeader(ctx) {
+		if !slices.Contains(s.config.TLS.Services, identity) {
+			return nil, status.Errorf(codes.PermissionDenied, "service %s is not trusted", identity)
+		}
+		return context.WithValue(ctx, serviceIdentityKey{}, identity), nil
+	}
+
+	payload, err := s.authorize(ctx, policy)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	// the caller is known by now, a role the method doesn't allow is denied rather than unauthenticated
+	if !hasPermission(payload.Role, policy.roles) {
+		return nil, status.Errorf(codes.PermissionDenied, "role %s is not allowed to call %s", payload.Role, method)
+	}
+
+	return context.WithValue(ctx, authPayloadKey{}, payload), nil
+}
+
+// AuthInterceptor authenticates unary calls against the access policy of their method
+func (s *Server) AuthInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	ctx, err = s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamAuthInterceptor does the same as AuthInterceptor for streaming calls
+func (s *Server) StreamAuthInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := s.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// wrappedServerStream replaces the context of a stream for the handlers down the chain
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedServerStream) Context() context.Context {
+	return s.ctx
+}
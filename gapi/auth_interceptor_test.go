@@ -0,0 +1,214 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestAccessPolicies(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	pb.RegisterSimpleBankServer(grpcServer, &pb.UnimplementedSimpleBankServer{})
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+	reflection.Register(grpcServer)
+
+	served := map[string]bool{}
+	for service, info := range grpcServer.GetServiceInfo() {
+		for _, method := range info.Methods {
+			fullMethod := "/" + service + "/" + method.Name
+			served[fullMethod] = true
+			_, ok := accessPolicies[fullMethod]
+			require.Truef(t, ok, "method %s has no access policy", fullMethod)
+		}
+	}
+
+	for method := range accessPolicies {
+		require.Truef(t, served[method], "access policy for unknown method %s", method)
+	}
+}
+
+func TestServer_AuthInterceptor(t *testing.T) {
+	user, _ := randomUser(t)
+
+	// mfaSession is a session of a role that requires a second factor
+	mfaSession := func(t *testing.T, tokenMaker token.Maker, verified bool) context.Context {
+		session := db.Session{ID: uuid.New(), Username: user.Username, IsMfaVerified: verified, LastUsedAt: time.Now()}
+		return newContextWithAuthSession(t, tokenMaker, db.GetAuthSessionRow{Session: session, MfaRequired: true}, user.Role, time.Minute)
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		buildContext func(t *testing.T, tokenMaker token.Maker) context.Context
+		check        func(t *testing.T, payload *token.Payload, called bool, err error)
+	}{
+		{
+			name:   "Authenticated",
+			method: pb.SimpleBank_ListAccounts_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.NoError(t, err)
+				require.True(t, called)
+				require.NotNil(t, payload)
+				require.Equal(t, user.Username, payload.Username)
+			},
+		},
+		{
+			name:   "Public",
+			method: pb.SimpleBank_LoginUser_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.NoError(t, err)
+				require.True(t, called)
+				require.Nil(t, payload)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: pb.SimpleBank_ListAccounts_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.False(t, called)
+			},
+		},
+		{
+			name:   "RefreshToken",
+			method: pb.SimpleBank_ListAccounts_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				// the session is valid, but its refresh token can't be used as an access token
+				session := db.Session{ID: uuid.New(), Username: user.Username, LastUsedAt: time.Now()}
+				testSessions.Store(session.ID, db.GetAuthSessionRow{Session: session})
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, session.ID, token.TokenTypeRefresh, time.Hour)
+				require.NoError(t, err)
+				return contextWithAccessToken(refreshToken)
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.False(t, called)
+			},
+		},
+		{
+			name:   "RoleNotAllowed",
+			method: pb.SimpleBank_ListUsers_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				require.False(t, called)
+			},
+		},
+		{
+			name:   "MfaPending",
+			method: pb.SimpleBank_ListAccounts_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return mfaSession(t, tokenMaker, false)
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.False(t, called)
+			},
+		},
+		{
+			name:   "MfaPendingEnroll",
+			method: pb.SimpleBank_EnrollTotp_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return mfaSession(t, tokenMaker, false)
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.NoError(t, err)
+				require.True(t, called)
+			},
+		},
+		{
+			name:   "MfaVerified",
+			method: pb.SimpleBank_ListAccounts_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return mfaSession(t, tokenMaker, true)
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.NoError(t, err)
+				require.True(t, called)
+			},
+		},
+		{
+			name:   "UnknownMethod",
+			method: "/pb.SimpleBank/Unknown",
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.BankerRole, time.Minute)
+			},
+			check: func(t *testing.T, payload *token.Payload, called bool, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				require.False(t, called)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, stream := range []bool{false, true} {
+				ctrl := gomock.NewController(t)
+				store := mockdb.NewMockStore(ctrl)
+				stubSessions(store)
+
+				server := NewTestServer(t, store, nil)
+				ctx := tt.buildContext(t, server.tokenMaker)
+
+				var payload *token.Payload
+				called := false
+				var err error
+				if stream {
+					info := &grpc.StreamServerInfo{FullMethod: tt.method}
+					err = server.StreamAuthInterceptor(nil, &testServerStream{ctx: ctx}, info, func(_ any, ss grpc.ServerStream) error {
+						called = true
+						payload, _ = authPayloadFromContext(ss.Context())
+						return nil
+					})
+				} else {
+					info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+					_, err = server.AuthInterceptor(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+						called = true
+						payload, _ = authPayloadFromContext(ctx)
+						return nil, nil
+					})
+				}
+				tt.check(t, payload, called, err)
+			}
+		})
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
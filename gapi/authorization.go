@@ -2,19 +2,34 @@ package gapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	db "github.com/mariobasic/simplebank/db/sqlc"
 	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"slices"
 	"strings"
+	"time"
 )
 
 const (
-	authorizationHeader = "Authorization"
-	authorizationBearer = "Bearer"
+	authorizationHeader  = "Authorization"
+	authorizationBearer  = "Bearer"
+	sessionTouchInterval = time.Minute
 )
 
-func (s *Server) authorizeUser(ctx context.Context, accessibleRoles []string) (*token.Payload, error) {
+var (
+	accountViewRoles     = []string{util.AccountOwnerRole, util.AccountSignerRole, util.AccountViewerRole}
+	accountTransferRoles = []string{util.AccountOwnerRole, util.AccountSignerRole}
+	accountManageRoles   = []string{util.AccountOwnerRole}
+)
+
+// authorize verifies the bearer token of the request and the session it was issued for against the access policy
+func (s *Server) authorize(ctx context.Context, policy accessPolicy) (*token.Payload, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, fmt.Errorf("missing metadata")
@@ -39,14 +54,89 @@ func (s *Server) authorizeUser(ctx context.Context, accessibleRoles []string) (*
 	if err != nil {
 		return nil, fmt.Errorf("invalid access token: %s", err)
 	}
+	// refresh tokens share the session, a rotated one would otherwise keep working as an access token
+	if err = payload.CheckType(token.TokenTypeAccess); err != nil {
+		return nil, fmt.Errorf("invalid access token: %s", err)
+	}
 
-	if !hasPermission(payload.Role, accessibleRoles) {
-		return nil, fmt.Errorf("permission denied")
+	if err := s.checkSession(ctx, payload, policy); err != nil {
+		return nil, err
 	}
 
 	return payload, nil
 }
 
+// hasAuthorizationHeader tells if the caller sent an access token, which takes precedence over a service identity
+func hasAuthorizationHeader(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(authorizationHeader)) > 0
+}
+
+// checkSession rejects tokens whose session has been blocked by a logout, or no longer exists.
+func (s *Server) checkSession(ctx context.Context, payload *token.Payload, policy accessPolicy) error {
+	row, err := s.store.GetAuthSession(ctx, payload.SessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("session not found")
+		}
+		return fmt.Errorf("failed to get session: %s", err)
+	}
+
+	session := row.Session
+	if session.IsBlocked {
+		return fmt.Errorf("session is blocked")
+	}
+
+	if session.Username != payload.Username {
+		return fmt.Errorf("incorrect session user")
+	}
+
+	if row.IsLocked {
+		return fmt.Errorf("user is locked")
+	}
+
+	if row.MustResetPassword && !policy.allowPendingReset {
+		return fmt.Errorf("password reset required")
+	}
+
+	// the role requires a second factor the session hasn't passed, the user has to enroll first
+	if row.MfaRequired && !session.IsMfaVerified && !policy.allowPendingMfa {
+		return fmt.Errorf("second factor required")
+	}
+
+	// last used is shown to the user, minute precision is enough and saves a write per request
+	if time.Since(session.LastUsedAt) > sessionTouchInterval {
+		if err := s.store.TouchSession(ctx, session.ID); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("session_id", session.ID.String()).Msg("failed to update session last used time")
+		}
+	}
+
+	return nil
+}
+
 func hasPermission(role string, accessibleRoles []string) bool {
 	return slices.Contains(accessibleRoles, role)
 }
+
+// authorizeAccountMember checks that the user is a member of the account with one of the given roles.
+// Pockets have no members of their own, access is granted by the members of the main account.
+func (s *Server) authorizeAccountMember(ctx context.Context, account db.Account, username string, roles []string) error {
+	accountID := account.ID
+	if account.ParentID.Valid {
+		accountID = account.ParentID.Int64
+	}
+
+	member, err := s.store.GetAccountMember(ctx, db.GetAccountMemberParams{AccountID: accountID, Username: username})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+		}
+		return status.Errorf(codes.Internal, "failed to get account member: %s", err)
+	}
+
+	if !slices.Contains(roles, member.Role) {
+		return status.Errorf(codes.PermissionDenied, "account member doesn't have the required permission")
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type cashOperationRequest interface {
+	GetAccountId() int64
+	GetAmount() int64
+	GetReference() string
+	GetMemo() string
+}
+
+// cashOperation performs a deposit or withdrawal on behalf of the authenticated banker
+func (s *Server) cashOperation(ctx context.Context, operationType string, req cashOperationRequest) (db.CashOperationTxResult, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return db.CashOperationTxResult{}, unauthenticatedError(err)
+	}
+
+	violations := validateCashOperationRequest(req)
+	if len(violations) > 0 {
+		return db.CashOperationTxResult{}, invalidArgumentError(violations)
+	}
+
+	result, err := s.store.CashOperationTx(ctx, db.CashOperationTxParams{
+		Type:      operationType,
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+		Reference: req.GetReference(),
+		Memo:      req.GetMemo(),
+		Banker:    authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return result, status.Errorf(codes.NotFound, "account not found: %s", err.Error())
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return result, insufficientFundsError(req.GetAccountId())
+		}
+		if db.ErrorCode(err) == db.UniqueViolationCode {
+			return result, status.Errorf(codes.AlreadyExists, "reference already used: %s", req.GetReference())
+		}
+		return result, status.Errorf(codes.Internal, "failed to record %s: %s", operationType, err.Error())
+	}
+
+	return result, nil
+}
+
+func validateCashOperationRequest(req cashOperationRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateReference(req.GetReference()); err != nil {
+		violations = append(violations, fieldViolation("reference", err))
+	}
+
+	if err := val.ValidateMemo(req.GetMemo()); err != nil {
+		violations = append(violations, fieldViolation("memo", err))
+	}
+
+	return violations
+}
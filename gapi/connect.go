@@ -0,0 +1,168 @@
+package gapi
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const authorizationHTTPHeader = "Authorization"
+
+// NewConnectHandler serves the unary and server streaming methods of the services over the Connect,
+// gRPC-Web and gRPC protocols for browsers, the calls are forwarded to the grpc server like the ones
+// of the JSON gateway so they go through the same interceptors
+func NewConnectHandler(conn grpc.ClientConnInterface, services ...protoreflect.ServiceDescriptor) http.Handler {
+	mux := http.NewServeMux()
+	for _, service := range services {
+		methods := service.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			method := methods.Get(i)
+			procedure := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+			options := []connect.HandlerOption{
+				connect.WithSchema(method),
+				connect.WithRequestInitializer(func(_ connect.Spec, msg any) error {
+					*msg.(*dynamicpb.Message) = *dynamicpb.NewMessage(method.Input())
+					return nil
+				}),
+			}
+
+			switch {
+			case !method.IsStreamingClient() && !method.IsStreamingServer():
+				mux.Handle(procedure, connect.NewUnaryHandler(procedure, proxyUnary(conn, procedure, method), options...))
+			case !method.IsStreamingClient():
+				mux.Handle(procedure, connect.NewServerStreamHandler(procedure, proxyServerStream(conn, procedure, method), options...))
+			}
+			// client streaming needs HTTP/2 end to end, browsers can't make such calls
+		}
+	}
+	return mux
+}
+
+func proxyUnary(
+	conn grpc.ClientConnInterface,
+	procedure string,
+	method protoreflect.MethodDescriptor,
+) func(context.Context, *connect.Request[dynamicpb.Message]) (*connect.Response[dynamicpb.Message], error) {
+	return func(ctx context.Context, req *connect.Request[dynamicpb.Message]) (*connect.Response[dynamicpb.Message], error) {
+		ctx = metadata.NewOutgoingContext(ctx, connectMetadata(req.Header(), req.Peer().Addr))
+
+		res := dynamicpb.NewMessage(method.Output())
+		var header, trailer metadata.MD
+		err := conn.Invoke(ctx, procedure, req.Msg, res, grpc.Header(&header), grpc.Trailer(&trailer))
+		if err != nil {
+			return nil, connectError(err, header, trailer)
+		}
+
+		response := connect.NewResponse(res)
+		copyMetadata(response.Header(), header)
+		copyMetadata(response.Trailer(), trailer)
+		return response, nil
+	}
+}
+
+func proxyServerStream(
+	conn grpc.ClientConnInterface,
+	procedure string,
+	method protoreflect.MethodDescriptor,
+) func(context.Context, *connect.Request[dynamicpb.Message], *connect.ServerStream[dynamicpb.Message]) error {
+	return func(ctx context.Context, req *connect.Request[dynamicpb.Message], stream *connect.ServerStream[dynamicpb.Message]) error {
+		ctx = metadata.NewOutgoingContext(ctx, connectMetadata(req.Header(), req.Peer().Addr))
+
+		clientStream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, procedure)
+		if err != nil {
+			return connectError(err, nil, nil)
+		}
+		if err = clientStream.SendMsg(req.Msg); err != nil {
+			return connectError(err, nil, nil)
+		}
+		if err = clientStream.CloseSend(); err != nil {
+			return connectError(err, nil, nil)
+		}
+
+		header, err := clientStream.Header()
+		if err != nil {
+			return connectError(err, nil, clientStream.Trailer())
+		}
+		copyMetadata(stream.ResponseHeader(), header)
+
+		for {
+			res := dynamicpb.NewMessage(method.Output())
+			err = clientStream.RecvMsg(res)
+			if errors.Is(err, io.EOF) {
+				copyMetadata(stream.ResponseTrailer(), clientStream.Trailer())
+				return nil
+			}
+			if err != nil {
+				return connectError(err, nil, clientStream.Trailer())
+			}
+			if err = stream.Send(res); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// connectMetadata forwards the headers of a Connect request the way the JSON gateway does,
+// the client IP is the address of the HTTP peer that HttpClientIP resolved, an x-forwarded-for header of the client is not trusted
+func connectMetadata(header http.Header, peerAddr string) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range header {
+		if key == authorizationHTTPHeader {
+			md.Append(authorizationHeader, values...)
+			continue
+		}
+		if strings.EqualFold(key, userAgent) {
+			md.Append(grpcGatewayUserAgent, values...)
+			continue
+		}
+		if strings.EqualFold(key, xForwardedForHeader) {
+			continue
+		}
+		if mdKey, ok := GatewayHeaderMatcher(key); ok && !strings.HasPrefix(mdKey, "grpcgateway-") {
+			md.Append(mdKey, values...)
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(peerAddr); err == nil {
+		md.Set(xForwardedForHeader, host)
+	}
+	return md
+}
+
+// copyMetadata passes the grpc headers and trailers on, the reserved grpc ones are set by connect
+func copyMetadata(header http.Header, md metadata.MD) {
+	for key, values := range md {
+		if strings.HasPrefix(key, "grpc-") || key == "content-type" {
+			continue
+		}
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+}
+
+// connectError converts the status of a grpc call, the details like the field violations are kept
+func connectError(err error, header metadata.MD, trailer metadata.MD) error {
+	st := status.Convert(err)
+	connectErr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, detail := range st.Proto().GetDetails() {
+		errorDetail, detailErr := connect.NewErrorDetail(detail)
+		if detailErr == nil {
+			connectErr.AddDetail(errorDetail)
+		}
+	}
+
+	copyMetadata(connectErr.Meta(), header)
+	copyMetadata(connectErr.Meta(), trailer)
+	return connectErr
+}
@@ -0,0 +1,192 @@
+package gapi
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newConnectTestServer serves the server the way main does, Connect calls go through the grpc server over bufconn
+func newConnectTestServer(t *testing.T, server *Server) *httptest.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(GrpcRequestID, server.AuthInterceptor),
+		grpc.ChainStreamInterceptor(GrpcStreamRequestID, server.StreamAuthInterceptor),
+	)
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	handler := NewConnectHandler(conn,
+		pb.File_service_simple_bank_proto.Services().ByName("SimpleBank"),
+		healthpb.File_grpc_health_v1_health_proto.Services().ByName("Health"),
+	)
+	httpServer := httptest.NewServer(HttpLogger(handler))
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+// bearerToken is the Authorization header of a test access token
+func bearerToken(t *testing.T, server *Server, username string) string {
+	md, ok := metadata.FromIncomingContext(newContextWithBearerToken(t, server.tokenMaker, username, util.DepositorRole, time.Minute))
+	require.True(t, ok)
+	return md.Get(authorizationHeader)[0]
+}
+
+func TestConnectHandler(t *testing.T) {
+	user, _ := randomUser(t)
+	account := db.Account{ID: util.RandomInt(1, 1000), Owner: user.Username, Balance: util.RandomMoney(), Currency: util.USD, Version: 1}
+
+	protocols := map[string][]connect.ClientOption{
+		"Connect":     nil,
+		"ConnectJSON": {connect.WithProtoJSON()},
+		"GrpcWeb":     {connect.WithGRPCWeb()},
+	}
+
+	for name, options := range protocols {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+			store.EXPECT().
+				ListMemberAccounts(gomock.Any(), gomock.Eq(db.ListMemberAccountsParams{Username: user.Username, Limit: 5, Offset: 0})).
+				Times(1).
+				Return([]db.Account{account}, nil)
+
+			server := NewTestServer(t, store, nil)
+			httpServer := newConnectTestServer(t, server)
+
+			client := connect.NewClient[pb.ListAccountsRequest, pb.ListAccountsResponse](
+				httpServer.Client(), httpServer.URL+pb.SimpleBank_ListAccounts_FullMethodName, options...)
+
+			// the auth interceptor of the grpc server rejects calls without a token
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&pb.ListAccountsRequest{PageId: 1, PageSize: 5}))
+			require.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+
+			req := connect.NewRequest(&pb.ListAccountsRequest{PageId: 1, PageSize: 5})
+			req.Header().Set(authorizationHTTPHeader, bearerToken(t, server, user.Username))
+			req.Header().Set(requestIDHeader, "connect-request")
+			res, err := client.CallUnary(context.Background(), req)
+			require.NoError(t, err)
+			require.Len(t, res.Msg.GetAccounts(), 1)
+			require.Equal(t, account.ID, res.Msg.GetAccounts()[0].GetId())
+			require.Equal(t, "connect-request", res.Header().Get(requestIDHeader))
+
+			// validation errors keep their field violations
+			req = connect.NewRequest(&pb.ListAccountsRequest{PageId: 0, PageSize: 5})
+			req.Header().Set(authorizationHTTPHeader, bearerToken(t, server, user.Username))
+			_, err = client.CallUnary(context.Background(), req)
+			require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+			var connectErr *connect.Error
+			require.ErrorAs(t, err, &connectErr)
+			var violations []string
+			for _, detail := range connectErr.Details() {
+				value, err := detail.Value()
+				require.NoError(t, err)
+				if badRequest, ok := value.(*errdetails.BadRequest); ok {
+					for _, violation := range badRequest.GetFieldViolations() {
+						violations = append(violations, violation.GetField())
+					}
+				}
+			}
+			require.Equal(t, []string{"page_id"}, violations)
+		})
+	}
+}
+
+func TestConnectHandlerServerStream(t *testing.T) {
+	for name, options := range map[string][]connect.ClientOption{
+		"Connect": nil,
+		"GrpcWeb": {connect.WithGRPCWeb()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			server := NewTestServer(t, store, nil)
+			httpServer := newConnectTestServer(t, server)
+
+			client := connect.NewClient[healthpb.HealthCheckRequest, healthpb.HealthCheckResponse](
+				httpServer.Client(), httpServer.URL+healthpb.Health_Watch_FullMethodName, options...)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			stream, err := client.CallServerStream(ctx, connect.NewRequest(&healthpb.HealthCheckRequest{}))
+			require.NoError(t, err)
+			defer stream.Close()
+
+			require.True(t, stream.Receive(), stream.Err())
+			require.Equal(t, healthpb.HealthCheckResponse_SERVING, stream.Msg().GetStatus())
+		})
+	}
+}
+
+func TestConnectMetadata(t *testing.T) {
+	header := http.Header{}
+	header.Set(authorizationHTTPHeader, "Bearer token")
+	header.Set("User-Agent", "browser")
+	header.Set("X-Forwarded-For", "1.2.3.4")
+	header.Set("X-Request-Id", "request")
+	header.Set("Grpc-Metadata-X-Client-Cert-Subject", "CN=spoofed")
+	header.Set("Connect-Protocol-Version", "1")
+	header.Set("Content-Type", "application/json")
+
+	md := connectMetadata(header, "10.0.0.1:50000")
+	require.Equal(t, []string{"Bearer token"}, md.Get(authorizationHeader))
+	require.Equal(t, []string{"browser"}, md.Get(grpcGatewayUserAgent))
+	require.Equal(t, []string{"10.0.0.1"}, md.Get(xForwardedForHeader))
+	require.Equal(t, []string{"request"}, md.Get(requestIDHeader))
+	require.Empty(t, md.Get(clientCertSubjectHeader))
+	require.Len(t, md, 4)
+}
+
+func TestCorsPreflight(t *testing.T) {
+	handler := NewCors([]string{"https://bank.example"}).Handler(http.NotFoundHandler())
+
+	request := httptest.NewRequest(http.MethodOptions, pb.SimpleBank_ListAccounts_FullMethodName, nil)
+	request.Header.Set("Origin", "https://bank.example")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	// browsers send the requested headers lowercased and sorted
+	request.Header.Set("Access-Control-Request-Headers", "authorization,connect-protocol-version,content-type,x-grpc-web,x-request-id")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	require.Equal(t, "https://bank.example", response.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "authorization,connect-protocol-version,content-type,x-grpc-web,x-request-id",
+		response.Header().Get("Access-Control-Allow-Headers"))
+
+	request.Header.Set("Origin", "https://evil.example")
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Empty(t, response.Header().Get("Access-Control-Allow-Origin"))
+}
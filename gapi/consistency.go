@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"strings"
+)
+
+const (
+	readConsistencyHeader = "x-read-consistency"
+	strongConsistency     = "strong"
+)
+
+// ReadConsistency routes the reads of a gRPC request to the primary database
+// when the client asks for strong consistency to read its own writes
+func ReadConsistency(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(readConsistencyHeader); len(values) > 0 && strings.EqualFold(values[0], strongConsistency) {
+			ctx = db.WithPrimary(ctx)
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+// GatewayHeaderMatcher forwards the read consistency, request id, If-Match and client certificate headers of HTTP requests
+// to the grpc server, the headers the gateway sets itself can't be smuggled in as Grpc-Metadata- headers
+func GatewayHeaderMatcher(key string) (string, bool) {
+	switch {
+	case strings.EqualFold(key, readConsistencyHeader):
+		return readConsistencyHeader, true
+	case strings.EqualFold(key, requestIDHeader):
+		return requestIDHeader, true
+	case strings.EqualFold(key, ifMatchHeader):
+		return ifMatchHeader, true
+	case strings.EqualFold(key, clientCertSubjectHeader):
+		return clientCertSubjectHeader, true
+	case strings.EqualFold(key, runtime.MetadataHeaderPrefix+clientCertSubjectHeader),
+		strings.EqualFold(key, runtime.MetadataHeaderPrefix+xForwardedForHeader):
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
@@ -1,8 +1,10 @@
 package gapi
 
 import (
+	"github.com/google/uuid"
 	db "github.com/mariobasic/simplebank/db/sqlc"
 	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -13,5 +15,118 @@ func convertUser(user db.User) *pb.User {
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Role:              user.Role,
+		IsLocked:          user.IsLocked,
+		MustResetPassword: user.MustResetPassword,
+		Etag:              formatETag(user.Version),
 	}
 }
+
+func convertUsers(users []db.User) []*pb.User {
+	result := make([]*pb.User, len(users))
+	for i, user := range users {
+		result[i] = convertUser(user)
+	}
+	return result
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:         account.ID,
+		Owner:      account.Owner,
+		Balance:    account.Balance,
+		Currency:   account.Currency,
+		CreatedAt:  timestamppb.New(account.CreatedAt),
+		ParentId:   account.ParentID.Int64,
+		Name:       account.Name,
+		GoalAmount: account.GoalAmount,
+		Etag:       formatETag(account.Version),
+	}
+}
+
+func convertAccounts(accounts []db.Account) []*pb.Account {
+	result := make([]*pb.Account, len(accounts))
+	for i, account := range accounts {
+		result[i] = convertAccount(account)
+	}
+	return result
+}
+
+func convertAccountMember(member db.AccountMember) *pb.AccountMember {
+	return &pb.AccountMember{
+		AccountId: member.AccountID,
+		Username:  member.Username,
+		Role:      member.Role,
+		InvitedBy: member.InvitedBy,
+		CreatedAt: timestamppb.New(member.CreatedAt),
+	}
+}
+
+func convertAccountMembers(members []db.AccountMember) []*pb.AccountMember {
+	result := make([]*pb.AccountMember, len(members))
+	for i, member := range members {
+		result[i] = convertAccountMember(member)
+	}
+	return result
+}
+
+func convertCashOperation(operation db.CashOperation) *pb.CashOperation {
+	return &pb.CashOperation{
+		Id:         operation.ID,
+		Type:       operation.Type,
+		AccountId:  operation.AccountID,
+		TransferId: operation.TransferID,
+		Amount:     operation.Amount,
+		Reference:  operation.Reference,
+		Memo:       operation.Memo,
+		Banker:     operation.Banker,
+		CreatedAt:  timestamppb.New(operation.CreatedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func convertSession(session db.Session, currentID uuid.UUID) *pb.Session {
+	device := util.ParseDevice(session.UserAgent)
+	return &pb.Session{
+		Id:        session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		Device: &pb.Device{
+			Browser:        device.Browser,
+			BrowserVersion: device.BrowserVersion,
+			Os:             device.OS,
+			Platform:       device.Platform,
+			Mobile:         device.Mobile,
+		},
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		LastUsedAt: timestamppb.New(session.LastUsedAt),
+		ExpiredAt:  timestamppb.New(session.ExpiredAt),
+		Current:    session.ID == currentID,
+	}
+}
+
+func convertSessions(sessions []db.Session, currentID uuid.UUID) []*pb.Session {
+	result := make([]*pb.Session, len(sessions))
+	for i, session := range sessions {
+		result[i] = convertSession(session, currentID)
+	}
+	return result
+}
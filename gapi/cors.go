@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"github.com/rs/cors"
+	"net/http"
+	"time"
+)
+
+// corsMaxAge is how long browsers cache the preflight responses
+const corsMaxAge = 2 * time.Hour
+
+// NewCors lets browsers of the allowed origins call the JSON gateway and the Connect and gRPC-Web protocols,
+// a "*" origin allows any
+func NewCors(allowedOrigins []string) *cors.Cors {
+	return cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{
+			http.MethodGet,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+		AllowedHeaders: []string{
+			authorizationHTTPHeader,
+			"Content-Type",
+			"Accept",
+			requestIDHeader,
+			readConsistencyHeader,
+			ifMatchHeader,
+			// Connect
+			"Connect-Protocol-Version",
+			"Connect-Timeout-Ms",
+			"Connect-Accept-Encoding",
+			"Connect-Content-Encoding",
+			// gRPC-Web
+			"Grpc-Timeout",
+			"X-Grpc-Web",
+			"X-User-Agent",
+		},
+		ExposedHeaders: []string{
+			requestIDHeader,
+			etagHeader,
+			retryAfterHeader,
+			"Content-Encoding",
+			"Connect-Content-Encoding",
+			"Grpc-Status",
+			"Grpc-Message",
+			"Grpc-Status-Details-Bin",
+		},
+		MaxAge: int(corsMaxAge.Seconds()),
+	})
+}
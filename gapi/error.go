@@ -1,9 +1,10 @@
 package gapi
 
 import (
+	"fmt"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/problem"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
@@ -14,17 +15,13 @@ func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolati
 }
 
 func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
-	badRequest := &errdetails.BadRequest{FieldViolations: violations}
-	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
-
-	statusDetails, err := statusInvalid.WithDetails(badRequest)
-	if err != nil {
-		return statusInvalid.Err()
-	}
-
-	return statusDetails.Err()
+	return problem.InvalidArgument.Error("invalid parameters", &errdetails.BadRequest{FieldViolations: violations})
 }
 
 func unauthenticatedError(err error) error {
-	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err.Error())
+	return problem.Unauthenticated.Error("unauthorized: " + err.Error())
+}
+
+func insufficientFundsError(accountID int64) error {
+	return problem.InsufficientFunds.Error(fmt.Sprintf("account %d: %s", accountID, db.ErrInsufficientFunds))
 }
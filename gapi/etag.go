@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/mariobasic/simplebank/pb"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ifMatchHeader carries the etag an update is based on, the gateway forwards the HTTP header of the same name
+	ifMatchHeader = "if-match"
+	etagHeader    = "ETag"
+	// anyETag matches any version, the update is not guarded
+	anyETag = "*"
+)
+
+// formatETag is the strong etag of a row version
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseETag returns the row version of an etag, the quotes are optional for the etag request fields
+func parseETag(etag string) (int64, error) {
+	etag = strings.TrimSpace(etag)
+	if strings.HasPrefix(etag, "W/") {
+		return 0, fmt.Errorf("weak etags can't guard updates")
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(etag, `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("must be an etag returned by the server")
+	}
+	return version, nil
+}
+
+// requestETag is the etag field of the request, or the If-Match header when the field is not set
+func requestETag(ctx context.Context, field *string) string {
+	if field != nil {
+		return *field
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ifMatchHeader); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// GatewayETag returns the etag of the user or account of a response as the ETag header
+func GatewayETag(_ context.Context, w http.ResponseWriter, resp proto.Message) error {
+	switch r := resp.(type) {
+	case interface{ GetUser() *pb.User }:
+		if user := r.GetUser(); user != nil && user.GetEtag() != "" {
+			w.Header().Set(etagHeader, user.GetEtag())
+		}
+	case interface{ GetAccount() *pb.Account }:
+		if account := r.GetAccount(); account != nil && account.GetEtag() != "" {
+			w.Header().Set(etagHeader, account.GetEtag())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseETag(t *testing.T) {
+	version, err := parseETag(formatETag(42))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), version)
+
+	// the etag request field doesn't need the quotes
+	version, err = parseETag("42")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), version)
+
+	for _, etag := range []string{`W/"42"`, `"abc"`, `"0"`, `"-1"`, ""} {
+		_, err = parseETag(etag)
+		require.Errorf(t, err, "etag %q", etag)
+	}
+}
+
+func TestGatewayETag(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := GatewayETag(context.Background(), recorder, &pb.UpdateUserResponse{User: &pb.User{Etag: formatETag(2)}})
+	require.NoError(t, err)
+	require.Equal(t, `"2"`, recorder.Header().Get(etagHeader))
+
+	recorder = httptest.NewRecorder()
+	err = GatewayETag(context.Background(), recorder, &pb.GetAccountResponse{Account: &pb.Account{Etag: formatETag(7)}})
+	require.NoError(t, err)
+	require.Equal(t, `"7"`, recorder.Header().Get(etagHeader))
+
+	recorder = httptest.NewRecorder()
+	err = GatewayETag(context.Background(), recorder, &pb.ListAccountsResponse{})
+	require.NoError(t, err)
+	require.Empty(t, recorder.Header().Get(etagHeader))
+
+	key, ok := GatewayHeaderMatcher("If-Match")
+	require.True(t, ok)
+	require.Equal(t, ifMatchHeader, key)
+}
@@ -0,0 +1,40 @@
+package gapi
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const updateMaskField = "update_mask"
+
+// fieldUpdater validates the new value of a field mask path and sets it on the update
+type fieldUpdater func() error
+
+// applyFieldMask runs the updater of every path of the mask, so only the listed fields change
+// and a field can be set to its zero value, unknown paths and invalid values are violations
+func applyFieldMask(mask *fieldmaskpb.FieldMask, updaters map[string]fieldUpdater) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(mask.GetPaths()) == 0 {
+		return append(violations, fieldViolation(updateMaskField, errors.New("must list at least one field to update")))
+	}
+
+	applied := make(map[string]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		if applied[path] {
+			continue
+		}
+		applied[path] = true
+
+		updater, ok := updaters[path]
+		if !ok {
+			violations = append(violations, fieldViolation(updateMaskField, fmt.Errorf("unknown path %q", path)))
+			continue
+		}
+		if err := updater(); err != nil {
+			violations = append(violations, fieldViolation(path, err))
+		}
+	}
+
+	return violations
+}
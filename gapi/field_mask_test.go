@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// updateUserRecorder keeps the UpdateUser request the gateway makes
+type updateUserRecorder struct {
+	pb.UnimplementedSimpleBankServer
+	req *pb.UpdateUserRequest
+}
+
+func (r *updateUserRecorder) UpdateUser(_ context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	r.req = req
+	return &pb.UpdateUserResponse{User: &pb.User{Username: req.GetUsername()}}, nil
+}
+
+func TestGatewayUpdateMask(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		body  string
+		check func(t *testing.T, req *pb.UpdateUserRequest)
+	}{
+		{
+			name: "BodyFields",
+			path: "/v1/users/alice",
+			body: `{"full_name": "", "email": "alice@email.com"}`,
+			check: func(t *testing.T, req *pb.UpdateUserRequest) {
+				require.Equal(t, "alice", req.GetUsername())
+				require.ElementsMatch(t, []string{"full_name", "email"}, req.GetUpdateMask().GetPaths())
+				require.Empty(t, req.GetUser().GetFullName())
+			},
+		},
+		{
+			name: "ExplicitMask",
+			path: "/v1/users/alice?update_mask=email",
+			body: `{"full_name": "Alice", "email": "alice@email.com"}`,
+			check: func(t *testing.T, req *pb.UpdateUserRequest) {
+				require.Equal(t, []string{"email"}, req.GetUpdateMask().GetPaths())
+			},
+		},
+		{
+			name: "LegacyBody",
+			path: "/v1/users",
+			body: `{"username": "alice", "full_name": "Alice"}`,
+			check: func(t *testing.T, req *pb.UpdateUserRequest) {
+				require.Empty(t, req.GetUpdateMask().GetPaths())
+				require.Equal(t, "Alice", req.GetFullName())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &updateUserRecorder{}
+			mux := runtime.NewServeMux()
+			require.NoError(t, pb.RegisterSimpleBankHandlerServer(context.Background(), mux, recorder))
+
+			response := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPatch, tt.path, strings.NewReader(tt.body))
+			mux.ServeHTTP(response, request)
+
+			require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+			require.NotNil(t, recorder.req)
+			tt.check(t, recorder.req)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package gapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mariobasic/simplebank/token"
+	"net/http"
+	"time"
+)
+
+// JWKSPath is where other services fetch the keys to verify tokens with
+const JWKSPath = "/.well-known/jwks.json"
+
+// jwksMaxAge is how long verifiers may cache the keys, so a new key is added at least this long before it signs
+const jwksMaxAge = 5 * time.Minute
+
+// JWKSHandler publishes the public keys of the token signing keys, the private keys never leave this service
+func JWKSHandler(keys *token.KeySet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+		_ = json.NewEncoder(w).Encode(keys.JWKS())
+	})
+}
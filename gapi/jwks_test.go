@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys, err := token.NewKeySet(token.SigningKey{
+		ID:         "2026-10",
+		Algorithm:  token.AlgorithmEdDSA,
+		PrivateKey: privateKey,
+		ActiveFrom: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	response := httptest.NewRecorder()
+	JWKSHandler(keys).ServeHTTP(response, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	require.Equal(t, "public, max-age=300", response.Header().Get("Cache-Control"))
+
+	var jwks token.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &jwks))
+	require.Equal(t, keys.JWKS(), jwks)
+	require.NotContains(t, response.Body.String(), `"d"`)
+
+	response = httptest.NewRecorder()
+	JWKSHandler(keys).ServeHTTP(response, httptest.NewRequest(http.MethodPost, JWKSPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
@@ -1,7 +1,10 @@
 package gapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"github.com/mariobasic/simplebank/problem"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -19,14 +22,11 @@ func GrpcLogger(
 	start := time.Now()
 	result, err := handler(ctx, req)
 
-	statusCode := codes.Unknown
-	if st, ok := status.FromError(err); ok {
-		statusCode = st.Code()
-	}
+	statusCode := grpcStatusCode(err)
 
-	logger := log.Info()
+	logger := log.Ctx(ctx).Info()
 	if err != nil {
-		logger = log.Error().Err(err)
+		logger = log.Ctx(ctx).Error().Err(err)
 	}
 
 	logger.
@@ -40,6 +40,14 @@ func GrpcLogger(
 	return result, err
 }
 
+// grpcStatusCode is the status code a handler error is sent with
+func grpcStatusCode(err error) codes.Code {
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+	return codes.Unknown
+}
+
 type ResponseRecorder struct {
 	http.ResponseWriter
 	StatusCode int
@@ -56,16 +64,29 @@ func (r *ResponseRecorder) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// Flush lets streaming responses through, connect needs it for server streaming calls
+func (r *ResponseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func HttpLogger(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		start := time.Now()
 
 		rec := &ResponseRecorder{ResponseWriter: res, StatusCode: http.StatusOK}
 		handler.ServeHTTP(rec, req)
-		logger := log.Info()
+		logger := log.Ctx(req.Context()).Info()
 
 		if rec.StatusCode != http.StatusOK {
-			logger = log.Error().Bytes("body", rec.body)
+			logger = log.Ctx(req.Context()).Error()
+			// problem details are logged as structured fields instead of a raw string
+			if problemBody := bytes.TrimSpace(rec.body); rec.Header().Get("Content-Type") == problem.ContentType && json.Valid(problemBody) {
+				logger = logger.RawJSON("problem", problemBody)
+			} else {
+				logger = logger.Bytes("body", rec.body)
+			}
 		}
 
 		logger.
@@ -3,32 +3,97 @@ package gapi
 import (
 	"context"
 	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
 	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/ratelimit"
 	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/mariobasic/simplebank/worker"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"sync"
 	"testing"
 	"time"
 )
 
 func NewTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
-	config := util.Config{Token: struct {
-		SymmetricKey    string        `mapstructure:"symmetric_key"`
-		AccessDuration  time.Duration `mapstructure:"access_duration"`
-		RefreshDuration time.Duration `mapstructure:"refresh_duration"`
-	}{SymmetricKey: util.RandomString(32), AccessDuration: time.Minute, RefreshDuration: time.Hour}}
+	config := util.Config{}
+	config.Token.AccessDuration = time.Minute
+	config.Token.RefreshDuration = time.Hour
+	config.Token.MfaDuration = time.Minute
+	config.TLS.Services = []string{testServiceSubject.String()}
 
-	return NewServer(config, store, taskDistributor)
+	return NewServer(config, store, taskDistributor, newTestTokenMaker(t), ratelimit.NewMemoryLimiter())
 }
 
+func newTestTokenMaker(t *testing.T) token.Maker {
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+	return tokenMaker
+}
+
+// testSessions holds the sessions of the tokens created by newContextWithBearerToken
+var testSessions sync.Map
+
 func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, role string, duration time.Duration) context.Context {
-	accessToken, _, err := tokenMaker.CreateToken(username, role, duration)
+	session := db.Session{ID: uuid.New(), Username: username, ExpiredAt: time.Now().Add(duration), LastUsedAt: time.Now()}
+	return newContextWithSession(t, tokenMaker, session, role, duration)
+}
+
+func newContextWithSession(t *testing.T, tokenMaker token.Maker, session db.Session, role string, duration time.Duration) context.Context {
+	return newContextWithAuthSession(t, tokenMaker, db.GetAuthSessionRow{Session: session}, role, duration)
+}
+
+// newContextWithAuthSession also sets the state of the session user checked by the auth interceptor
+func newContextWithAuthSession(t *testing.T, tokenMaker token.Maker, row db.GetAuthSessionRow, role string, duration time.Duration) context.Context {
+	testSessions.Store(row.Session.ID, row)
+
+	accessToken, _, err := tokenMaker.CreateToken(row.Session.Username, role, row.Session.ID, token.TokenTypeAccess, duration)
 	require.NoError(t, err)
+
+	return contextWithAccessToken(accessToken)
+}
+
+func contextWithAccessToken(accessToken string) context.Context {
 	bearerToken := fmt.Sprintf("%s %s", authorizationBearer, accessToken)
 
 	return metadata.NewIncomingContext(context.Background(), metadata.MD{
 		authorizationHeader: []string{bearerToken},
 	})
 }
+
+// withIncomingMetadata adds a header to the incoming metadata of the context
+func withIncomingMetadata(ctx context.Context, key string, value string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return metadata.NewIncomingContext(ctx, metadata.Join(md, metadata.Pairs(key, value)))
+}
+
+// stubSessions lets the session check of the auth interceptor find the sessions of the test tokens
+func stubSessions(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetAuthSession(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, id uuid.UUID) (db.GetAuthSessionRow, error) {
+			row, ok := testSessions.Load(id)
+			if !ok {
+				return db.GetAuthSessionRow{}, db.ErrRecordNotFound
+			}
+			return row.(db.GetAuthSessionRow), nil
+		})
+	store.EXPECT().TouchSession(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+}
+
+// callUnary runs the RPC behind the auth interceptor, the way the grpc server calls it
+func callUnary[Req, Res any](server *Server, ctx context.Context, method string, req Req, rpc func(context.Context, Req) (Res, error)) (Res, error) {
+	var res Res
+	resp, err := server.AuthInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return rpc(ctx, req.(Req))
+	})
+	if resp != nil {
+		res = resp.(Res)
+	}
+	return res, err
+}
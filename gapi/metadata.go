@@ -4,6 +4,9 @@ import (
 	"context"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"net"
+	"net/http"
+	"strings"
 )
 
 const (
@@ -12,6 +15,8 @@ const (
 	xForwardedForHeader  = "x-forwarded-for"
 	// grpc
 	userAgent = "user-agent"
+	// gatewayNetwork is the network of the in-memory connection the HTTP gateway calls the grpc server over
+	gatewayNetwork = "bufconn"
 )
 
 type Metadata struct {
@@ -22,24 +27,63 @@ type Metadata struct {
 func (s *Server) extractMetadata(ctx context.Context) *Metadata {
 	mtdt := &Metadata{}
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if usrAgents := md.Get(grpcGatewayUserAgent); len(usrAgents) > 0 {
+		if usrAgents := md.Get(userAgent); len(usrAgents) > 0 {
 			mtdt.UserAgent = usrAgents[0]
 		}
-		if usrAgents := md.Get(userAgent); len(usrAgents) > 0 {
+		// the gateway connection has a user agent of its own, the one of the HTTP client wins
+		if usrAgents := md.Get(grpcGatewayUserAgent); len(usrAgents) > 0 {
 			mtdt.UserAgent = usrAgents[0]
 		}
 
-		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
-			mtdt.ClientIP = clientIPs[0]
-		}
+		// HttpClientIP already resolved the client IP, the gateway forwards it alone
 		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
 			mtdt.ClientIP = clientIPs[0]
 		}
 	}
 
-	if p, ok := peer.FromContext(ctx); ok {
+	// requests from the gateway carry the client IP in x-forwarded-for, the peer is only trusted for direct calls
+	if p, ok := peer.FromContext(ctx); ok && p.Addr.Network() != gatewayNetwork {
 		mtdt.ClientIP = p.Addr.String()
+		// drop the port, it changes with every connection and would make each login look like a new IP
+		if host, _, err := net.SplitHostPort(mtdt.ClientIP); err == nil {
+			mtdt.ClientIP = host
+		}
 	}
 
 	return mtdt
 }
+
+// HttpClientIP resolves the client IP before the gateway and the connect handler see the request.
+// Clients can send any X-Forwarded-For, only the last trustedProxies hops were added by our own proxies,
+// so the hop before them becomes the remote address and the header is dropped
+func HttpClientIP(trustedProxies int) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if host, port, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+				req.RemoteAddr = net.JoinHostPort(forwardedClientIP(req.Header, host, trustedProxies), port)
+			}
+			req.Header.Del(xForwardedForHeader)
+
+			handler.ServeHTTP(res, req)
+		})
+	}
+}
+
+func forwardedClientIP(header http.Header, remoteIP string, trustedProxies int) string {
+	var hops []string
+	for _, value := range header.Values(xForwardedForHeader) {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	hops = append(hops, remoteIP)
+
+	// every trusted proxy appended the address it got the request from
+	clientIP := hops[max(len(hops)-1-trustedProxies, 0)]
+	if net.ParseIP(clientIP) == nil {
+		return remoteIP
+	}
+	return clientIP
+}
@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"context"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/mariobasic/simplebank/metrics"
+	"google.golang.org/grpc"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GrpcMetrics records the rate, errors and duration of gRPC requests per method
+func GrpcMetrics(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	start := time.Now()
+	result, err := handler(ctx, req)
+
+	metrics.GrpcRequests.WithLabelValues(info.FullMethod, grpcStatusCode(err).String()).Inc()
+	metrics.GrpcDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+
+	return result, err
+}
+
+// GatewayMetrics records the rate, errors and duration of HTTP requests per gateway route
+func GatewayMetrics(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		start := time.Now()
+		rec := &ResponseRecorder{ResponseWriter: w, StatusCode: http.StatusOK}
+		next(rec, r, pathParams)
+
+		route := gatewayRoute(r)
+		metrics.HttpRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.StatusCode)).Inc()
+		metrics.HttpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// gatewayRoute is the path template the gateway matched, e.g. /v1/accounts/{id}, so every account
+// doesn't get a metric of its own. runtime.HTTPPathPattern is only set inside the generated handlers,
+// the matched pattern is already on the request when the middlewares run
+func gatewayRoute(r *http.Request) string {
+	pattern, ok := runtime.HTTPPattern(r.Context())
+	if !ok {
+		return "unknown"
+	}
+	// single segment variables are printed as {id=*}
+	return strings.ReplaceAll(pattern.String(), "=*}", "}")
+}
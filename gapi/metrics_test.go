@@ -0,0 +1,48 @@
+package gapi
+
+import (
+	"context"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGrpcMetrics(t *testing.T) {
+	method := pb.SimpleBank_GetAccount_FullMethodName
+	notFound := metrics.GrpcRequests.WithLabelValues(method, codes.NotFound.String())
+	before := testutil.ToFloat64(notFound)
+
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, err := GrpcMetrics(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "account not found")
+	})
+	require.Error(t, err)
+	require.Equal(t, before+1, testutil.ToFloat64(notFound))
+}
+
+func TestGatewayMetrics(t *testing.T) {
+	mux := runtime.NewServeMux(runtime.WithMiddlewares(GatewayMetrics))
+	err := mux.HandlePath(http.MethodGet, "/v1/users/{username}", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	require.NoError(t, err)
+
+	requests := metrics.HttpRequests.WithLabelValues(http.MethodGet, "/v1/users/{username}", "404")
+	before := testutil.ToFloat64(requests)
+
+	// a parameter equal to a literal segment of the path doesn't change the route
+	for _, path := range []string{"/v1/users/alice", "/v1/users/users", "/v1/users/v1"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	require.Equal(t, before+3, testutil.ToFloat64(requests))
+	require.Zero(t, testutil.ToFloat64(metrics.HttpRequests.WithLabelValues(http.MethodGet, "/v1/{username}/{username}", "404")))
+}
@@ -0,0 +1,61 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/mariobasic/simplebank/problem"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// GatewayErrorHandler renders the errors of the gateway as RFC 7807 problem details,
+// rate limited requests get the Retry-After header with their 429
+func GatewayErrorHandler(
+	ctx context.Context,
+	_ *runtime.ServeMux,
+	_ runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	// routing errors of the mux come with their own HTTP status
+	httpStatus := 0
+	var statusErr *runtime.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		httpStatus = statusErr.HTTPStatus
+		err = statusErr.Err
+	}
+
+	st := status.Convert(err)
+	p := problem.FromStatus(st)
+	if httpStatus != 0 {
+		p.Status = httpStatus
+	}
+	p.Instance = r.URL.Path
+	p.RequestID = util.RequestIDFromContext(ctx)
+
+	for _, detail := range st.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			seconds := math.Ceil(retryInfo.GetRetryDelay().AsDuration().Seconds())
+			w.Header().Set(retryAfterHeader, strconv.Itoa(max(1, int(seconds))))
+		}
+	}
+
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for key, values := range md.HeaderMD {
+			for _, value := range values {
+				w.Header().Add(runtime.MetadataHeaderPrefix+key, value)
+			}
+		}
+	}
+
+	if err := p.Write(w); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("cannot write problem response")
+	}
+}
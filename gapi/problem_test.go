@@ -0,0 +1,96 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/mariobasic/simplebank/problem"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGatewayErrorHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		check func(t *testing.T, recorder *httptest.ResponseRecorder, p problem.Problem)
+	}{
+		{
+			name: "RateLimited",
+			err:  resourceExhaustedError(1500 * time.Millisecond),
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder, p problem.Problem) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "2", recorder.Header().Get(retryAfterHeader))
+				require.Equal(t, problem.RateLimited.URI(), p.Type)
+				require.Equal(t, problem.RateLimited.Code, p.Code)
+			},
+		},
+		{
+			name: "InvalidArgument",
+			err: invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+				fieldViolation("amount", errors.New("must be a positive integer")),
+			}),
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder, p problem.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, problem.InvalidArgument.URI(), p.Type)
+				require.Equal(t, "invalid parameters", p.Detail)
+				require.Equal(t, []problem.InvalidParam{{Name: "amount", Reason: "must be a positive integer"}}, p.InvalidParams)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			err:  insufficientFundsError(1),
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder, p problem.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, problem.InsufficientFunds.URI(), p.Type)
+				require.Equal(t, problem.InsufficientFunds.Title, p.Title)
+			},
+		},
+		{
+			name: "PlainStatus",
+			err:  status.Error(codes.NotFound, "account not found"),
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder, p problem.Problem) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, problem.NotFound.URI(), p.Type)
+				require.Equal(t, "account not found", p.Detail)
+			},
+		},
+		{
+			name: "RoutingError",
+			err:  &runtime.HTTPStatusError{HTTPStatus: http.StatusMethodNotAllowed, Err: status.Error(codes.Unimplemented, "method not allowed")},
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder, p problem.Problem) {
+				require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+				require.Equal(t, http.StatusMethodNotAllowed, p.Status)
+				require.Equal(t, problem.Unimplemented.URI(), p.Type)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := runtime.NewServeMux()
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/v1/transfers", nil)
+			requestID := util.RequestIDOrNew("")
+			ctx := util.WithRequestID(context.Background(), requestID)
+
+			GatewayErrorHandler(ctx, mux, &runtime.JSONPb{}, recorder, request, tt.err)
+
+			require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+			var p problem.Problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+			require.Equal(t, recorder.Code, p.Status)
+			require.Equal(t, "/v1/transfers", p.Instance)
+			require.Equal(t, requestID, p.RequestID)
+			tt.check(t, recorder, p)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/mariobasic/simplebank/problem"
+	"github.com/mariobasic/simplebank/ratelimit"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"path"
+	"strings"
+	"time"
+)
+
+const retryAfterHeader = "Retry-After"
+
+// methodRateLimits maps the configured limits to full method names,
+// a method is configured by its name in snake case, e.g. login_user
+func methodRateLimits(config util.Config) (map[string]ratelimit.Limit, error) {
+	limits := map[string]ratelimit.Limit{}
+	for name, limit := range config.RateLimit.Methods {
+		fullMethod, ok := findMethod(strings.ReplaceAll(name, "_", ""))
+		if !ok {
+			return nil, fmt.Errorf("rate limit for unknown method %s", name)
+		}
+		if limit.Requests <= 0 || limit.Window <= 0 {
+			return nil, fmt.Errorf("invalid rate limit for method %s", name)
+		}
+		limits[fullMethod] = ratelimit.Limit{Requests: limit.Requests, Window: limit.Window}
+	}
+	return limits, nil
+}
+
+func findMethod(name string) (string, bool) {
+	for fullMethod := range accessPolicies {
+		if strings.EqualFold(path.Base(fullMethod), name) {
+			return fullMethod, true
+		}
+	}
+	return "", false
+}
+
+// RateLimiter limits the calls of a method per client IP and per username,
+// it runs after the auth interceptor to key authenticated calls by their user
+func (s *Server) RateLimiter(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	limit, ok := s.rateLimits[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	for _, key := range s.rateLimitKeys(ctx, info.FullMethod, req) {
+		result, err := s.rateLimiter.Allow(ctx, key, limit)
+		if err != nil {
+			// an unavailable limiter must not take the API down with it
+			log.Ctx(ctx).Error().Err(err).Str("key", key).Msg("failed to check rate limit")
+			continue
+		}
+		if !result.Allowed {
+			return nil, resourceExhaustedError(result.RetryAfter)
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+type usernameRequest interface {
+	GetUsername() string
+}
+
+// rateLimitKeys keys the call by the client IP, and by the username of the caller
+// or the one the request acts for, so a login can't be brute-forced from many IPs
+func (s *Server) rateLimitKeys(ctx context.Context, method string, req any) []string {
+	var keys []string
+	if clientIP := s.extractMetadata(ctx).ClientIP; clientIP != "" {
+		keys = append(keys, method+":ip:"+clientIP)
+	}
+
+	username := ""
+	if payload, err := authPayloadFromContext(ctx); err == nil {
+		username = payload.Username
+	} else if r, ok := req.(usernameRequest); ok {
+		username = r.GetUsername()
+	}
+	if username != "" {
+		keys = append(keys, method+":user:"+username)
+	}
+
+	return keys
+}
+
+func resourceExhaustedError(retryAfter time.Duration) error {
+	return problem.RateLimited.Error("too many requests", &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+}
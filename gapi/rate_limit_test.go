@@ -0,0 +1,187 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/problem"
+	"github.com/mariobasic/simplebank/ratelimit"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type rateLimitConfig = map[string]struct {
+	Requests int           `mapstructure:"requests"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+func newRateLimitedTestServer(t *testing.T, store *mockdb.MockStore) *Server {
+	config := util.Config{}
+	config.RateLimit.Methods = rateLimitConfig{
+		"login_user":      {Requests: 2, Window: time.Minute},
+		"create_transfer": {Requests: 1, Window: time.Minute},
+	}
+
+	return NewServer(config, store, nil, newTestTokenMaker(t), ratelimit.NewMemoryLimiter())
+}
+
+func contextWithClientIP(ctx context.Context, ip string) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}})
+}
+
+func callRateLimiter(server *Server, ctx context.Context, method string, req any) error {
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, err := server.RateLimiter(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func requireRateLimited(t *testing.T, err error) {
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 2)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.Positive(t, retryInfo.GetRetryDelay().AsDuration())
+	errorInfo, ok := st.Details()[1].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, problem.RateLimited.Code, errorInfo.GetReason())
+}
+
+func TestServer_RateLimiterByIP(t *testing.T) {
+	server := newRateLimitedTestServer(t, nil)
+	ctx := contextWithClientIP(context.Background(), "10.0.0.1")
+
+	for i := 0; i < 2; i++ {
+		req := &pb.LoginUserRequest{Username: util.RandomOwner()}
+		require.NoError(t, callRateLimiter(server, ctx, pb.SimpleBank_LoginUser_FullMethodName, req))
+	}
+
+	err := callRateLimiter(server, ctx, pb.SimpleBank_LoginUser_FullMethodName, &pb.LoginUserRequest{Username: util.RandomOwner()})
+	requireRateLimited(t, err)
+
+	otherCtx := contextWithClientIP(context.Background(), "10.0.0.2")
+	err = callRateLimiter(server, otherCtx, pb.SimpleBank_LoginUser_FullMethodName, &pb.LoginUserRequest{Username: util.RandomOwner()})
+	require.NoError(t, err)
+}
+
+func TestServer_RateLimiterByUsername(t *testing.T) {
+	server := newRateLimitedTestServer(t, nil)
+	req := &pb.LoginUserRequest{Username: util.RandomOwner()}
+
+	for i := 0; i < 2; i++ {
+		ctx := contextWithClientIP(context.Background(), randomIP())
+		require.NoError(t, callRateLimiter(server, ctx, pb.SimpleBank_LoginUser_FullMethodName, req))
+	}
+
+	ctx := contextWithClientIP(context.Background(), randomIP())
+	requireRateLimited(t, callRateLimiter(server, ctx, pb.SimpleBank_LoginUser_FullMethodName, req))
+}
+
+func TestServer_RateLimiterAuthenticatedUser(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	stubSessions(store)
+	server := newRateLimitedTestServer(t, store)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+	ctx, err := server.authenticate(ctx, pb.SimpleBank_CreateTransfer_FullMethodName)
+	require.NoError(t, err)
+
+	require.NoError(t, callRateLimiter(server, ctx, pb.SimpleBank_CreateTransfer_FullMethodName, &pb.CreateTransferRequest{}))
+	requireRateLimited(t, callRateLimiter(server, ctx, pb.SimpleBank_CreateTransfer_FullMethodName, &pb.CreateTransferRequest{}))
+
+	// methods without a limit are never limited
+	for i := 0; i < 5; i++ {
+		require.NoError(t, callRateLimiter(server, ctx, pb.SimpleBank_ListAccounts_FullMethodName, &pb.ListAccountsRequest{}))
+	}
+}
+
+// gatewayAddr is the address of the in-memory connection of the gateway
+type gatewayAddr struct{}
+
+func (gatewayAddr) Network() string { return gatewayNetwork }
+func (gatewayAddr) String() string  { return gatewayNetwork }
+
+// gatewayRateLimitKeys sends the request through HttpClientIP and the gateway annotation, like main does
+func gatewayRateLimitKeys(t *testing.T, server *Server, trustedProxies int, remoteAddr string, forwardedFor string) []string {
+	var keys []string
+	handler := HttpClientIP(trustedProxies)(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ctx, err := runtime.AnnotateContext(req.Context(), runtime.NewServeMux(), req, pb.SimpleBank_LoginUser_FullMethodName)
+		require.NoError(t, err)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		ctx = peer.NewContext(metadata.NewIncomingContext(ctx, md), &peer.Peer{Addr: gatewayAddr{}})
+		keys = server.rateLimitKeys(ctx, "login_user", &pb.LoginUserRequest{Username: "alice"})
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/login_user", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	return keys
+}
+
+func TestServer_RateLimitKeysGatewayClientIP(t *testing.T) {
+	server := newRateLimitedTestServer(t, nil)
+	want := []string{"login_user:ip:203.0.113.7", "login_user:user:alice"}
+
+	// a client can't get a fresh bucket by sending another x-forwarded-for
+	for _, spoofed := range []string{"", "10.0.0.1", "10.0.0.2, 10.0.0.3", "not an ip"} {
+		require.Equal(t, want, gatewayRateLimitKeys(t, server, 0, "203.0.113.7:50000", spoofed))
+	}
+
+	// behind a trusted proxy the hop it appended is the client, the ones before it are still ignored
+	for _, spoofed := range []string{"203.0.113.7", "10.0.0.1, 203.0.113.7"} {
+		require.Equal(t, want, gatewayRateLimitKeys(t, server, 1, "192.168.0.10:40000", spoofed))
+	}
+	require.Equal(t, []string{"login_user:ip:192.168.0.10", "login_user:user:alice"},
+		gatewayRateLimitKeys(t, server, 1, "192.168.0.10:40000", ""))
+}
+
+func TestMethodRateLimits(t *testing.T) {
+	config := util.Config{}
+	config.RateLimit.Methods = rateLimitConfig{"LoginUser": {Requests: 5, Window: time.Minute}}
+	limits, err := methodRateLimits(config)
+	require.NoError(t, err)
+	require.Equal(t, ratelimit.Limit{Requests: 5, Window: time.Minute}, limits[pb.SimpleBank_LoginUser_FullMethodName])
+
+	config.RateLimit.Methods = rateLimitConfig{"login_users": {Requests: 5, Window: time.Minute}}
+	_, err = methodRateLimits(config)
+	require.Error(t, err)
+
+	config.RateLimit.Methods = rateLimitConfig{"login_user": {Requests: 0, Window: time.Minute}}
+	_, err = methodRateLimits(config)
+	require.Error(t, err)
+
+	// the limits shipped in app.yml all name served methods
+	config, err = util.LoadConfig("..")
+	require.NoError(t, err)
+	limits, err = methodRateLimits(config)
+	require.NoError(t, err)
+	require.Contains(t, limits, pb.SimpleBank_RenewAccessToken_FullMethodName)
+}
+
+func randomIP() string {
+	return fmt.Sprintf("10.%d.%d.%d", util.RandomInt(0, 255), util.RandomInt(0, 255), util.RandomInt(1, 254))
+}
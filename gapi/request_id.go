@@ -0,0 +1,61 @@
+package gapi
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+)
+
+const requestIDHeader = "x-request-id"
+
+// requestIDFromMetadata accepts the request id set by the gateway or a gRPC client, or generates one
+func requestIDFromMetadata(ctx context.Context) string {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	return util.RequestIDOrNew(requestID)
+}
+
+// GrpcRequestID stores the request id in the context and returns it in the response header,
+// it has to run first so every log of the request carries the id
+func GrpcRequestID(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	requestID := requestIDFromMetadata(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID))
+
+	return handler(util.WithRequestID(ctx, requestID), req)
+}
+
+// GrpcStreamRequestID does the same as GrpcRequestID for streaming calls
+func GrpcStreamRequestID(
+	srv any,
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	requestID := requestIDFromMetadata(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(requestIDHeader, requestID))
+
+	ctx := util.WithRequestID(ss.Context(), requestID)
+	return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// HttpRequestID accepts or generates the request id at the edge, the gateway forwards it to the grpc server
+func HttpRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requestID := util.RequestIDOrNew(req.Header.Get(requestIDHeader))
+		req.Header.Set(requestIDHeader, requestID)
+		res.Header().Set(requestIDHeader, requestID)
+
+		handler.ServeHTTP(res, req.WithContext(util.WithRequestID(req.Context(), requestID)))
+	})
+}
@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGrpcRequestID(t *testing.T) {
+	tests := []struct {
+		name  string
+		md    metadata.MD
+		check func(t *testing.T, requestID string)
+	}{
+		{
+			name: "Accepted",
+			md:   metadata.Pairs(requestIDHeader, "client-request-id"),
+			check: func(t *testing.T, requestID string) {
+				require.Equal(t, "client-request-id", requestID)
+			},
+		},
+		{
+			name: "Generated",
+			md:   metadata.MD{},
+			check: func(t *testing.T, requestID string) {
+				_, err := uuid.Parse(requestID)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "InvalidReplaced",
+			md:   metadata.Pairs(requestIDHeader, "not a valid id"),
+			check: func(t *testing.T, requestID string) {
+				_, err := uuid.Parse(requestID)
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			var requestID string
+			_, err := GrpcRequestID(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+				requestID = util.RequestIDFromContext(ctx)
+				return nil, nil
+			})
+			require.NoError(t, err)
+			tt.check(t, requestID)
+		})
+	}
+}
+
+func TestHttpRequestID(t *testing.T) {
+	var requestID, forwarded string
+	handler := HttpRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = util.RequestIDFromContext(r.Context())
+		forwarded = r.Header.Get(requestIDHeader)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	request.Header.Set("X-Request-ID", "client-request-id")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, "client-request-id", requestID)
+	require.Equal(t, "client-request-id", forwarded)
+	require.Equal(t, "client-request-id", recorder.Header().Get("X-Request-ID"))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/accounts", nil))
+
+	_, err := uuid.Parse(requestID)
+	require.NoError(t, err)
+	require.Equal(t, requestID, forwarded)
+	require.Equal(t, requestID, recorder.Header().Get("X-Request-ID"))
+}
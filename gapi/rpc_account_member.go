@@ -0,0 +1,184 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/hibiken/asynq"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/val"
+	"github.com/mariobasic/simplebank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// ListAccountMembers members of an account can see who else has access to it
+func (s *Server) ListAccountMembers(ctx context.Context, req *pb.ListAccountMembersRequest) (*pb.ListAccountMembersResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := val.ValidateAccountID(req.GetAccountId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("account_id", err)})
+	}
+
+	account, err := s.getMemberAccount(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeAccountMember(ctx, account, authPayload.Username, accountViewRoles); err != nil {
+		return nil, err
+	}
+
+	members, err := s.store.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list account members: %s", err)
+	}
+
+	return &pb.ListAccountMembersResponse{Members: convertAccountMembers(members)}, nil
+}
+
+// AddAccountMember an account owner can invite another user to the account, the invitee is notified by email
+func (s *Server) AddAccountMember(ctx context.Context, req *pb.AddAccountMemberRequest) (*pb.AddAccountMemberResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAddAccountMemberRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := s.getMemberAccount(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeAccountMember(ctx, account, authPayload.Username, accountManageRoles); err != nil {
+		return nil, err
+	}
+
+	arg := db.AddAccountMemberTxParams{
+		CreateAccountMemberParams: db.CreateAccountMemberParams{
+			AccountID: account.ID,
+			Username:  req.GetUsername(),
+			Role:      req.GetRole(),
+			InvitedBy: authPayload.Username,
+		},
+		AfterCreate: func(member db.AccountMember) error {
+			taskPayload := &worker.PayloadSendAccountInvitation{AccountID: member.AccountID, Username: member.Username}
+			opts := []asynq.Option{
+				asynq.MaxRetry(10),
+				asynq.ProcessIn(10 * time.Second),
+				asynq.Queue(worker.QueueDefault),
+			}
+
+			return s.taskDistributor.DistributeTaskSendAccountInvitation(ctx, taskPayload, opts...)
+		},
+	}
+
+	result, err := s.store.AddAccountMemberTx(ctx, arg)
+	if err != nil {
+		switch db.ErrorCode(err) {
+		case db.UniqueViolationCode:
+			return nil, status.Errorf(codes.AlreadyExists, "user is already a member of the account")
+		case db.ForeignKeyViolationCode:
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to add account member: %s", err)
+	}
+
+	return &pb.AddAccountMemberResponse{Member: convertAccountMember(result.Member)}, nil
+}
+
+// RemoveAccountMember an account owner can remove any member except the owner the account was opened by
+func (s *Server) RemoveAccountMember(ctx context.Context, req *pb.RemoveAccountMemberRequest) (*pb.RemoveAccountMemberResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateRemoveAccountMemberRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := s.getMemberAccount(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeAccountMember(ctx, account, authPayload.Username, accountManageRoles); err != nil {
+		return nil, err
+	}
+
+	if req.GetUsername() == account.Owner {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot remove the primary owner of the account")
+	}
+
+	deleted, err := s.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{AccountID: account.ID, Username: req.GetUsername()})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove account member: %s", err)
+	}
+	if deleted == 0 {
+		return nil, status.Errorf(codes.NotFound, "user is not a member of the account")
+	}
+
+	return &pb.RemoveAccountMemberResponse{}, nil
+}
+
+// getAccount returns the account or the status error of the lookup
+func (s *Server) getAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, status.Errorf(codes.NotFound, "account not found")
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	return account, nil
+}
+
+// getMemberAccount returns the account whose members are managed, pockets have no members of their own
+func (s *Server) getMemberAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.ParentID.Valid {
+		return account, status.Errorf(codes.FailedPrecondition, "pockets have no members of their own, use account %d", account.ParentID.Int64)
+	}
+
+	return account, nil
+}
+
+func validateAddAccountMemberRequest(req *pb.AddAccountMemberRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+	if err := val.ValidateAccountRole(req.GetRole()); err != nil {
+		violations = append(violations, fieldViolation("role", err))
+	}
+
+	return violations
+}
+
+func validateRemoveAccountMemberRequest(req *pb.RemoveAccountMemberRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	return violations
+}
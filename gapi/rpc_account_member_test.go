@@ -0,0 +1,407 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/worker"
+	mockwk "github.com/mariobasic/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type eqAddAccountMemberTxParamsMatcher struct {
+	arg db.AddAccountMemberTxParams
+}
+
+func (e eqAddAccountMemberTxParamsMatcher) Matches(x any) bool {
+	actualArg, ok := x.(db.AddAccountMemberTxParams)
+	if !ok {
+		return false
+	}
+
+	if !reflect.DeepEqual(e.arg.CreateAccountMemberParams, actualArg.CreateAccountMemberParams) {
+		return false
+	}
+
+	err := actualArg.AfterCreate(db.AccountMember{
+		AccountID: actualArg.AccountID,
+		Username:  actualArg.Username,
+		Role:      actualArg.Role,
+		InvitedBy: actualArg.InvitedBy,
+	})
+	return err == nil
+}
+
+func (e eqAddAccountMemberTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v", e.arg)
+}
+
+func EqAddAccountMemberTxParams(arg db.AddAccountMemberTxParams) gomock.Matcher {
+	return eqAddAccountMemberTxParamsMatcher{arg}
+}
+
+func TestServer_ListAccountMembers(t *testing.T) {
+	owner, _ := randomUser(t)
+	other, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	pocket := randomPocket(account)
+	members := []db.AccountMember{
+		randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole),
+		randomAccountMember(account.ID, other.Username, util.AccountViewerRole),
+	}
+
+	tests := []struct {
+		name           string
+		body           *pb.ListAccountMembersRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.ListAccountMembersResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ListAccountMembersRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: other.Username})).
+					Times(1).
+					Return(members[1], nil)
+				store.EXPECT().ListAccountMembers(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(members, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, other.Username, other.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountMembersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, r.GetMembers(), 2)
+				require.Equal(t, owner.Username, r.GetMembers()[0].GetUsername())
+				require.Equal(t, util.AccountViewerRole, r.GetMembers()[1].GetRole())
+			},
+		},
+		{
+			name: "NotMember",
+			body: &pb.ListAccountMembersRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, db.ErrRecordNotFound)
+				store.EXPECT().ListAccountMembers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, other.Username, other.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountMembersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "Pocket",
+			body: &pb.ListAccountMembersRequest{AccountId: pocket.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().ListAccountMembers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, owner.Username, owner.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountMembersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: &pb.ListAccountMembersRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().ListAccountMembers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, owner.Username, owner.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountMembersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.ListAccountMembersRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountMembers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountMembersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ListAccountMembers_FullMethodName, tt.body, server.ListAccountMembers)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_AddAccountMember(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	tests := []struct {
+		name           string
+		body           *pb.AddAccountMemberRequest
+		buildStubs     func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor)
+		checkResponses func(*testing.T, *pb.AddAccountMemberResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.AddAccountMemberRequest{AccountId: account.ID, Username: invitee.Username, Role: util.AccountSignerRole},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: owner.Username})).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+
+				arg := db.AddAccountMemberTxParams{
+					CreateAccountMemberParams: db.CreateAccountMemberParams{
+						AccountID: account.ID,
+						Username:  invitee.Username,
+						Role:      util.AccountSignerRole,
+						InvitedBy: owner.Username,
+					},
+				}
+				store.EXPECT().
+					AddAccountMemberTx(gomock.Any(), EqAddAccountMemberTxParams(arg)).
+					Times(1).
+					Return(db.AddAccountMemberTxResult{Member: randomAccountMember(account.ID, invitee.Username, util.AccountSignerRole)}, nil)
+
+				taskPayload := &worker.PayloadSendAccountInvitation{AccountID: account.ID, Username: invitee.Username}
+				tskDist.EXPECT().
+					DistributeTaskSendAccountInvitation(gomock.Any(), taskPayload, gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponses: func(t *testing.T, r *pb.AddAccountMemberResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, invitee.Username, r.GetMember().GetUsername())
+				require.Equal(t, util.AccountSignerRole, r.GetMember().GetRole())
+			},
+		},
+		{
+			name: "NotAnOwner",
+			body: &pb.AddAccountMemberRequest{AccountId: account.ID, Username: invitee.Username, Role: util.AccountViewerRole},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountSignerRole), nil)
+				store.EXPECT().AddAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+				tskDist.EXPECT().DistributeTaskSendAccountInvitation(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.AddAccountMemberResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyMember",
+			body: &pb.AddAccountMemberRequest{AccountId: account.ID, Username: invitee.Username, Role: util.AccountViewerRole},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					AddAccountMemberTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AddAccountMemberTxResult{}, &pgconn.PgError{Code: db.UniqueViolationCode})
+			},
+			checkResponses: func(t *testing.T, r *pb.AddAccountMemberResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: &pb.AddAccountMemberRequest{AccountId: account.ID, Username: invitee.Username, Role: util.AccountViewerRole},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					AddAccountMemberTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AddAccountMemberTxResult{}, &pgconn.PgError{Code: db.ForeignKeyViolationCode})
+			},
+			checkResponses: func(t *testing.T, r *pb.AddAccountMemberResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidRole",
+			body: &pb.AddAccountMemberRequest{AccountId: account.ID, Username: invitee.Username, Role: util.BankerRole},
+			buildStubs: func(store *mockdb.MockStore, tskDist *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AddAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.AddAccountMemberResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tskCtrl := gomock.NewController(t)
+			tskDist := mockwk.NewMockTaskDistributor(tskCtrl)
+
+			tt.buildStubs(store, tskDist)
+
+			server := NewTestServer(t, store, tskDist)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, owner.Username, owner.Role, time.Minute)
+			res, err := callUnary(server, ctx, pb.SimpleBank_AddAccountMember_FullMethodName, tt.body, server.AddAccountMember)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_RemoveAccountMember(t *testing.T) {
+	owner, _ := randomUser(t)
+	member, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	tests := []struct {
+		name           string
+		body           *pb.RemoveAccountMemberRequest
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponses func(*testing.T, *pb.RemoveAccountMemberResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.RemoveAccountMemberRequest{AccountId: account.ID, Username: member.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: member.Username})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponses: func(t *testing.T, r *pb.RemoveAccountMemberResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "NotMember",
+			body: &pb.RemoveAccountMemberRequest{AccountId: account.ID, Username: member.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			},
+			checkResponses: func(t *testing.T, r *pb.RemoveAccountMemberResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "PrimaryOwner",
+			body: &pb.RemoveAccountMemberRequest{AccountId: account.ID, Username: owner.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.RemoveAccountMemberResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.RemoveAccountMemberRequest{AccountId: account.ID, Username: member.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, owner.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponses: func(t *testing.T, r *pb.RemoveAccountMemberResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, owner.Username, owner.Role, time.Minute)
+			res, err := callUnary(server, ctx, pb.SimpleBank_RemoveAccountMember_FullMethodName, tt.body, server.RemoveAccountMember)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func randomAccountMember(accountID int64, username string, role string) db.AccountMember {
+	return db.AccountMember{
+		AccountID: accountID,
+		Username:  username,
+		Role:      role,
+		InvitedBy: username,
+	}
+}
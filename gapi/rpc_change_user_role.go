@@ -0,0 +1,48 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// ChangeUserRole revokes the sessions of the user as well, their tokens still carry the old role
+func (s *Server) ChangeUserRole(ctx context.Context, req *pb.ChangeUserRoleRequest) (*pb.ChangeUserRoleResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateChangeUserRoleRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.adminUpdateUser(ctx, db.AdminUpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username: req.GetUsername(),
+			Role:     pgtype.Text{String: req.GetRole(), Valid: true},
+		},
+		Banker:        authPayload.Username,
+		Action:        util.AuditChangeRole,
+		Details:       "role=" + req.GetRole(),
+		BlockSessions: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ChangeUserRoleResponse{User: convertUser(result.User), RevokedSessions: result.BlockedSessions}, nil
+}
+
+func validateChangeUserRoleRequest(req *pb.ChangeUserRoleRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validateAdminUsername(req.GetUsername())
+	if err := val.ValidateRole(req.GetRole()); err != nil {
+		violations = append(violations, fieldViolation("role", err))
+	}
+	return violations
+}
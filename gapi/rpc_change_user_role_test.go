@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_ChangeUserRole(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	user, _ := randomUser(t)
+	promoted := user
+	promoted.Role = util.BankerRole
+
+	tests := []struct {
+		name           string
+		body           *pb.ChangeUserRoleRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.ChangeUserRoleResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ChangeUserRoleRequest{Username: user.Username, Role: util.BankerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminUpdateUserTxParams{
+					UpdateUserParams: db.UpdateUserParams{
+						Username: user.Username,
+						Role:     pgtype.Text{String: util.BankerRole, Valid: true},
+					},
+					Banker:        banker.Username,
+					Action:        util.AuditChangeRole,
+					Details:       "role=" + util.BankerRole,
+					BlockSessions: true,
+				}
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AdminUpdateUserTxResult{User: promoted, BlockedSessions: 1}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ChangeUserRoleResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.BankerRole, r.GetUser().GetRole())
+				require.Equal(t, int64(1), r.GetRevokedSessions())
+			},
+		},
+		{
+			name: "InvalidRole",
+			body: &pb.ChangeUserRoleRequest{Username: user.Username, Role: "admin"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdminUpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ChangeUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "OwnUser",
+			body: &pb.ChangeUserRoleRequest{Username: banker.Username, Role: util.DepositorRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdminUpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ChangeUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "DepositorNotAllowed",
+			body: &pb.ChangeUserRoleRequest{Username: user.Username, Role: util.BankerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdminUpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ChangeUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ChangeUserRole_FullMethodName, tt.body, server.ChangeUserRole)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
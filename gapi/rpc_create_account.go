@@ -0,0 +1,45 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateAccountRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.store.CreateAccountTx(ctx, db.CreateAccountParams{
+		Owner:    authPayload.Username,
+		Balance:  0,
+		Currency: req.GetCurrency(),
+	})
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolationCode {
+			return nil, status.Errorf(codes.AlreadyExists, "user already has a %s account", req.GetCurrency())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(result.Account)}, nil
+}
+
+func validateCreateAccountRequest(req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	return violations
+}
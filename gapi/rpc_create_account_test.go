@@ -0,0 +1,114 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_CreateAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	tests := []struct {
+		name           string
+		body           *pb.CreateAccountRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.CreateAccountResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.CreateAccountRequest{Currency: account.Currency},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{Owner: user.Username, Balance: 0, Currency: account.Currency}
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateAccountTxResult{
+						Account: account,
+						Member:  db.AccountMember{AccountID: account.ID, Username: user.Username, Role: util.AccountOwnerRole},
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, r)
+				require.Equal(t, account.ID, r.GetAccount().GetId())
+				require.Equal(t, user.Username, r.GetAccount().GetOwner())
+				require.Equal(t, account.Currency, r.GetAccount().GetCurrency())
+			},
+		},
+		{
+			name: "DuplicateCurrency",
+			body: &pb.CreateAccountRequest{Currency: account.Currency},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, db.ErrUniqueViolation)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			body: &pb.CreateAccountRequest{Currency: "XYZ"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.CreateAccountRequest{Currency: account.Currency},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_CreateAccount_FullMethodName, tt.body, server.CreateAccount)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateTransfer a logged-in user can only send money from accounts they own or can sign for.
+// A transfer above the step-up threshold first returns a challenge, it is posted when sent again with the emailed code
+func (s *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateTransferRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency(), "from_account_id")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeAccountMember(ctx, fromAccount, authPayload.Username, accountTransferRoles); err != nil {
+		return nil, err
+	}
+
+	if _, err = s.validAccount(ctx, req.GetToAccountId(), req.GetCurrency(), "to_account_id"); err != nil {
+		return nil, err
+	}
+
+	// fast path only, TransferTx checks the balance again on the locked row
+	if fromAccount.Balance < req.GetAmount() {
+		return nil, insufficientFundsError(fromAccount.ID)
+	}
+
+	var challengeID int64
+	if s.transferNeedsStepUp(req.GetAmount()) {
+		if req.GetChallengeId() == 0 {
+			return s.startTransferChallenge(ctx, authPayload.Username, req)
+		}
+		if err = s.checkTransferChallenge(ctx, authPayload.Username, req); err != nil {
+			return nil, err
+		}
+		challengeID = req.GetChallengeId()
+	}
+
+	result, err := s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		ChallengeID:   challengeID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, insufficientFundsError(fromAccount.ID)
+		}
+		if errors.Is(err, db.ErrChallengeUsed) {
+			return nil, status.Error(codes.PermissionDenied, "challenge is already used")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer money: %s", err)
+	}
+	metrics.TransfersPosted.WithLabelValues(fromAccount.Currency).Inc()
+	metrics.TransferredAmount.WithLabelValues(fromAccount.Currency).Add(float64(result.Transfer.Amount))
+
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}, nil
+}
+
+// validAccount returns the account when it exists and holds the given currency
+func (s *Server) validAccount(ctx context.Context, accountID int64, currency string, field string) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, status.Errorf(codes.NotFound, "account %d not found", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Currency != currency {
+		err := fmt.Errorf("account %d holds %s, not %s", accountID, account.Currency, currency)
+		return account, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation(field, err)})
+	}
+
+	return account, nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := val.ValidateAccountID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	} else if req.GetToAccountId() == req.GetFromAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("must differ from from_account_id")))
+	}
+
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if req.GetChallengeId() < 0 {
+		violations = append(violations, fieldViolation("challenge_id", errors.New("must be a positive integer")))
+	} else if req.GetChallengeId() > 0 {
+		if err := val.ValidateStepUpCode(req.GetCode()); err != nil {
+			violations = append(violations, fieldViolation("code", err))
+		}
+	} else if req.GetCode() != "" {
+		violations = append(violations, fieldViolation("challenge_id", errors.New("must be set with code")))
+	}
+
+	return violations
+}
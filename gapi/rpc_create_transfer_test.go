@@ -0,0 +1,499 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/worker"
+	mockwk "github.com/mariobasic/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_CreateTransfer(t *testing.T) {
+	amount := int64(10)
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account3.Currency = util.EUR
+	account1.Balance = 100
+
+	ownerMember := db.AccountMember{AccountID: account1.ID, Username: user1.Username, Role: util.AccountOwnerRole}
+
+	tests := []struct {
+		name           string
+		body           *pb.CreateTransferRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.CreateTransferResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, Username: user1.Username})).
+					Times(1).
+					Return(ownerMember, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount}
+				fromAccount := account1
+				fromAccount.Balance -= amount
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount},
+						FromAccount: fromAccount,
+						ToAccount:   account2,
+						FromEntry:   db.Entry{ID: 1, AccountID: account1.ID, Amount: -amount},
+						ToEntry:     db.Entry{ID: 2, AccountID: account2.ID, Amount: amount},
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, user1.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, amount, r.GetTransfer().GetAmount())
+				require.Equal(t, account1.Balance-amount, r.GetFromAccount().GetBalance())
+				require.Equal(t, -amount, r.GetFromEntry().GetAmount())
+				require.Equal(t, amount, r.GetToEntry().GetAmount())
+			},
+		},
+		{
+			name: "NotAMember",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user2.Username, user2.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ToAccountCurrencyMismatch",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account3.ID, Amount: amount, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(ownerMember, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, user1.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolation(t, err, "to_account_id")
+			},
+		},
+		{
+			name: "FromAccountNotFound",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, user1.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: account1.Balance + 1, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(ownerMember, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, user1.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFundsInTx",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: account1.Balance, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(ownerMember, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, user1.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidRequest",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account1.ID, Amount: -1, Currency: "XYZ"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, user1.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolation(t, err, "to_account_id")
+				requireFieldViolation(t, err, "amount")
+				requireFieldViolation(t, err, "currency")
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_CreateTransfer_FullMethodName, tt.body, server.CreateTransfer)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_CreateTransferStepUp(t *testing.T) {
+	threshold := int64(50)
+	amount := int64(60)
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account1.Balance = 100
+
+	ownerMember := db.AccountMember{AccountID: account1.ID, Username: user1.Username, Role: util.AccountOwnerRole}
+	challenge := db.TransferChallenge{
+		ID:            util.RandomInt(1, 1000),
+		Username:      user1.Username,
+		Email:         user1.Email,
+		SecretCode:    "123456",
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Currency:      util.USD,
+		Attempts:      1,
+		ExpiredAt:     time.Now().Add(time.Minute),
+	}
+
+	validTransfer := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+		store.EXPECT().
+			GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, Username: user1.Username})).
+			Times(1).
+			Return(ownerMember, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	}
+	attempt := func(store *mockdb.MockStore, challenge db.TransferChallenge, err error) {
+		arg := db.AttemptTransferChallengeParams{ID: challenge.ID, Username: user1.Username, MaxAttempts: stepUpMaxAttempts}
+		store.EXPECT().AttemptTransferChallenge(gomock.Any(), gomock.Eq(arg)).Times(1).Return(challenge, err)
+	}
+
+	tests := []struct {
+		name           string
+		body           *pb.CreateTransferRequest
+		buildStubs     func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponses func(*testing.T, *pb.CreateTransferResponse, error)
+	}{
+		{
+			name: "ChallengeStarted",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().
+					CreateTransferChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateTransferChallengeParams) (db.TransferChallenge, error) {
+						require.Equal(t, user1.Email, arg.Email)
+						require.Regexp(t, `^[0-9]{6}$`, arg.SecretCode)
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, account2.ID, arg.ToAccountID)
+						require.Equal(t, amount, arg.Amount)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiredAt, time.Second)
+						return challenge, nil
+					})
+				distributor.EXPECT().
+					DistributeTaskSendTransferCode(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, payload *worker.PayloadSendTransferCode, _ ...any) error {
+						require.Equal(t, challenge.ID, payload.ChallengeID)
+						return nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, r.GetStepUpRequired())
+				require.Equal(t, challenge.ID, r.GetChallengeId())
+				require.NotNil(t, r.GetChallengeExpiresAt())
+				require.Nil(t, r.GetTransfer())
+			},
+		},
+		{
+			name: "OK",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD, ChallengeId: challenge.ID, Code: "123456"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				attempt(store, challenge, nil)
+				arg := db.TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount, ChallengeID: challenge.ID}
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount}}, nil)
+				distributor.EXPECT().DistributeTaskSendTransferCode(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, r.GetStepUpRequired())
+				require.Equal(t, amount, r.GetTransfer().GetAmount())
+			},
+		},
+		{
+			name: "BelowThreshold",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: threshold, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				store.EXPECT().CreateTransferChallenge(gomock.Any(), gomock.Any()).Times(0)
+				arg := db.TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: threshold}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, r.GetStepUpRequired())
+			},
+		},
+		{
+			name: "WrongCode",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD, ChallengeId: challenge.ID, Code: "654321"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				attempt(store, challenge, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ChallengeForAnotherTransfer",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount + 1, Currency: util.USD, ChallengeId: challenge.ID, Code: "123456"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				attempt(store, challenge, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ChallengeExpired",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD, ChallengeId: challenge.ID, Code: "123456"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				attempt(store, db.TransferChallenge{ID: challenge.ID}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ChallengeUsedConcurrently",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD, ChallengeId: challenge.ID, Code: "123456"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				attempt(store, challenge, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrChallengeUsed)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "DistributeError",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				validTransfer(store)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user1, nil)
+				store.EXPECT().CreateTransferChallenge(gomock.Any(), gomock.Any()).Times(1).Return(challenge, nil)
+				distributor.EXPECT().
+					DistributeTaskSendTransferCode(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(context.DeadlineExceeded)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD, ChallengeId: challenge.ID, Code: "12"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				requireFieldViolations(t, err, "code")
+			},
+		},
+		{
+			name: "CodeWithoutChallenge",
+			body: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD, Code: "123456"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreateTransferResponse, err error) {
+				requireFieldViolations(t, err, "challenge_id")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+
+			tt.buildStubs(store, distributor)
+
+			server := newStepUpTestServer(t, store, distributor, threshold)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user1.Username, user1.Role, time.Minute)
+			res, err := callUnary(server, ctx, pb.SimpleBank_CreateTransfer_FullMethodName, tt.body, server.CreateTransfer)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_CreateTransferStepUpCodeLimit(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account1.Balance = 100
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	stubSessions(store)
+	distributor := mockwk.NewMockTaskDistributor(ctrl)
+
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).AnyTimes().Return(account1, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).AnyTimes().Return(account2, nil)
+	store.EXPECT().
+		GetAccountMember(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.AccountMember{AccountID: account1.ID, Username: user1.Username, Role: util.AccountOwnerRole}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(2).Return(user1, nil)
+	store.EXPECT().CreateTransferChallenge(gomock.Any(), gomock.Any()).Times(2).Return(db.TransferChallenge{ID: 1}, nil)
+	distributor.EXPECT().DistributeTaskSendTransferCode(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).Return(nil)
+
+	server := newStepUpTestServer(t, store, distributor, 50)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user1.Username, user1.Role, time.Minute)
+	req := &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 60, Currency: util.USD}
+
+	for range 2 {
+		res, err := callUnary(server, ctx, pb.SimpleBank_CreateTransfer_FullMethodName, req, server.CreateTransfer)
+		require.NoError(t, err)
+		require.True(t, res.GetStepUpRequired())
+	}
+
+	_, err := callUnary(server, ctx, pb.SimpleBank_CreateTransfer_FullMethodName, req, server.CreateTransfer)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// newStepUpTestServer requires an emailed code for transfers above the threshold, two codes per minute can be sent
+func newStepUpTestServer(t *testing.T, store db.Store, distributor worker.TaskDistributor, threshold int64) *Server {
+	server := NewTestServer(t, store, distributor)
+	server.config.StepUp.TransferThreshold = threshold
+	server.config.StepUp.CodeDuration = time.Minute
+	server.config.StepUp.MaxCodes = 2
+	server.config.StepUp.CodeWindow = time.Minute
+	return server
+}
+
+func requireFieldViolation(t *testing.T, err error, field string) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				if violation.GetField() == field {
+					return
+				}
+			}
+		}
+	}
+	require.Failf(t, "missing field violation", "no violation for field %s", field)
+}
@@ -0,0 +1,19 @@
+package gapi
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+)
+
+func (s *Server) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	result, err := s.cashOperation(ctx, util.DepositOperation, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DepositResponse{
+		Operation: convertCashOperation(result.CashOperation),
+		Account:   convertAccount(result.Account),
+	}, nil
+}
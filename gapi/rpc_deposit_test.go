@@ -0,0 +1,188 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_Deposit(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	depositor, _ := randomUser(t)
+	account := randomAccount(depositor.Username)
+	amount := util.RandomMoney()
+	reference := util.RandomString(12)
+
+	tests := []struct {
+		name           string
+		body           *pb.DepositRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.DepositResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.DepositRequest{
+				AccountId: account.ID,
+				Amount:    amount,
+				Reference: reference,
+				Memo:      "counter deposit",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CashOperationTxParams{
+					Type:      util.DepositOperation,
+					AccountID: account.ID,
+					Amount:    amount,
+					Reference: reference,
+					Memo:      "counter deposit",
+					Banker:    banker.Username,
+				}
+				updated := account
+				updated.Balance += amount
+
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CashOperationTxResult{
+						CashOperation: db.CashOperation{
+							ID:        1,
+							Type:      arg.Type,
+							AccountID: arg.AccountID,
+							Amount:    arg.Amount,
+							Reference: arg.Reference,
+							Memo:      arg.Memo,
+							Banker:    arg.Banker,
+						},
+						Account: updated,
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.DepositResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, r)
+				require.Equal(t, util.DepositOperation, r.GetOperation().GetType())
+				require.Equal(t, banker.Username, r.GetOperation().GetBanker())
+				require.Equal(t, reference, r.GetOperation().GetReference())
+				require.Equal(t, account.Balance+amount, r.GetAccount().GetBalance())
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: &pb.DepositRequest{
+				AccountId: account.ID,
+				Amount:    amount,
+				Reference: reference,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CashOperationTxResult{}, db.ErrRecordNotFound)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.DepositResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "DuplicateReference",
+			body: &pb.DepositRequest{
+				AccountId: account.ID,
+				Amount:    amount,
+				Reference: reference,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CashOperationTxResult{}, db.ErrUniqueViolation)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.DepositResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: &pb.DepositRequest{
+				AccountId: account.ID,
+				Amount:    -amount,
+				Reference: reference,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.DepositResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "DepositorNotAllowed",
+			body: &pb.DepositRequest{
+				AccountId: account.ID,
+				Amount:    amount,
+				Reference: reference,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, depositor.Username, depositor.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.DepositResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_Deposit_FullMethodName, tt.body, server.Deposit)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomMoney(),
+		Currency: util.RandomCurrency(),
+	}
+}
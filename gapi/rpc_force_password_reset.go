@@ -0,0 +1,37 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+)
+
+// ForcePasswordReset logs the user out everywhere, after the next login they can only set a new password or log out
+func (s *Server) ForcePasswordReset(ctx context.Context, req *pb.ForcePasswordResetRequest) (*pb.ForcePasswordResetResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminUsername(req.GetUsername())
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.adminUpdateUser(ctx, db.AdminUpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username:          req.GetUsername(),
+			MustResetPassword: pgtype.Bool{Bool: true, Valid: true},
+		},
+		Banker:        authPayload.Username,
+		Action:        util.AuditForcePasswordReset,
+		BlockSessions: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ForcePasswordResetResponse{User: convertUser(result.User), RevokedSessions: result.BlockedSessions}, nil
+}
@@ -0,0 +1,107 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_ForcePasswordReset(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	user, _ := randomUser(t)
+	reset := user
+	reset.MustResetPassword = true
+
+	tests := []struct {
+		name           string
+		body           *pb.ForcePasswordResetRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.ForcePasswordResetResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ForcePasswordResetRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminUpdateUserTxParams{
+					UpdateUserParams: db.UpdateUserParams{
+						Username:          user.Username,
+						MustResetPassword: pgtype.Bool{Bool: true, Valid: true},
+					},
+					Banker:        banker.Username,
+					Action:        util.AuditForcePasswordReset,
+					BlockSessions: true,
+				}
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AdminUpdateUserTxResult{User: reset, BlockedSessions: 3}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ForcePasswordResetResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, r.GetUser().GetMustResetPassword())
+				require.Equal(t, int64(3), r.GetRevokedSessions())
+			},
+		},
+		{
+			name: "NotFound",
+			body: &pb.ForcePasswordResetRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdminUpdateUserTxResult{}, db.ErrRecordNotFound)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ForcePasswordResetResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.ForcePasswordResetRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdminUpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.ForcePasswordResetResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ForcePasswordReset_FullMethodName, tt.body, server.ForcePasswordReset)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
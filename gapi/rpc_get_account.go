@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetAccount members of an account can view it, bankers and trusted services can view any account
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	authPayload, _, err := authCallerFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateGetAccountRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := s.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if authPayload != nil && authPayload.Role != util.BankerRole {
+		if err = s.authorizeAccountMember(ctx, account, authPayload.Username, accountViewRoles); err != nil {
+			return nil, err
+		}
+	}
+
+	rsp := &pb.GetAccountResponse{Account: convertAccount(account)}
+	if !account.ParentID.Valid {
+		rsp.PocketsBalance, err = s.store.GetPocketsBalance(ctx, account.ID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get pockets balance: %s", err)
+		}
+	}
+
+	return rsp, nil
+}
+
+func validateGetAccountRequest(req *pb.GetAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,163 @@
+package gapi
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_GetAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	account := randomAccount(user.Username)
+
+	tests := []struct {
+		name           string
+		body           *pb.GetAccountRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.GetAccountResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.GetAccountRequest{Id: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: user.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account.ID, Username: user.Username, Role: util.AccountViewerRole}, nil)
+				store.EXPECT().GetPocketsBalance(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(int64(50), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, r.GetAccount().GetId())
+				require.Equal(t, account.Balance, r.GetAccount().GetBalance())
+				require.Equal(t, int64(50), r.GetPocketsBalance())
+			},
+		},
+		{
+			name: "BankerCanViewAnyAccount",
+			body: &pb.GetAccountRequest{Id: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetPocketsBalance(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(int64(0), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, r.GetAccount().GetId())
+			},
+		},
+		{
+			name: "TrustedService",
+			body: &pb.GetAccountRequest{Id: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetPocketsBalance(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(int64(0), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithClientCert(testServiceSubject)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, r.GetAccount().GetId())
+			},
+		},
+		{
+			name: "UntrustedService",
+			body: &pb.GetAccountRequest{Id: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithClientCert(pkix.Name{CommonName: "unknown"})
+			},
+			checkResponses: func(t *testing.T, r *pb.GetAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotAMember",
+			body: &pb.GetAccountRequest{Id: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, db.ErrRecordNotFound)
+				store.EXPECT().GetPocketsBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, other.Username, other.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotFound",
+			body: &pb.GetAccountRequest{Id: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidID",
+			body: &pb.GetAccountRequest{Id: 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_GetAccount_FullMethodName, tt.body, server.GetAccount)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetUser shows a user with the accounts they are a member of
+func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminUsername(req.GetUsername())
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := s.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	if err = s.auditView(ctx, authPayload.Username, util.AuditViewUser, user.Username, ""); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.store.ListMemberAccounts(ctx, db.ListMemberAccountsParams{
+		Username: user.Username,
+		Limit:    adminUserAccountsLimit,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	return &pb.GetUserResponse{User: convertUser(user), Accounts: convertAccounts(accounts)}, nil
+}
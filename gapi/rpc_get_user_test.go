@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_GetUser(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	user, _ := randomUser(t)
+	accounts := []db.Account{randomAccount(user.Username), randomAccount(user.Username)}
+
+	tests := []struct {
+		name           string
+		body           *pb.GetUserRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.GetUserResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.GetUserRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Eq(db.CreateAuditLogParams{
+						Banker: banker.Username,
+						Action: util.AuditViewUser,
+						Target: user.Username,
+					})).
+					Times(1).
+					Return(db.AuditLog{}, nil)
+				arg := db.ListMemberAccountsParams{Username: user.Username, Limit: adminUserAccountsLimit}
+				store.EXPECT().ListMemberAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, r.GetUser().GetUsername())
+				require.Equal(t, user.Role, r.GetUser().GetRole())
+				require.Len(t, r.GetAccounts(), len(accounts))
+			},
+		},
+		{
+			name: "NotFound",
+			body: &pb.GetUserRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidUsername",
+			body: &pb.GetUserRequest{Username: "In-valid"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "DepositorNotAllowed",
+			body: &pb.GetUserRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.GetUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_GetUser_FullMethodName, tt.body, server.GetUser)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAccounts lists the accounts the logged-in user is a member of
+func (s *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListAccountsRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	accounts, err := s.store.ListMemberAccounts(ctx, db.ListMemberAccountsParams{
+		Username: authPayload.Username,
+		Limit:    req.GetPageSize(),
+		Offset:   (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	return &pb.ListAccountsResponse{Accounts: convertAccounts(accounts)}, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidatePageID(req.GetPageId()); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+
+	return violations
+}
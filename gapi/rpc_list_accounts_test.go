@@ -0,0 +1,90 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_ListAccounts(t *testing.T) {
+	user, _ := randomUser(t)
+	accounts := []db.Account{randomAccount(user.Username), randomAccount(user.Username)}
+
+	tests := []struct {
+		name           string
+		body           *pb.ListAccountsRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.ListAccountsResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ListAccountsRequest{PageId: 2, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListMemberAccountsParams{Username: user.Username, Limit: 5, Offset: 5}
+				store.EXPECT().ListMemberAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, r.GetAccounts(), len(accounts))
+				for i, account := range r.GetAccounts() {
+					require.Equal(t, accounts[i].ID, account.GetId())
+				}
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			body: &pb.ListAccountsRequest{PageId: 1, PageSize: 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListMemberAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.ListAccountsRequest{PageId: 1, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListMemberAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.ListAccountsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ListAccounts_FullMethodName, tt.body, server.ListAccounts)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
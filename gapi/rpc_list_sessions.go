@@ -0,0 +1,23 @@
+package gapi
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListSessions lists the active sessions of the logged-in user, most recently used first
+func (s *Server) ListSessions(ctx context.Context, _ *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	sessions, err := s.store.ListUserSessions(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions: %s", err)
+	}
+
+	return &pb.ListSessionsResponse{Sessions: convertSessions(sessions, authPayload.SessionID)}, nil
+}
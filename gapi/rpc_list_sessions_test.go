@@ -0,0 +1,106 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func randomSession(username string) db.Session {
+	return db.Session{
+		ID:         uuid.New(),
+		Username:   username,
+		UserAgent:  "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		ClientIp:   "203.0.113.7",
+		ExpiredAt:  time.Now().Add(time.Hour),
+		CreatedAt:  time.Now().Add(-time.Hour),
+		LastUsedAt: time.Now(),
+	}
+}
+
+func TestServer_ListSessions(t *testing.T) {
+	user, _ := randomUser(t)
+	current := randomSession(user.Username)
+	sessions := []db.Session{current, randomSession(user.Username)}
+
+	tests := []struct {
+		name           string
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.ListSessionsResponse, error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUserSessions(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(sessions, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithSession(t, tokenMaker, current, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListSessionsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, r.GetSessions(), len(sessions))
+				for i, session := range r.GetSessions() {
+					require.Equal(t, sessions[i].ID.String(), session.GetId())
+					require.Equal(t, sessions[i].ClientIp, session.GetClientIp())
+					require.Equal(t, "Safari", session.GetDevice().GetBrowser())
+					require.True(t, session.GetDevice().GetMobile())
+					require.WithinDuration(t, sessions[i].LastUsedAt, session.GetLastUsedAt().AsTime(), time.Second)
+				}
+				require.True(t, r.GetSessions()[0].GetCurrent())
+				require.False(t, r.GetSessions()[1].GetCurrent())
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUserSessions(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListSessionsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.ListSessionsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ListSessions_FullMethodName, &pb.ListSessionsRequest{}, server.ListSessions)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListUsers searches users by username, full name or email, an empty search lists all of them
+func (s *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListUsersRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	details := fmt.Sprintf("search=%q page_id=%d page_size=%d", req.GetSearch(), req.GetPageId(), req.GetPageSize())
+	if err = s.auditView(ctx, authPayload.Username, util.AuditSearchUsers, "", details); err != nil {
+		return nil, err
+	}
+
+	users, err := s.store.ListUsers(ctx, db.ListUsersParams{
+		Search: db.EscapeLike(req.GetSearch()),
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list users: %s", err)
+	}
+
+	return &pb.ListUsersResponse{Users: convertUsers(users)}, nil
+}
+
+func validateListUsersRequest(req *pb.ListUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateSearch(req.GetSearch()); err != nil {
+		violations = append(violations, fieldViolation("search", err))
+	}
+
+	if err := val.ValidatePageID(req.GetPageId()); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_ListUsers(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	users := []db.User{user1, user2}
+
+	tests := []struct {
+		name           string
+		body           *pb.ListUsersRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.ListUsersResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ListUsersRequest{Search: "example", PageId: 2, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Eq(db.CreateAuditLogParams{
+						Banker:  banker.Username,
+						Action:  util.AuditSearchUsers,
+						Details: `search="example" page_id=2 page_size=5`,
+					})).
+					Times(1).
+					Return(db.AuditLog{}, nil)
+				arg := db.ListUsersParams{Search: "example", Limit: 5, Offset: 5}
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(users, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListUsersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, r.GetUsers(), len(users))
+				for i, user := range r.GetUsers() {
+					require.Equal(t, users[i].Username, user.GetUsername())
+				}
+			},
+		},
+		{
+			name: "EscapesWildcards",
+			body: &pb.ListUsersRequest{Search: "100%_", PageId: 1, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, nil)
+				arg := db.ListUsersParams{Search: `100\%\_`, Limit: 5}
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.User{}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListUsersResponse, err error) {
+				require.NoError(t, err)
+				require.Empty(t, r.GetUsers())
+			},
+		},
+		{
+			name: "AuditLogError",
+			body: &pb.ListUsersRequest{PageId: 1, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, sql.ErrConnDone)
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListUsersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			body: &pb.ListUsersRequest{PageId: 1, PageSize: 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListUsersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "DepositorNotAllowed",
+			body: &pb.ListUsersRequest{PageId: 1, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, user1.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListUsersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ListUsers_FullMethodName, tt.body, server.ListUsers)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// LockUser stops a user from logging in and revokes all of their sessions
+func (s *Server) LockUser(ctx context.Context, req *pb.LockUserRequest) (*pb.LockUserResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateLockRequest(req.GetUsername(), req.GetReason())
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.adminUpdateUser(ctx, db.AdminUpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username: req.GetUsername(),
+			IsLocked: pgtype.Bool{Bool: true, Valid: true},
+		},
+		Banker:        authPayload.Username,
+		Action:        util.AuditLockUser,
+		Details:       req.GetReason(),
+		BlockSessions: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.LockUserResponse{User: convertUser(result.User), RevokedSessions: result.BlockedSessions}, nil
+}
+
+// UnlockUser lets a locked user log in again
+func (s *Server) UnlockUser(ctx context.Context, req *pb.UnlockUserRequest) (*pb.UnlockUserResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateLockRequest(req.GetUsername(), req.GetReason())
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.adminUpdateUser(ctx, db.AdminUpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username: req.GetUsername(),
+			IsLocked: pgtype.Bool{Bool: false, Valid: true},
+		},
+		Banker:  authPayload.Username,
+		Action:  util.AuditUnlockUser,
+		Details: req.GetReason(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UnlockUserResponse{User: convertUser(result.User)}, nil
+}
+
+func validateLockRequest(username, reason string) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validateAdminUsername(username)
+	if err := val.ValidateMemo(reason); err != nil {
+		violations = append(violations, fieldViolation("reason", err))
+	}
+	return violations
+}
@@ -0,0 +1,210 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_LockUser(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	user, _ := randomUser(t)
+	locked := user
+	locked.IsLocked = true
+
+	tests := []struct {
+		name           string
+		body           *pb.LockUserRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.LockUserResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.LockUserRequest{Username: user.Username, Reason: "fraud investigation"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminUpdateUserTxParams{
+					UpdateUserParams: db.UpdateUserParams{
+						Username: user.Username,
+						IsLocked: pgtype.Bool{Bool: true, Valid: true},
+					},
+					Banker:        banker.Username,
+					Action:        util.AuditLockUser,
+					Details:       "fraud investigation",
+					BlockSessions: true,
+				}
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AdminUpdateUserTxResult{User: locked, BlockedSessions: 2}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LockUserResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, r.GetUser().GetIsLocked())
+				require.Equal(t, int64(2), r.GetRevokedSessions())
+			},
+		},
+		{
+			name: "OwnUser",
+			body: &pb.LockUserRequest{Username: banker.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdminUpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LockUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NotFound",
+			body: &pb.LockUserRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdminUpdateUserTxResult{}, db.ErrRecordNotFound)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LockUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.LockUserRequest{Username: user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdminUpdateUserTxResult{}, sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LockUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "DepositorNotAllowed",
+			body: &pb.LockUserRequest{Username: banker.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdminUpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LockUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_LockUser_FullMethodName, tt.body, server.LockUser)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_UnlockUser(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	user, _ := randomUser(t)
+
+	tests := []struct {
+		name           string
+		body           *pb.UnlockUserRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.UnlockUserResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.UnlockUserRequest{Username: user.Username, Reason: "cleared"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminUpdateUserTxParams{
+					UpdateUserParams: db.UpdateUserParams{
+						Username: user.Username,
+						IsLocked: pgtype.Bool{Bool: false, Valid: true},
+					},
+					Banker:  banker.Username,
+					Action:  util.AuditUnlockUser,
+					Details: "cleared",
+				}
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AdminUpdateUserTxResult{User: user}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UnlockUserResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, r.GetUser().GetIsLocked())
+			},
+		},
+		{
+			name: "InvalidUsername",
+			body: &pb.UnlockUserRequest{Username: "In-valid"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdminUpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UnlockUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_UnlockUser_FullMethodName, tt.body, server.UnlockUser)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
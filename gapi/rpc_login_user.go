@@ -3,14 +3,22 @@ package gapi
 import (
 	"context"
 	"errors"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
 	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/mariobasic/simplebank/mfa"
 	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/mariobasic/simplebank/val"
+	"github.com/mariobasic/simplebank/worker"
+	"github.com/rs/zerolog/log"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
 func (s *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
@@ -18,9 +26,11 @@ func (s *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.L
 	if len(violations) > 0 {
 		return nil, invalidArgumentError(violations)
 	}
-	user, err := s.store.GetUser(ctx, req.GetUsername())
+	// a lagging replica could still accept a password just changed or a user just locked
+	user, err := s.store.GetUser(db.WithPrimary(ctx), req.GetUsername())
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
+			metrics.Logins.WithLabelValues(metrics.LoginFailed).Inc()
 			return nil, status.Error(codes.NotFound, "user not found")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
@@ -28,40 +38,130 @@ func (s *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.L
 
 	err = util.CheckPassword(req.GetPassword(), user.HashedPassword)
 	if err != nil {
+		metrics.Logins.WithLabelValues(metrics.LoginFailed).Inc()
 		return nil, status.Errorf(codes.NotFound, "incorrect password: %s", err)
 	}
 
-	token, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, s.config.Token.AccessDuration)
+	if user.IsLocked {
+		metrics.Logins.WithLabelValues(metrics.LoginFailed).Inc()
+		return nil, status.Error(codes.PermissionDenied, "user is locked")
+	}
+
+	totp, err := s.store.GetTotpSecret(ctx, user.Username)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.Internal, "failed to get TOTP secret: %s", err)
+	}
+	if err == nil && totp.IsConfirmed {
+		return s.startMfaChallenge(ctx, user)
+	}
+
+	policy, err := s.store.GetRolePolicy(ctx, user.Role)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.Internal, "failed to get role policy: %s", err)
+	}
+
+	rsp, err := s.startSession(ctx, user, false)
+	if err != nil {
+		return nil, err
+	}
+	rsp.MfaEnrollmentRequired = policy.MfaRequired
+	return rsp, nil
+}
+
+// startMfaChallenge answers a correct password of a user with a second factor, instead of tokens the user
+// gets a short-lived mfa token to exchange for them with a code
+func (s *Server) startMfaChallenge(ctx context.Context, user db.User) (*pb.LoginUserResponse, error) {
+	mfaToken, err := mfa.GenerateChallengeToken()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate mfa token: %s", err)
+	}
+
+	challenge, err := s.store.CreateMfaChallenge(ctx, db.CreateMfaChallengeParams{
+		Username:  user.Username,
+		TokenHash: util.HashToken(mfaToken),
+		ExpiredAt: time.Now().Add(s.config.Token.MfaDuration),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create mfa challenge: %s", err)
+	}
+
+	metrics.Logins.WithLabelValues(metrics.LoginMfaRequired).Inc()
+	return &pb.LoginUserResponse{
+		MfaRequired:       true,
+		MfaToken:          mfaToken,
+		MfaTokenExpiresAt: timestamppb.New(challenge.ExpiredAt),
+	}, nil
+}
+
+// startSession issues the tokens of a login, mfaVerified tells if the user passed a second factor
+func (s *Server) startSession(ctx context.Context, user db.User, mfaVerified bool) (*pb.LoginUserResponse, error) {
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate session id: %s", err)
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeAccess, s.config.Token.AccessDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to generate access token: %s", err)
 	}
 
-	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, s.config.Token.RefreshDuration)
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeRefresh, s.config.Token.RefreshDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to generate refresh token: %s", err)
 	}
 
 	metadata := s.extractMetadata(ctx)
+	history, err := s.store.GetLoginHistory(ctx, db.GetLoginHistoryParams{
+		Username:  user.Username,
+		UserAgent: metadata.UserAgent,
+		ClientIp:  metadata.ClientIP,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get login history: %s", err)
+	}
+
 	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    metadata.UserAgent,
-		ClientIp:     metadata.ClientIP,
-		IsBlocked:    false,
-		ExpiredAt:    refreshPayload.ExpiredAt,
+		ID:               sessionID,
+		Username:         user.Username,
+		RefreshTokenHash: util.HashToken(refreshToken),
+		UserAgent:        metadata.UserAgent,
+		ClientIp:         metadata.ClientIP,
+		IsBlocked:        false,
+		ExpiredAt:        refreshPayload.ExpiredAt,
+		IsMfaVerified:    mfaVerified,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create session: %s", err)
 	}
 
+	// the very first login has nothing to compare with, so it is not reported
+	if history.HasSessions && (!history.KnownDevice || !history.KnownIp) {
+		taskPayload := &worker.PayloadSendLoginAlert{
+			SessionID:   session.ID,
+			NewDevice:   !history.KnownDevice,
+			NewClientIP: !history.KnownIp,
+		}
+		opts := []asynq.Option{
+			asynq.MaxRetry(10),
+			asynq.Queue(worker.QueueCritical),
+		}
+
+		// the login itself succeeded, a missed alert must not lock the user out
+		err = s.taskDistributor.DistributeTaskSendLoginAlert(ctx, taskPayload, opts...)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("session_id", session.ID.String()).Msg("failed to distribute login alert")
+		}
+	}
+
+	metrics.Logins.WithLabelValues(metrics.LoginSucceeded).Inc()
 	return &pb.LoginUserResponse{
 		SessionId:             session.ID.String(),
-		AccessToken:           token,
+		AccessToken:           accessToken,
 		RefreshToken:          refreshToken,
 		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
 		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
 		User:                  convertUser(user),
+		PasswordResetRequired: user.MustResetPassword,
 	}, nil
 }
 
@@ -0,0 +1,342 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/worker"
+	mockwk "github.com/mariobasic/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_LoginUser(t *testing.T) {
+	user, password := randomUser(t)
+	browserAgent := "Mozilla/5.0 (X11; Linux x86_64) Gecko/20100101 Firefox/120.0"
+	clientIP := "203.0.113.7"
+
+	createSession := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+				return db.Session{
+					ID:               arg.ID,
+					Username:         arg.Username,
+					RefreshTokenHash: arg.RefreshTokenHash,
+					UserAgent:        arg.UserAgent,
+					ClientIp:         arg.ClientIp,
+					ExpiredAt:        arg.ExpiredAt,
+				}, nil
+			})
+	}
+
+	// noSecondFactor is a user without TOTP whose role doesn't require it
+	noSecondFactor := func(store *mockdb.MockStore) {
+		store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.TotpSecret{}, db.ErrRecordNotFound)
+		store.EXPECT().GetRolePolicy(gomock.Any(), gomock.Eq(user.Role)).Times(1).Return(db.RolePolicy{}, db.ErrRecordNotFound)
+	}
+
+	tests := []struct {
+		name           string
+		body           *pb.LoginUserRequest
+		buildStubs     func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponses func(*testing.T, *pb.LoginUserResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				noSecondFactor(store)
+				arg := db.GetLoginHistoryParams{Username: user.Username, UserAgent: browserAgent, ClientIp: clientIP}
+				store.EXPECT().
+					GetLoginHistory(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.GetLoginHistoryRow{HasSessions: true, KnownDevice: true, KnownIp: true}, nil)
+				createSession(store)
+				distributor.EXPECT().DistributeTaskSendLoginAlert(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, r.GetUser().GetUsername())
+				require.NotEmpty(t, r.GetAccessToken())
+				require.NotEmpty(t, r.GetRefreshToken())
+				require.NotEmpty(t, r.GetSessionId())
+			},
+		},
+		{
+			name: "FirstLogin",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				noSecondFactor(store)
+				store.EXPECT().
+					GetLoginHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetLoginHistoryRow{}, nil)
+				createSession(store)
+				distributor.EXPECT().DistributeTaskSendLoginAlert(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "NewDevice",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				noSecondFactor(store)
+				store.EXPECT().
+					GetLoginHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetLoginHistoryRow{HasSessions: true, KnownDevice: false, KnownIp: true}, nil)
+				createSession(store)
+				distributor.EXPECT().
+					DistributeTaskSendLoginAlert(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, payload *worker.PayloadSendLoginAlert, _ ...any) error {
+						require.True(t, payload.NewDevice)
+						require.False(t, payload.NewClientIP)
+						require.NotZero(t, payload.SessionID)
+						return nil
+					})
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AlertFailureDoesNotFailLogin",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				noSecondFactor(store)
+				store.EXPECT().
+					GetLoginHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetLoginHistoryRow{HasSessions: true, KnownDevice: true, KnownIp: false}, nil)
+				createSession(store)
+				distributor.EXPECT().
+					DistributeTaskSendLoginAlert(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "PasswordResetRequired",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				reset := user
+				reset.MustResetPassword = true
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(reset, nil)
+				noSecondFactor(store)
+				store.EXPECT().
+					GetLoginHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetLoginHistoryRow{HasSessions: true, KnownDevice: true, KnownIp: true}, nil)
+				createSession(store)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, r.GetPasswordResetRequired())
+			},
+		},
+		{
+			name: "MfaRequired",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{Username: user.Username, IsConfirmed: true}, nil)
+				store.EXPECT().
+					CreateMfaChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateMfaChallengeParams) (db.MfaChallenge, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiredAt, time.Second)
+						return db.MfaChallenge{ID: 1, Username: arg.Username, TokenHash: arg.TokenHash, ExpiredAt: arg.ExpiredAt}, nil
+					})
+				store.EXPECT().GetLoginHistory(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, r.GetMfaRequired())
+				require.NotEmpty(t, r.GetMfaToken())
+				require.NotNil(t, r.GetMfaTokenExpiresAt())
+				require.Empty(t, r.GetAccessToken())
+				require.Empty(t, r.GetRefreshToken())
+				require.Nil(t, r.GetUser())
+			},
+		},
+		{
+			name: "PendingTotpIgnored",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{Username: user.Username, IsConfirmed: false}, nil)
+				store.EXPECT().GetRolePolicy(gomock.Any(), gomock.Any()).Times(1).Return(db.RolePolicy{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateMfaChallenge(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetLoginHistory(gomock.Any(), gomock.Any()).Times(1).Return(db.GetLoginHistoryRow{}, nil)
+				createSession(store)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, r.GetMfaRequired())
+				require.NotEmpty(t, r.GetAccessToken())
+			},
+		},
+		{
+			name: "MfaEnrollmentRequired",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpSecret{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetRolePolicy(gomock.Any(), gomock.Eq(user.Role)).
+					Times(1).
+					Return(db.RolePolicy{Role: user.Role, MfaRequired: true}, nil)
+				store.EXPECT().GetLoginHistory(gomock.Any(), gomock.Any()).Times(1).Return(db.GetLoginHistoryRow{}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						require.False(t, arg.IsMfaVerified)
+						return db.Session{ID: arg.ID, Username: arg.Username, ExpiredAt: arg.ExpiredAt}, nil
+					})
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, r.GetMfaEnrollmentRequired())
+				require.NotEmpty(t, r.GetAccessToken())
+			},
+		},
+		{
+			name: "TotpInternalError",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpSecret{}, sql.ErrConnDone)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "LockedUser",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				locked := user
+				locked.IsLocked = true
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(locked, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: "incorrect"},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetLoginHistory(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "HistoryInternalError",
+			body: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				noSecondFactor(store)
+				store.EXPECT().GetLoginHistory(gomock.Any(), gomock.Any()).Times(1).Return(db.GetLoginHistoryRow{}, sql.ErrConnDone)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+
+			tskCtrl := gomock.NewController(t)
+			tskDist := mockwk.NewMockTaskDistributor(tskCtrl)
+
+			tt.buildStubs(store, tskDist)
+
+			server := NewTestServer(t, store, tskDist)
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+				userAgent:           []string{browserAgent},
+				xForwardedForHeader: []string{clientIP},
+			})
+			res, err := server.LoginUser(ctx, tt.body)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_LoginUserTokensShareSession(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Return(user, nil)
+	store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Return(db.TotpSecret{}, db.ErrRecordNotFound)
+	store.EXPECT().GetRolePolicy(gomock.Any(), gomock.Any()).Return(db.RolePolicy{}, db.ErrRecordNotFound)
+	store.EXPECT().GetLoginHistory(gomock.Any(), gomock.Any()).Return(db.GetLoginHistoryRow{}, nil)
+	var created db.CreateSessionParams
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			created = arg
+			return db.Session{ID: arg.ID, Username: arg.Username, ExpiredAt: arg.ExpiredAt}, nil
+		})
+
+	server := NewTestServer(t, store, nil)
+	res, err := server.LoginUser(context.Background(), &pb.LoginUserRequest{Username: user.Username, Password: password})
+	require.NoError(t, err)
+
+	accessPayload, err := server.tokenMaker.VerifyToken(res.GetAccessToken())
+	require.NoError(t, err)
+	refreshPayload, err := server.tokenMaker.VerifyToken(res.GetRefreshToken())
+	require.NoError(t, err)
+
+	require.Equal(t, res.GetSessionId(), accessPayload.SessionID.String())
+	require.Equal(t, res.GetSessionId(), refreshPayload.SessionID.String())
+	require.WithinDuration(t, time.Now().Add(time.Hour), refreshPayload.ExpiredAt, time.Second)
+
+	// only the hash of the refresh token is stored
+	require.Equal(t, util.HashToken(res.GetRefreshToken()), created.RefreshTokenHash)
+}
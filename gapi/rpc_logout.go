@@ -0,0 +1,38 @@
+package gapi
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Logout blocks the session the access token was issued for, which also invalidates its refresh token
+func (s *Server) Logout(ctx context.Context, _ *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	err = s.store.BlockSession(ctx, authPayload.SessionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	return &pb.LogoutResponse{}, nil
+}
+
+// LogoutAll blocks every session of the logged-in user, logging out all of their devices
+func (s *Server) LogoutAll(ctx context.Context, _ *pb.LogoutAllRequest) (*pb.LogoutAllResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	revoked, err := s.store.BlockUserSessions(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block sessions: %s", err)
+	}
+
+	return &pb.LogoutAllResponse{RevokedSessions: revoked}, nil
+}
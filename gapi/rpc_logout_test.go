@@ -0,0 +1,236 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_Logout(t *testing.T) {
+	user, _ := randomUser(t)
+	session := db.Session{ID: uuid.New(), Username: user.Username, ExpiredAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name           string
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.LogoutResponse, error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithSession(t, tokenMaker, session, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, r)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				blocked := db.Session{ID: uuid.New(), Username: user.Username, IsBlocked: true, ExpiredAt: time.Now().Add(time.Hour)}
+				return newContextWithSession(t, tokenMaker, blocked, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "PendingPasswordReset",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				row := db.GetAuthSessionRow{Session: session, MustResetPassword: true}
+				return newContextWithAuthSession(t, tokenMaker, row, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "SessionOfOtherUser",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, _, err := tokenMaker.CreateToken("other", user.Role, session.ID, token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				return contextWithAccessToken(accessToken)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "UnknownSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, uuid.New(), token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				return contextWithAccessToken(accessToken)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithSession(t, tokenMaker, session, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_Logout_FullMethodName, &pb.LogoutRequest{}, server.Logout)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_LogoutAll(t *testing.T) {
+	user, _ := randomUser(t)
+	session := db.Session{ID: uuid.New(), Username: user.Username, ExpiredAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name           string
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.LogoutAllResponse, error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(int64(3), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutAllResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(3), r.GetRevokedSessions())
+			},
+		},
+		{
+			name: "PendingPasswordReset",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				row := db.GetAuthSessionRow{Session: session, MustResetPassword: true}
+				return newContextWithAuthSession(t, tokenMaker, row, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutAllResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "LockedUser",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				row := db.GetAuthSessionRow{Session: session, IsLocked: true}
+				return newContextWithAuthSession(t, tokenMaker, row, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutAllResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutAllResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.LogoutAllResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_LogoutAll_FullMethodName, &pb.LogoutAllRequest{}, server.LogoutAll)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreatePocket members who can transfer money from the main account can set money aside in a new pocket
+func (s *Server) CreatePocket(ctx context.Context, req *pb.CreatePocketRequest) (*pb.CreatePocketResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreatePocketRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	parent, err := s.getAccount(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if parent.ParentID.Valid {
+		return nil, status.Errorf(codes.FailedPrecondition, "pockets cannot be nested")
+	}
+
+	if err = s.authorizeAccountMember(ctx, parent, authPayload.Username, accountTransferRoles); err != nil {
+		return nil, err
+	}
+
+	pocket, err := s.store.CreatePocket(ctx, db.CreatePocketParams{
+		Name:       req.GetName(),
+		GoalAmount: req.GetGoalAmount(),
+		ParentID:   parent.ID,
+	})
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolationCode {
+			return nil, status.Errorf(codes.AlreadyExists, "account already has a pocket named %s", req.GetName())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create pocket: %s", err)
+	}
+
+	return &pb.CreatePocketResponse{Pocket: convertAccount(pocket)}, nil
+}
+
+// ListPockets members of an account can see its pockets
+func (s *Server) ListPockets(ctx context.Context, req *pb.ListPocketsRequest) (*pb.ListPocketsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := val.ValidateAccountID(req.GetAccountId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("account_id", err)})
+	}
+
+	account, err := s.getAccount(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeAccountMember(ctx, account, authPayload.Username, accountViewRoles); err != nil {
+		return nil, err
+	}
+
+	pockets, err := s.store.ListPockets(ctx, account.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list pockets: %s", err)
+	}
+
+	return &pb.ListPocketsResponse{Pockets: convertAccounts(pockets)}, nil
+}
+
+func validateCreatePocketRequest(req *pb.CreatePocketRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidatePocketName(req.GetName()); err != nil {
+		violations = append(violations, fieldViolation("name", err))
+	}
+	if err := val.ValidateGoalAmount(req.GetGoalAmount()); err != nil {
+		violations = append(violations, fieldViolation("goal_amount", err))
+	}
+
+	return violations
+}
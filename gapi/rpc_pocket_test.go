@@ -0,0 +1,217 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_CreatePocket(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	pocket := randomPocket(account)
+
+	tests := []struct {
+		name           string
+		body           *pb.CreatePocketRequest
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponses func(*testing.T, *pb.CreatePocketResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.CreatePocketRequest{AccountId: account.ID, Name: pocket.Name, GoalAmount: pocket.GoalAmount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: user.Username})).
+					Times(1).
+					Return(randomAccountMember(account.ID, user.Username, util.AccountSignerRole), nil)
+				store.EXPECT().
+					CreatePocket(gomock.Any(), gomock.Eq(db.CreatePocketParams{Name: pocket.Name, GoalAmount: pocket.GoalAmount, ParentID: account.ID})).
+					Times(1).
+					Return(pocket, nil)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreatePocketResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, pocket.ID, r.GetPocket().GetId())
+				require.Equal(t, account.ID, r.GetPocket().GetParentId())
+				require.Equal(t, pocket.Name, r.GetPocket().GetName())
+			},
+		},
+		{
+			name: "Viewer",
+			body: &pb.CreatePocketRequest{AccountId: account.ID, Name: pocket.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, user.Username, util.AccountViewerRole), nil)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreatePocketResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NestedPocket",
+			body: &pb.CreatePocketRequest{AccountId: pocket.ID, Name: pocket.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreatePocketResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "DuplicateName",
+			body: &pb.CreatePocketRequest{AccountId: account.ID, Name: pocket.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, user.Username, util.AccountOwnerRole), nil)
+				store.EXPECT().
+					CreatePocket(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pgconn.PgError{Code: db.UniqueViolationCode})
+			},
+			checkResponses: func(t *testing.T, r *pb.CreatePocketResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "NegativeGoal",
+			body: &pb.CreatePocketRequest{AccountId: account.ID, Name: pocket.Name, GoalAmount: -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.CreatePocketResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+			res, err := callUnary(server, ctx, pb.SimpleBank_CreatePocket_FullMethodName, tt.body, server.CreatePocket)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_ListPockets(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	pockets := []db.Account{randomPocket(account), randomPocket(account)}
+
+	tests := []struct {
+		name           string
+		body           *pb.ListPocketsRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, server *Server) context.Context
+		checkResponses func(*testing.T, *pb.ListPocketsResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ListPocketsRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAccountMember(account.ID, user.Username, util.AccountViewerRole), nil)
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(pockets, nil)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListPocketsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, r.GetPockets(), 2)
+				require.Equal(t, pockets[1].Name, r.GetPockets()[1].GetName())
+			},
+		},
+		{
+			name: "NotMember",
+			body: &pb.ListPocketsRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, db.ErrRecordNotFound)
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.ListPocketsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.ListPocketsRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.ListPocketsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ListPockets_FullMethodName, tt.body, server.ListPockets)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func randomPocket(parent db.Account) db.Account {
+	return db.Account{
+		ID:         util.RandomInt(1001, 2000),
+		Owner:      parent.Owner,
+		Currency:   parent.Currency,
+		ParentID:   pgtype.Int8{Int64: parent.ID, Valid: true},
+		Name:       util.RandomString(8),
+		GoalAmount: util.RandomMoney(),
+	}
+}
@@ -0,0 +1,98 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// RenewAccessToken issues a new access token for the session of a valid refresh token and rotates the refresh token.
+// A session is the family of refresh tokens rotated from one login, only the hash of the latest one is stored,
+// so a valid token of the session that doesn't match it was already rotated and is likely stolen
+func (s *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	refreshPayload, err := s.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+	// an access token of the session doesn't match the refresh token hash either, but it wasn't reused
+	if err = refreshPayload.CheckType(token.TokenTypeRefresh); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+
+	session, err := s.store.GetSession(ctx, refreshPayload.SessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if session.IsBlocked {
+		return nil, status.Error(codes.Unauthenticated, "session is blocked")
+	}
+
+	if session.Username != refreshPayload.Username {
+		return nil, status.Error(codes.Unauthenticated, "incorrect session user")
+	}
+
+	refreshTokenHash := util.HashToken(req.GetRefreshToken())
+	if session.RefreshTokenHash != refreshTokenHash {
+		return nil, s.refreshTokenReused(ctx, session)
+	}
+
+	if time.Now().After(session.ExpiredAt) {
+		return nil, status.Error(codes.Unauthenticated, "session has expired")
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.Role, session.ID, token.TokenTypeAccess, s.config.Token.AccessDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate access token: %s", err)
+	}
+
+	// rotated tokens expire with the session, so a login lasts at most the refresh duration
+	refreshToken, newRefreshPayload, err := s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.Role, session.ID, token.TokenTypeRefresh, time.Until(session.ExpiredAt))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate refresh token: %s", err)
+	}
+
+	rotated, err := s.store.RotateSessionRefreshToken(ctx, db.RotateSessionRefreshTokenParams{
+		NewRefreshTokenHash: util.HashToken(refreshToken),
+		ID:                  session.ID,
+		RefreshTokenHash:    refreshTokenHash,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to rotate refresh token: %s", err)
+	}
+	// a concurrent renewal with the same token rotated it first, the token was used twice
+	if rotated == 0 {
+		return nil, s.refreshTokenReused(ctx, session)
+	}
+
+	return &pb.RenewAccessTokenResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(newRefreshPayload.ExpiredAt),
+	}, nil
+}
+
+// refreshTokenReused revokes the session of a refresh token that was presented again after its rotation,
+// neither the thief nor the user can renew it afterward and its access tokens stop working
+func (s *Server) refreshTokenReused(ctx context.Context, session db.Session) error {
+	metrics.RefreshTokenReuses.Inc()
+	log.Ctx(ctx).Warn().Str("session_id", session.ID.String()).Str("username", session.Username).Msg("refresh token reused, revoking session")
+
+	if err := s.store.BlockSession(ctx, session.ID); err != nil {
+		return status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+	return status.Error(codes.Unauthenticated, "refresh token has already been used, session revoked")
+}
@@ -0,0 +1,238 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_RenewAccessToken(t *testing.T) {
+	user, _ := randomUser(t)
+	sessionID := uuid.New()
+
+	// rotate checks that the presented token is swapped for a new one and reports the rotated rows
+	rotate := func(t *testing.T, session db.Session, rows int64) func(context.Context, db.RotateSessionRefreshTokenParams) (int64, error) {
+		return func(_ context.Context, arg db.RotateSessionRefreshTokenParams) (int64, error) {
+			require.Equal(t, session.ID, arg.ID)
+			require.Equal(t, session.RefreshTokenHash, arg.RefreshTokenHash)
+			require.NotEqual(t, session.RefreshTokenHash, arg.NewRefreshTokenHash)
+			return rows, nil
+		}
+	}
+
+	newRefreshToken := func(t *testing.T, tokenMaker token.Maker, username string) (string, db.Session) {
+		refreshToken, payload, err := tokenMaker.CreateToken(username, user.Role, sessionID, token.TokenTypeRefresh, time.Hour)
+		require.NoError(t, err)
+		return refreshToken, db.Session{
+			ID:               sessionID,
+			Username:         username,
+			RefreshTokenHash: util.HashToken(refreshToken),
+			ExpiredAt:        payload.ExpiredAt,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		buildRequest   func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest
+		checkResponses func(*testing.T, token.Maker, *pb.RenewAccessTokenResponse, error)
+	}{
+		{
+			name: "OK",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionRefreshToken(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(rotate(t, session, 1))
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.NoError(t, err)
+				payload, err := tokenMaker.VerifyToken(r.GetAccessToken())
+				require.NoError(t, err)
+				require.Equal(t, token.TokenTypeAccess, payload.Type)
+				require.Equal(t, sessionID, payload.SessionID)
+				require.Equal(t, user.Username, payload.Username)
+				require.WithinDuration(t, payload.ExpiredAt, r.GetAccessTokenExpiresAt().AsTime(), time.Second)
+
+				// the rotated refresh token belongs to the same session and expires with it
+				refreshPayload, err := tokenMaker.VerifyToken(r.GetRefreshToken())
+				require.NoError(t, err)
+				require.Equal(t, token.TokenTypeRefresh, refreshPayload.Type)
+				require.Equal(t, sessionID, refreshPayload.SessionID)
+				require.WithinDuration(t, time.Now().Add(time.Hour), refreshPayload.ExpiredAt, time.Second)
+				require.WithinDuration(t, refreshPayload.ExpiredAt, r.GetRefreshTokenExpiresAt().AsTime(), time.Second)
+			},
+		},
+		{
+			name: "InvalidToken",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+				return &pb.RenewAccessTokenRequest{RefreshToken: "invalid"}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "AccessToken",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				// a client sending its access token by mistake must not get the session revoked
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+				return &pb.RenewAccessTokenRequest{RefreshToken: accessToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, _ := newRefreshToken(t, tokenMaker, user.Username)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, _ := newRefreshToken(t, tokenMaker, user.Username)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrConnDone)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				session.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "IncorrectSessionUser",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				session.Username = "other"
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ReusedRefreshToken",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				rotatedToken, _ := newRefreshToken(t, tokenMaker, user.Username)
+				session.RefreshTokenHash = util.HashToken(rotatedToken)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(nil)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ConcurrentRotation",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionRefreshToken(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(rotate(t, session, 0))
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(nil)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "BlockSessionError",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				session.RefreshTokenHash = util.HashToken("rotated")
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(sql.ErrConnDone)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "ExpiredSession",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				session.ExpiredAt = time.Now().Add(-time.Minute)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "RotateError",
+			buildRequest: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) *pb.RenewAccessTokenRequest {
+				refreshToken, session := newRefreshToken(t, tokenMaker, user.Username)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+				return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			},
+			checkResponses: func(t *testing.T, tokenMaker token.Maker, r *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+
+			server := NewTestServer(t, store, nil)
+			req := tt.buildRequest(t, server.tokenMaker, store)
+			res, err := server.RenewAccessToken(context.Background(), req)
+			tt.checkResponses(t, server.tokenMaker, res, err)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeSession blocks one of the sessions of the logged-in user, e.g. a lost or unknown device
+func (s *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	sessionID, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	// sessions of other users are reported as missing so their ids can't be probed
+	if session.Username != authPayload.Username {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+
+	err = s.store.BlockSession(ctx, session.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	return &pb.RevokeSessionResponse{}, nil
+}
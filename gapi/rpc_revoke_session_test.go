@@ -0,0 +1,135 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_RevokeSession(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	session := randomSession(user.Username)
+	otherSession := randomSession(other.Username)
+
+	tests := []struct {
+		name           string
+		body           *pb.RevokeSessionRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.RevokeSessionResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.RevokeSessionRequest{Id: session.ID.String()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.RevokeSessionResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "SessionOfOtherUser",
+			body: &pb.RevokeSessionRequest{Id: otherSession.ID.String()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(otherSession.ID)).Times(1).Return(otherSession, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.RevokeSessionResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NotFound",
+			body: &pb.RevokeSessionRequest{Id: uuid.NewString()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.RevokeSessionResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidID",
+			body: &pb.RevokeSessionRequest{Id: "invalid"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.RevokeSessionResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.RevokeSessionRequest{Id: session.ID.String()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.RevokeSessionResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.RevokeSessionRequest{Id: session.ID.String()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.RevokeSessionResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_RevokeSession_FullMethodName, tt.body, server.RevokeSession)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
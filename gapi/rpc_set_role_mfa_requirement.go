@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SetRoleMfaRequirement makes every user of the role enroll a second factor, sessions that didn't pass one
+// can only enroll from now on
+func (s *Server) SetRoleMfaRequirement(ctx context.Context, req *pb.SetRoleMfaRequirementRequest) (*pb.SetRoleMfaRequirementResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateSetRoleMfaRequirementRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.store.SetRolePolicyTx(ctx, db.SetRolePolicyTxParams{
+		UpsertRolePolicyParams: db.UpsertRolePolicyParams{
+			Role:        req.GetRole(),
+			MfaRequired: req.GetMfaRequired(),
+			UpdatedBy:   authPayload.Username,
+		},
+		Action:  util.AuditSetRoleMfa,
+		Details: fmt.Sprintf("mfa_required=%t", req.GetMfaRequired()),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set role policy: %s", err)
+	}
+
+	return &pb.SetRoleMfaRequirementResponse{
+		Role:        result.RolePolicy.Role,
+		MfaRequired: result.RolePolicy.MfaRequired,
+		UpdatedBy:   result.RolePolicy.UpdatedBy,
+		UpdatedAt:   timestamppb.New(result.RolePolicy.UpdatedAt),
+	}, nil
+}
+
+func validateSetRoleMfaRequirementRequest(req *pb.SetRoleMfaRequirementRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateRole(req.GetRole()); err != nil {
+		violations = append(violations, fieldViolation("role", err))
+	}
+	return violations
+}
@@ -0,0 +1,120 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_SetRoleMfaRequirement(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	tests := []struct {
+		name           string
+		body           *pb.SetRoleMfaRequirementRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.SetRoleMfaRequirementResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.SetRoleMfaRequirementRequest{Role: util.BankerRole, MfaRequired: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetRolePolicyTxParams{
+					UpsertRolePolicyParams: db.UpsertRolePolicyParams{
+						Role:        util.BankerRole,
+						MfaRequired: true,
+						UpdatedBy:   banker.Username,
+					},
+					Action:  util.AuditSetRoleMfa,
+					Details: "mfa_required=true",
+				}
+				store.EXPECT().
+					SetRolePolicyTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.SetRolePolicyTxResult{RolePolicy: db.RolePolicy{
+						Role:        util.BankerRole,
+						MfaRequired: true,
+						UpdatedBy:   banker.Username,
+						UpdatedAt:   time.Now(),
+					}}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.SetRoleMfaRequirementResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.BankerRole, r.GetRole())
+				require.True(t, r.GetMfaRequired())
+				require.Equal(t, banker.Username, r.GetUpdatedBy())
+			},
+		},
+		{
+			name: "InvalidRole",
+			body: &pb.SetRoleMfaRequirementRequest{Role: "admin", MfaRequired: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetRolePolicyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.SetRoleMfaRequirementResponse, err error) {
+				requireFieldViolations(t, err, "role")
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.SetRoleMfaRequirementRequest{Role: util.DepositorRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetRolePolicyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.SetRolePolicyTxResult{}, sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.SetRoleMfaRequirementResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "DepositorDenied",
+			body: &pb.SetRoleMfaRequirementRequest{Role: util.DepositorRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetRolePolicyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.SetRoleMfaRequirementResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_SetRoleMfaRequirement_FullMethodName, tt.body, server.SetRoleMfaRequirement)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/mfa"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// totpMaxAttempts wrong codes burn a pending TOTP secret and block the session, the user has to enroll again
+const totpMaxAttempts = 5
+
+// EnrollTotp creates a pending TOTP secret, enrolling again replaces it until a code confirms it
+func (s *Server) EnrollTotp(ctx context.Context, _ *pb.EnrollTotpRequest) (*pb.EnrollTotpResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	totp, err := s.store.CreateTotpSecret(ctx, db.CreateTotpSecretParams{Username: authPayload.Username, Secret: secret})
+	if err != nil {
+		// the upsert leaves a confirmed secret alone
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.FailedPrecondition, "TOTP is already enabled")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create TOTP secret: %s", err)
+	}
+
+	return &pb.EnrollTotpResponse{
+		Secret:          totp.Secret,
+		ProvisioningUri: mfa.ProvisioningURI(totp.Secret, authPayload.Username),
+	}, nil
+}
+
+// ConfirmTotp enables TOTP with the first code of the authenticator app, the recovery codes are only shown here
+func (s *Server) ConfirmTotp(ctx context.Context, req *pb.ConfirmTotpRequest) (*pb.ConfirmTotpResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateConfirmTotpRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	totp, err := s.store.GetTotpSecret(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.FailedPrecondition, "TOTP enrollment not started")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get TOTP secret: %s", err)
+	}
+	if totp.IsConfirmed {
+		return nil, status.Error(codes.FailedPrecondition, "TOTP is already enabled")
+	}
+
+	totp, err = s.store.AttemptTotpConfirmation(ctx, db.AttemptTotpConfirmationParams{
+		Username:    authPayload.Username,
+		MaxAttempts: totpMaxAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, s.blockTotpSession(ctx, authPayload.SessionID)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to attempt TOTP confirmation: %s", err)
+	}
+
+	step, ok := mfa.ValidateCode(totp.Secret, req.GetCode(), time.Now())
+	if !ok {
+		if totp.ConfirmAttempts >= totpMaxAttempts {
+			return nil, s.blockTotpSession(ctx, authPayload.SessionID)
+		}
+		return nil, status.Error(codes.InvalidArgument, "invalid TOTP code")
+	}
+
+	recoveryCodes, err := mfa.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = util.HashToken(code)
+	}
+
+	_, err = s.store.ConfirmTotpTx(ctx, db.ConfirmTotpTxParams{
+		Username:           authPayload.Username,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+		SessionID:          authPayload.SessionID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.InvalidArgument, "TOTP code is already used")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to confirm TOTP: %s", err)
+	}
+
+	return &pb.ConfirmTotpResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// blockTotpSession ends the session that used up the attempts of a pending TOTP secret
+func (s *Server) blockTotpSession(ctx context.Context, sessionID uuid.UUID) error {
+	if err := s.store.BlockSession(ctx, sessionID); err != nil {
+		return status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+	return status.Error(codes.PermissionDenied, "too many invalid TOTP codes, log in and enroll again")
+}
+
+func validateConfirmTotpRequest(req *pb.ConfirmTotpRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateTotpCode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+	return violations
+}
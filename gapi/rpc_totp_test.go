@@ -0,0 +1,298 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/mfa"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_EnrollTotp(t *testing.T) {
+	user, _ := randomUser(t)
+
+	tests := []struct {
+		name           string
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.EnrollTotpResponse, error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTotpSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateTotpSecretParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.TotpSecret{Username: arg.Username, Secret: arg.Secret}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.EnrollTotpResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, r.GetSecret())
+				require.Equal(t, mfa.ProvisioningURI(r.GetSecret(), user.Username), r.GetProvisioningUri())
+			},
+		},
+		{
+			name: "MfaPendingSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpSecret{Username: user.Username}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				session := db.Session{ID: uuid.New(), Username: user.Username, LastUsedAt: time.Now()}
+				return newContextWithAuthSession(t, tokenMaker, db.GetAuthSessionRow{Session: session, MfaRequired: true}, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.EnrollTotpResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpSecret{}, db.ErrRecordNotFound)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.EnrollTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpSecret{}, sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.EnrollTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.EnrollTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_EnrollTotp_FullMethodName, &pb.EnrollTotpRequest{}, server.EnrollTotp)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
+
+func TestServer_ConfirmTotp(t *testing.T) {
+	user, _ := randomUser(t)
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+	pending := db.TotpSecret{Username: user.Username, Secret: secret}
+	attempted := pending
+	attempted.ConfirmAttempts = 1
+
+	validCode := func(t *testing.T) string {
+		code, err := mfa.GenerateCode(secret, mfa.Step(time.Now()))
+		require.NoError(t, err)
+		return code
+	}
+
+	tests := []struct {
+		name           string
+		code           func(t *testing.T) string
+		buildStubs     func(store *mockdb.MockStore, sessionID uuid.UUID)
+		checkResponses func(*testing.T, *pb.ConfirmTotpResponse, error)
+	}{
+		{
+			name: "OK",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(pending, nil)
+				store.EXPECT().
+					AttemptTotpConfirmation(gomock.Any(), gomock.Eq(db.AttemptTotpConfirmationParams{Username: user.Username, MaxAttempts: totpMaxAttempts})).
+					Times(1).
+					Return(attempted, nil)
+				store.EXPECT().
+					ConfirmTotpTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ConfirmTotpTxParams) (db.ConfirmTotpTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, sessionID, arg.SessionID)
+						require.InDelta(t, mfa.Step(time.Now()), arg.Step, 1)
+						require.Len(t, arg.RecoveryCodeHashes, mfa.RecoveryCodeCount)
+						return db.ConfirmTotpTxResult{}, nil
+					})
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, r.GetRecoveryCodes(), mfa.RecoveryCodeCount)
+			},
+		},
+		{
+			name: "WrongCode",
+			code: func(t *testing.T) string {
+				code, err := mfa.GenerateCode(secret, mfa.Step(time.Now())-10)
+				require.NoError(t, err)
+				return code
+			},
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+				store.EXPECT().AttemptTotpConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(attempted, nil)
+				store.EXPECT().ConfirmTotpTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "LastWrongCode",
+			code: func(t *testing.T) string {
+				code, err := mfa.GenerateCode(secret, mfa.Step(time.Now())-10)
+				require.NoError(t, err)
+				return code
+			},
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				last := pending
+				last.ConfirmAttempts = totpMaxAttempts
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+				store.EXPECT().AttemptTotpConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(last, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(nil)
+				store.EXPECT().ConfirmTotpTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "AttemptsUsedUp",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+				store.EXPECT().AttemptTotpConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpSecret{}, db.ErrRecordNotFound)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(nil)
+				store.EXPECT().ConfirmTotpTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ReplayedCode",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+				store.EXPECT().AttemptTotpConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(attempted, nil)
+				store.EXPECT().ConfirmTotpTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConfirmTotpTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NotEnrolled",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpSecret{}, db.ErrRecordNotFound)
+				store.EXPECT().ConfirmTotpTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				confirmed := pending
+				confirmed.IsConfirmed = true
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(confirmed, nil)
+				store.EXPECT().ConfirmTotpTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: func(t *testing.T) string { return "12ab56" },
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				requireFieldViolations(t, err, "code")
+			},
+		},
+		{
+			name: "TxError",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore, sessionID uuid.UUID) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+				store.EXPECT().AttemptTotpConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(attempted, nil)
+				store.EXPECT().ConfirmTotpTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConfirmTotpTxResult{}, sql.ErrConnDone)
+			},
+			checkResponses: func(t *testing.T, r *pb.ConfirmTotpResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			// the enrolling session of a role that requires a second factor
+			session := db.Session{ID: uuid.New(), Username: user.Username, LastUsedAt: time.Now()}
+			tt.buildStubs(store, session.ID)
+
+			server := NewTestServer(t, store, nil)
+			ctx := newContextWithAuthSession(t, server.tokenMaker, db.GetAuthSessionRow{Session: session, MfaRequired: true}, util.DepositorRole, time.Minute)
+			res, err := callUnary(server, ctx, pb.SimpleBank_ConfirmTotp_FullMethodName, &pb.ConfirmTotpRequest{Code: tt.code(t)}, server.ConfirmTotp)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -3,23 +3,28 @@ package gapi
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/mariobasic/simplebank/db/sqlc"
 	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/problem"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/mariobasic/simplebank/val"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"strings"
 	"time"
 )
 
 func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	authPayload, err := s.authorizeUser(ctx, []string{util.BankerRole, util.DepositorRole})
+	authPayload, err := authPayloadFromContext(ctx)
 	if err != nil {
 		return nil, unauthenticatedError(err)
 	}
-	violations := validateUpdateUserRequest(req)
+
+	arg, password, violations := validateUpdateUserRequest(req)
 	if len(violations) > 0 {
 		return nil, invalidArgumentError(violations)
 	}
@@ -28,30 +33,29 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 		return nil, status.Errorf(codes.PermissionDenied, "cannot update other user's info")
 	}
 
-	arg := db.UpdateUserParams{
-		Username: req.GetUsername(),
-		FullName: pgtype.Text{
-			String: req.GetFullName(),
-			Valid:  req.FullName != nil,
-		},
-		Email: pgtype.Text{
-			String: req.GetEmail(),
-			Valid:  req.Email != nil,
-		},
+	if etag := requestETag(ctx, req.Etag); etag != "" && etag != anyETag {
+		version, err := parseETag(etag)
+		if err != nil {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("etag", err)})
+		}
+		arg.Version = pgtype.Int8{Int64: version, Valid: true}
 	}
 
-	if req.Password != nil {
-		password, err := util.HashPassword(req.GetPassword())
+	if password != nil {
+		hashedPassword, err := util.HashPassword(*password)
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
-		arg.HashedPassword = pgtype.Text{String: password, Valid: true}
+		arg.HashedPassword = pgtype.Text{String: hashedPassword, Valid: true}
 		arg.PasswordChangedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
 	}
 
-	user, err := s.store.UpdateUser(ctx, arg)
+	user, err := s.updateUser(ctx, authPayload.Username, req, arg)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
+			if arg.Version.Valid {
+				return nil, s.staleUserError(ctx, arg.Username)
+			}
 			return nil, status.Errorf(codes.NotFound, "user not found: %s", err.Error())
 		}
 		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err.Error())
@@ -60,28 +64,99 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 	return &pb.UpdateUserResponse{User: convertUser(user)}, nil
 }
 
-func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := val.ValidateUsername(req.GetUsername()); err != nil {
-		violations = append(violations, fieldViolation("username", err))
+// updateUser applies the update, a banker editing another user is recorded in the audit log with the fields changed
+func (s *Server) updateUser(ctx context.Context, caller string, req *pb.UpdateUserRequest, arg db.UpdateUserParams) (db.User, error) {
+	if caller == arg.Username {
+		return s.store.UpdateUser(ctx, arg)
 	}
 
-	if req.Password != nil {
-		if err := val.ValidatePassword(req.GetPassword()); err != nil {
-			violations = append(violations, fieldViolation("password", err))
-		}
+	_, mask := userUpdateMask(req)
+	result, err := s.store.AdminUpdateUserTx(ctx, db.AdminUpdateUserTxParams{
+		UpdateUserParams: arg,
+		Banker:           caller,
+		Action:           util.AuditUpdateUser,
+		Details:          "fields=" + strings.Join(mask.GetPaths(), ","),
+	})
+	return result.User, err
+}
+
+// userUpdateMask is the update and field mask of the request, requests without a mask
+// list the optional fields they set the way UpdateUser worked before field masks
+func userUpdateMask(req *pb.UpdateUserRequest) (*pb.UserUpdate, *fieldmaskpb.FieldMask) {
+	if len(req.GetUpdateMask().GetPaths()) > 0 {
+		return req.GetUser(), req.GetUpdateMask()
 	}
 
+	update := &pb.UserUpdate{}
+	mask := &fieldmaskpb.FieldMask{}
 	if req.FullName != nil {
-		if err := val.ValidateFullName(req.GetFullName()); err != nil {
-			violations = append(violations, fieldViolation("full_name", err))
-		}
+		update.FullName = req.GetFullName()
+		mask.Paths = append(mask.Paths, "full_name")
 	}
-
 	if req.Email != nil {
-		if err := val.ValidateEmail(req.GetEmail()); err != nil {
-			violations = append(violations, fieldViolation("email", err))
+		update.Email = req.GetEmail()
+		mask.Paths = append(mask.Paths, "email")
+	}
+	if req.Password != nil {
+		update.Password = req.GetPassword()
+		mask.Paths = append(mask.Paths, "password")
+	}
+	return update, mask
+}
+
+// staleUserError tells a stale version apart from a missing user, a guarded update matches no row for both.
+// The user is read from the primary, a lagging replica could still return the version the update was guarded by
+func (s *Server) staleUserError(ctx context.Context, username string) error {
+	user, err := s.store.GetUser(db.WithPrimary(ctx), username)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return status.Errorf(codes.NotFound, "user not found: %s", err.Error())
 		}
+		return status.Errorf(codes.Internal, "failed to get user: %s", err.Error())
 	}
 
-	return violations
+	return problem.StaleVersion.Error(fmt.Sprintf("user was updated since it was read, the current etag is %s", formatETag(user.Version)))
+}
+
+// validateUpdateUserRequest sets the fields of the update mask on the update params,
+// the new password is returned apart to be hashed once the caller is known to be allowed to set it
+func validateUpdateUserRequest(req *pb.UpdateUserRequest) (
+	arg db.UpdateUserParams,
+	password *string,
+	violations []*errdetails.BadRequest_FieldViolation,
+) {
+	arg.Username = req.GetUsername()
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	update, mask := userUpdateMask(req)
+	violations = append(violations, applyFieldMask(mask, map[string]fieldUpdater{
+		// the full name is optional, an empty value clears it
+		"full_name": func() error {
+			if update.GetFullName() != "" {
+				if err := val.ValidateFullName(update.GetFullName()); err != nil {
+					return err
+				}
+			}
+			arg.FullName = pgtype.Text{String: update.GetFullName(), Valid: true}
+			return nil
+		},
+		"email": func() error {
+			if err := val.ValidateEmail(update.GetEmail()); err != nil {
+				return err
+			}
+			arg.Email = pgtype.Text{String: update.GetEmail(), Valid: true}
+			return nil
+		},
+		"password": func() error {
+			if err := val.ValidatePassword(update.GetPassword()); err != nil {
+				return err
+			}
+			password = util.ToPtr(update.GetPassword())
+			return nil
+		},
+	})...)
+
+	return arg, password, violations
 }
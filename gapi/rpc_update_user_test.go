@@ -8,17 +8,23 @@ import (
 	mockdb "github.com/mariobasic/simplebank/db/mock"
 	db "github.com/mariobasic/simplebank/db/sqlc"
 	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/problem"
 	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"net/http"
 	"testing"
 	"time"
 )
 
 func TestServer_UpdateUser(t *testing.T) {
 	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
 	newName := util.RandomOwner()
 	newEmail := util.RandomEmail()
 	tests := []struct {
@@ -68,6 +74,37 @@ func TestServer_UpdateUser(t *testing.T) {
 				require.Equal(t, newEmail, uptUsr.Email)
 			},
 		},
+		{
+			name: "BankerUpdatesOtherUser",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+				Password: util.ToPtr(util.RandomString(8)),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					AdminUpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.AdminUpdateUserTxParams) (db.AdminUpdateUserTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, banker.Username, arg.Banker)
+						require.Equal(t, util.AuditUpdateUser, arg.Action)
+						require.Equal(t, "fields=full_name,password", arg.Details)
+						require.True(t, arg.HashedPassword.Valid)
+						return db.AdminUpdateUserTxResult{User: db.User{Username: user.Username, FullName: newName}}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newName, r.GetUser().GetFullName())
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: &pb.UpdateUserRequest{
@@ -91,6 +128,289 @@ func TestServer_UpdateUser(t *testing.T) {
 				require.Equal(t, codes.NotFound, status.Code(err))
 			},
 		},
+		{
+			name: "ETagField",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+				Etag:     util.ToPtr(formatETag(3)),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					FullName: pgtype.Text{String: newName, Valid: true},
+					Version:  pgtype.Int8{Int64: 3, Valid: true},
+				}
+
+				store.EXPECT().
+					UpdateUser(gomock.Any(), arg).
+					Times(1).
+					Return(db.User{Username: user.Username, FullName: newName, Version: 4}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, `"4"`, r.GetUser().GetEtag())
+			},
+		},
+		{
+			name: "IfMatchHeader",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					FullName: pgtype.Text{String: newName, Valid: true},
+					Version:  pgtype.Int8{Int64: 3, Valid: true},
+				}
+
+				store.EXPECT().
+					UpdateUser(gomock.Any(), arg).
+					Times(1).
+					Return(db.User{Username: user.Username, FullName: newName, Version: 4}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				ctx := newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+				return withIncomingMetadata(ctx, ifMatchHeader, formatETag(3))
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, formatETag(4), r.GetUser().GetEtag())
+			},
+		},
+		{
+			name: "IfMatchAny",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					FullName: pgtype.Text{String: newName, Valid: true},
+				}
+
+				store.EXPECT().
+					UpdateUser(gomock.Any(), arg).
+					Times(1).
+					Return(db.User{Username: user.Username, FullName: newName, Version: 4}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				ctx := newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+				return withIncomingMetadata(ctx, ifMatchHeader, anyETag)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "StaleETag",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+				Etag:     util.ToPtr(formatETag(3)),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{Username: user.Username, Version: 5}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Aborted, status.Code(err))
+				require.Equal(t, http.StatusPreconditionFailed, problem.FromStatus(status.Convert(err)).Status)
+			},
+		},
+		{
+			name: "StaleETagUserNotFound",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+				Etag:     util.ToPtr(formatETag(3)),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidETag",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+				Etag:     util.ToPtr(`W/"3"`),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "FieldMask",
+			body: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				User:       &pb.UserUpdate{FullName: newName, Email: newEmail},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					Email:    pgtype.Text{String: newEmail, Valid: true},
+				}
+
+				store.EXPECT().
+					UpdateUser(gomock.Any(), arg).
+					Times(1).
+					Return(db.User{Username: user.Username, FullName: user.FullName, Email: newEmail}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.FullName, r.GetUser().GetFullName())
+				require.Equal(t, newEmail, r.GetUser().GetEmail())
+			},
+		},
+		{
+			name: "FieldMaskIgnoresOptionalFields",
+			body: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				Email:      &newEmail,
+				User:       &pb.UserUpdate{FullName: newName},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"full_name"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					FullName: pgtype.Text{String: newName, Valid: true},
+				}
+
+				store.EXPECT().
+					UpdateUser(gomock.Any(), arg).
+					Times(1).
+					Return(db.User{Username: user.Username, FullName: newName}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ClearFullName",
+			body: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				User:       &pb.UserUpdate{},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"full_name"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					FullName: pgtype.Text{String: "", Valid: true},
+				}
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.User{Username: user.Username, Email: user.Email}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Empty(t, r.GetUser().GetFullName())
+			},
+		},
+		{
+			name: "ClearEmail",
+			body: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				User:       &pb.UserUpdate{},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				requireFieldViolations(t, err, "email")
+			},
+		},
+		{
+			name: "UnknownPath",
+			body: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				User:       &pb.UserUpdate{FullName: newName},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"full_name", "role"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				requireFieldViolations(t, err, updateMaskField)
+			},
+		},
+		{
+			name: "NothingToUpdate",
+			body: &pb.UpdateUserRequest{
+				Username: user.Username,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.UpdateUserResponse, err error) {
+				requireFieldViolations(t, err, updateMaskField)
+			},
+		},
 		{
 			name: "InvalidEmail",
 			body: &pb.UpdateUserRequest{
@@ -166,14 +486,31 @@ func TestServer_UpdateUser(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
 
 			tt.buildStubs(store)
 
 			server := NewTestServer(t, store, nil)
 			ctx := tt.buildContext(t, server.tokenMaker)
-			createUser, err := server.UpdateUser(ctx, tt.body)
+			createUser, err := callUnary(server, ctx, pb.SimpleBank_UpdateUser_FullMethodName, tt.body, server.UpdateUser)
 			tt.checkResponses(t, createUser, err)
 
 		})
 	}
 }
+
+func requireFieldViolations(t *testing.T, err error, fields ...string) {
+	require.Error(t, err)
+	st := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+
+	var violated []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violated = append(violated, violation.GetField())
+			}
+		}
+	}
+	require.Equal(t, fields, violated)
+}
@@ -0,0 +1,109 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/mariobasic/simplebank/mfa"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/mariobasic/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// mfaMaxAttempts wrong codes burn an mfa token, the user has to enter the password again
+const mfaMaxAttempts = 5
+
+// VerifyLoginMfa exchanges the mfa token of a login for its tokens with a TOTP code or an unused recovery code
+func (s *Server) VerifyLoginMfa(ctx context.Context, req *pb.VerifyLoginMfaRequest) (*pb.LoginUserResponse, error) {
+	violations := validateVerifyLoginMfaRequest(req)
+	if len(violations) > 0 {
+		return nil, invalidArgumentError(violations)
+	}
+
+	challenge, err := s.store.AttemptMfaChallenge(ctx, db.AttemptMfaChallengeParams{
+		TokenHash:   util.HashToken(req.GetMfaToken()),
+		MaxAttempts: mfaMaxAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "mfa token is invalid, expired or used up")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get mfa challenge: %s", err)
+	}
+
+	user, err := s.store.GetUser(db.WithPrimary(ctx), challenge.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+	if user.IsLocked {
+		metrics.Logins.WithLabelValues(metrics.LoginFailed).Inc()
+		return nil, status.Error(codes.PermissionDenied, "user is locked")
+	}
+
+	if err = s.checkSecondFactor(ctx, user.Username, req.GetCode()); err != nil {
+		metrics.Logins.WithLabelValues(metrics.LoginFailed).Inc()
+		return nil, err
+	}
+
+	// a concurrent request may have used the token since it was attempted
+	rows, err := s.store.UseMfaChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to use mfa challenge: %s", err)
+	}
+	if rows == 0 {
+		return nil, status.Error(codes.Unauthenticated, "mfa token is already used")
+	}
+
+	return s.startSession(ctx, user, true)
+}
+
+// checkSecondFactor accepts a TOTP code once, codes of six digits are TOTP codes and the others recovery codes
+func (s *Server) checkSecondFactor(ctx context.Context, username, code string) error {
+	if val.ValidateTotpCode(code) != nil {
+		rows, err := s.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			Username: username,
+			CodeHash: util.HashToken(mfa.NormalizeRecoveryCode(code)),
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to use recovery code: %s", err)
+		}
+		if rows == 0 {
+			return status.Error(codes.Unauthenticated, "invalid recovery code")
+		}
+		return nil
+	}
+
+	totp, err := s.store.GetTotpSecret(ctx, username)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get TOTP secret: %s", err)
+	}
+
+	step, ok := mfa.ValidateCode(totp.Secret, code, time.Now())
+	if !ok {
+		return status.Error(codes.Unauthenticated, "invalid TOTP code")
+	}
+
+	rows, err := s.store.UseTotpStep(ctx, db.UseTotpStepParams{Step: step, Username: username})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to use TOTP code: %s", err)
+	}
+	if rows == 0 {
+		return status.Error(codes.Unauthenticated, "TOTP code is already used")
+	}
+	return nil
+}
+
+func validateVerifyLoginMfaRequest(req *pb.VerifyLoginMfaRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateMfaToken(req.GetMfaToken()); err != nil {
+		violations = append(violations, fieldViolation("mfa_token", err))
+	}
+	if err := val.ValidateMfaCode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+	return violations
+}
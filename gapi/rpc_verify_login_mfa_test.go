@@ -0,0 +1,213 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/mfa"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_VerifyLoginMfa(t *testing.T) {
+	user, _ := randomUser(t)
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+	totp := db.TotpSecret{Username: user.Username, Secret: secret, IsConfirmed: true}
+
+	mfaToken, err := mfa.GenerateChallengeToken()
+	require.NoError(t, err)
+	challenge := db.MfaChallenge{ID: util.RandomInt(1, 1000), Username: user.Username, TokenHash: util.HashToken(mfaToken), Attempts: 1}
+	recoveryCode := "abcd-efgh-ijkl-mnop"
+
+	step := mfa.Step(time.Now())
+	totpCode, err := mfa.GenerateCode(secret, step)
+	require.NoError(t, err)
+
+	attempt := func(store *mockdb.MockStore) {
+		arg := db.AttemptMfaChallengeParams{TokenHash: util.HashToken(mfaToken), MaxAttempts: mfaMaxAttempts}
+		store.EXPECT().AttemptMfaChallenge(gomock.Any(), gomock.Eq(arg)).Times(1).Return(challenge, nil)
+		store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	}
+	startVerifiedSession := func(store *mockdb.MockStore) {
+		store.EXPECT().UseMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(int64(1), nil)
+		store.EXPECT().GetLoginHistory(gomock.Any(), gomock.Any()).Times(1).Return(db.GetLoginHistoryRow{}, nil)
+		store.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+				require.True(t, arg.IsMfaVerified)
+				return db.Session{ID: arg.ID, Username: arg.Username, ExpiredAt: arg.ExpiredAt, IsMfaVerified: true}, nil
+			})
+	}
+
+	tests := []struct {
+		name           string
+		body           *pb.VerifyLoginMfaRequest
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponses func(*testing.T, *pb.LoginUserResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: totpCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				attempt(store)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(totp, nil)
+				store.EXPECT().
+					UseTotpStep(gomock.Any(), gomock.Eq(db.UseTotpStepParams{Step: step, Username: user.Username})).
+					Times(1).
+					Return(int64(1), nil)
+				startVerifiedSession(store)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, r.GetUser().GetUsername())
+				require.NotEmpty(t, r.GetAccessToken())
+				require.NotEmpty(t, r.GetRefreshToken())
+				require.False(t, r.GetMfaRequired())
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: "ABCD EFGH IJKL MNOP"},
+			buildStubs: func(store *mockdb.MockStore) {
+				attempt(store)
+				arg := db.UseRecoveryCodeParams{Username: user.Username, CodeHash: util.HashToken(recoveryCode)}
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(1), nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+				startVerifiedSession(store)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, r.GetAccessToken())
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				attempt(store)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().UseMfaChallenge(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "WrongTotpCode",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: func() string {
+				code, _ := mfa.GenerateCode(secret, step-10)
+				return code
+			}()},
+			buildStubs: func(store *mockdb.MockStore) {
+				attempt(store)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(totp, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ReplayedTotpCode",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: totpCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				attempt(store)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(totp, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidMfaToken",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: totpCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AttemptMfaChallenge(gomock.Any(), gomock.Any()).Times(1).Return(db.MfaChallenge{}, db.ErrRecordNotFound)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ConcurrentlyUsedMfaToken",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				attempt(store)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().UseMfaChallenge(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "LockedUser",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: totpCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				locked := user
+				locked.IsLocked = true
+				store.EXPECT().AttemptMfaChallenge(gomock.Any(), gomock.Any()).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(locked, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: mfaToken, Code: totpCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AttemptMfaChallenge(gomock.Any(), gomock.Any()).Times(1).Return(db.MfaChallenge{}, sql.ErrConnDone)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidArguments",
+			body: &pb.VerifyLoginMfaRequest{MfaToken: "short", Code: "123"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AttemptMfaChallenge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(t *testing.T, r *pb.LoginUserResponse, err error) {
+				requireFieldViolations(t, err, "mfa_token", "code")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			res, err := callUnary(server, context.Background(), pb.SimpleBank_VerifyLoginMfa_FullMethodName, tt.body, server.VerifyLoginMfa)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package gapi
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+)
+
+func (s *Server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	result, err := s.cashOperation(ctx, util.WithdrawalOperation, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.WithdrawResponse{
+		Operation: convertCashOperation(result.CashOperation),
+		Account:   convertAccount(result.Account),
+	}, nil
+}
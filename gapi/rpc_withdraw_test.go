@@ -0,0 +1,153 @@
+package gapi
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/token"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestServer_Withdraw(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	depositor, _ := randomUser(t)
+	account := randomAccount(depositor.Username)
+	amount := account.Balance / 2
+	reference := util.RandomString(12)
+
+	tests := []struct {
+		name           string
+		body           *pb.WithdrawRequest
+		buildStubs     func(store *mockdb.MockStore)
+		buildContext   func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponses func(*testing.T, *pb.WithdrawResponse, error)
+	}{
+		{
+			name: "OK",
+			body: &pb.WithdrawRequest{
+				AccountId: account.ID,
+				Amount:    amount,
+				Reference: reference,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CashOperationTxParams{
+					Type:      util.WithdrawalOperation,
+					AccountID: account.ID,
+					Amount:    amount,
+					Reference: reference,
+					Banker:    banker.Username,
+				}
+				updated := account
+				updated.Balance -= amount
+
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CashOperationTxResult{
+						CashOperation: db.CashOperation{
+							ID:        1,
+							Type:      arg.Type,
+							AccountID: arg.AccountID,
+							Amount:    arg.Amount,
+							Reference: arg.Reference,
+							Banker:    arg.Banker,
+						},
+						Account: updated,
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.WithdrawResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, r)
+				require.Equal(t, util.WithdrawalOperation, r.GetOperation().GetType())
+				require.Equal(t, account.Balance-amount, r.GetAccount().GetBalance())
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: &pb.WithdrawRequest{
+				AccountId: account.ID,
+				Amount:    account.Balance + 1,
+				Reference: reference,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CashOperationTxResult{}, db.ErrInsufficientFunds)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.WithdrawResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidReference",
+			body: &pb.WithdrawRequest{
+				AccountId: account.ID,
+				Amount:    amount,
+				Reference: "bad ref!",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, banker.Username, banker.Role, time.Minute)
+			},
+			checkResponses: func(t *testing.T, r *pb.WithdrawResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.WithdrawRequest{
+				AccountId: account.ID,
+				Amount:    amount,
+				Reference: reference,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashOperationTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponses: func(t *testing.T, r *pb.WithdrawResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			tt.buildStubs(store)
+
+			server := NewTestServer(t, store, nil)
+			ctx := tt.buildContext(t, server.tokenMaker)
+			res, err := callUnary(server, ctx, pb.SimpleBank_Withdraw_FullMethodName, tt.body, server.Withdraw)
+			tt.checkResponses(t, res, err)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package gapi
 import (
 	db "github.com/mariobasic/simplebank/db/sqlc"
 	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/ratelimit"
 	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/mariobasic/simplebank/worker"
@@ -15,13 +16,28 @@ type Server struct {
 	store           db.Store
 	tokenMaker      token.Maker
 	taskDistributor worker.TaskDistributor
+	rateLimiter     ratelimit.Limiter
+	rateLimits      map[string]ratelimit.Limit
 }
 
-func NewServer(config util.Config, store db.Store, distributor worker.TaskDistributor) *Server {
-	tokenMaker, err := token.NewPasetoMaker(config.Token.SymmetricKey)
+func NewServer(
+	config util.Config,
+	store db.Store,
+	distributor worker.TaskDistributor,
+	tokenMaker token.Maker,
+	rateLimiter ratelimit.Limiter,
+) *Server {
+	rateLimits, err := methodRateLimits(config)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	return &Server{config: config, store: store, tokenMaker: tokenMaker, taskDistributor: distributor}
+	return &Server{
+		config:          config,
+		store:           store,
+		tokenMaker:      tokenMaker,
+		taskDistributor: distributor,
+		rateLimiter:     rateLimiter,
+		rateLimits:      rateLimits,
+	}
 }
@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net/http"
+)
+
+// clientCertSubjectHeader carries the subject of the client certificate the gateway verified to the grpc server
+const clientCertSubjectHeader = "x-client-cert-subject"
+
+type serviceIdentityKey struct{}
+
+// serviceIdentityFromContext returns the client certificate subject of the trusted service making the call
+func serviceIdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(serviceIdentityKey{}).(string)
+	return identity, ok && identity != ""
+}
+
+// serviceIdentity is the subject of the verified client certificate of a mutual TLS call,
+// calls through the gateway carry the one the gateway verified in the metadata
+func serviceIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if p.Addr.Network() == gatewayNetwork {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(clientCertSubjectHeader); len(values) > 0 {
+				return values[0]
+			}
+		}
+		return ""
+	}
+
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		if chains := tlsInfo.State.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			return chains[0][0].Subject.String()
+		}
+	}
+	return ""
+}
+
+// HttpClientCert passes the subject of the verified client certificate to the gateway,
+// a header with the same name sent by the client is dropped so it can't claim an identity
+func HttpClientCert(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		req.Header.Del(clientCertSubjectHeader)
+		if req.TLS != nil {
+			if chains := req.TLS.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+				req.Header.Set(clientCertSubjectHeader, chains[0][0].Subject.String())
+			}
+		}
+
+		handler.ServeHTTP(res, req)
+	})
+}
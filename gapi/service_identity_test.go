@@ -0,0 +1,183 @@
+package gapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mariobasic/simplebank/db/mock"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testServiceSubject = pkix.Name{CommonName: "payments", Organization: []string{"simplebank"}}
+
+type testAddr string
+
+func (a testAddr) Network() string { return string(a) }
+func (a testAddr) String() string  { return string(a) }
+
+func verifiedState(subject pkix.Name) tls.ConnectionState {
+	return tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: subject}}}}
+}
+
+func newContextWithClientCert(subject pkix.Name) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 50000},
+		AuthInfo: credentials.TLSInfo{State: verifiedState(subject)},
+	})
+}
+
+func newGatewayContext(md metadata.MD) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: testAddr(gatewayNetwork)})
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+func TestServiceIdentity(t *testing.T) {
+	subject := testServiceSubject.String()
+
+	require.Equal(t, subject, serviceIdentity(newContextWithClientCert(testServiceSubject)))
+	require.Equal(t, subject, serviceIdentity(newGatewayContext(metadata.Pairs(clientCertSubjectHeader, subject))))
+	require.Empty(t, serviceIdentity(newGatewayContext(metadata.MD{})))
+	require.Empty(t, serviceIdentity(context.Background()))
+
+	// only the gateway is trusted to pass the subject in the metadata
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 50000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(clientCertSubjectHeader, subject))
+	require.Empty(t, serviceIdentity(ctx))
+}
+
+func TestHttpClientCert(t *testing.T) {
+	var subject string
+	handler := HttpClientCert(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		subject = req.Header.Get(clientCertSubjectHeader)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	req.Header.Set(clientCertSubjectHeader, "CN=spoofed")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Empty(t, subject)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	req.Header.Set(clientCertSubjectHeader, "CN=spoofed")
+	state := verifiedState(testServiceSubject)
+	req.TLS = &state
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, testServiceSubject.String(), subject)
+}
+
+func TestGatewayHeaderMatcher(t *testing.T) {
+	key, ok := GatewayHeaderMatcher("X-Client-Cert-Subject")
+	require.True(t, ok)
+	require.Equal(t, clientCertSubjectHeader, key)
+
+	_, ok = GatewayHeaderMatcher("Grpc-Metadata-X-Client-Cert-Subject")
+	require.False(t, ok)
+
+	_, ok = GatewayHeaderMatcher("Grpc-Metadata-X-Forwarded-For")
+	require.False(t, ok)
+}
+
+func TestServer_AuthInterceptorService(t *testing.T) {
+	user, _ := randomUser(t)
+
+	tests := []struct {
+		name     string
+		services []string
+		method   string
+		ctx      func(t *testing.T, server *Server) context.Context
+		code     codes.Code
+	}{
+		{
+			name:     "TrustedService",
+			services: []string{testServiceSubject.String()},
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return newContextWithClientCert(testServiceSubject)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "TrustedServiceThroughGateway",
+			services: []string{testServiceSubject.String()},
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return newGatewayContext(metadata.Pairs(clientCertSubjectHeader, testServiceSubject.String()))
+			},
+			code: codes.OK,
+		},
+		{
+			name: "UntrustedService",
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return newContextWithClientCert(testServiceSubject)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:     "NoClientCert",
+			services: []string{testServiceSubject.String()},
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "MethodNotOpenToServices",
+			services: []string{testServiceSubject.String()},
+			method:   pb.SimpleBank_ListAccounts_FullMethodName,
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return newContextWithClientCert(testServiceSubject)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "AccessTokenTakesPrecedence",
+			services: []string{testServiceSubject.String()},
+			ctx: func(t *testing.T, server *Server) context.Context {
+				// the expired token is rejected instead of falling back to the client certificate
+				ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, util.DepositorRole, -time.Minute)
+				md, _ := metadata.FromIncomingContext(ctx)
+				return metadata.NewIncomingContext(newContextWithClientCert(testServiceSubject), md)
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			stubSessions(store)
+
+			server := NewTestServer(t, store, nil)
+			server.config.TLS.Services = tt.services
+
+			method := pb.SimpleBank_GetAccount_FullMethodName
+			if tt.method != "" {
+				method = tt.method
+			}
+
+			var identity string
+			info := &grpc.UnaryServerInfo{FullMethod: method}
+			_, err := server.AuthInterceptor(tt.ctx(t, server), nil, info, func(ctx context.Context, _ any) (any, error) {
+				identity, _ = serviceIdentityFromContext(ctx)
+				return nil, nil
+			})
+			require.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.Equal(t, testServiceSubject.String(), identity)
+			}
+		})
+	}
+}
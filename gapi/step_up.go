@@ -0,0 +1,122 @@
+package gapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"github.com/hibiken/asynq"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/mfa"
+	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/ratelimit"
+	"github.com/mariobasic/simplebank/worker"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// stepUpMaxAttempts wrong codes burn a challenge, the transfer has to be started again
+const stepUpMaxAttempts = 5
+
+// transferNeedsStepUp tells if the amount is above the configured threshold
+func (s *Server) transferNeedsStepUp(amount int64) bool {
+	threshold := s.config.StepUp.TransferThreshold
+	return threshold > 0 && amount > threshold
+}
+
+// startTransferChallenge emails a code for the transfer, the response carries the challenge
+// to send the transfer again with
+func (s *Server) startTransferChallenge(ctx context.Context, username string, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	if err := s.limitStepUpCodes(ctx, username); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.GetUser(ctx, username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	code, err := mfa.GenerateEmailCode()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	challenge, err := s.store.CreateTransferChallenge(ctx, db.CreateTransferChallengeParams{
+		Username:      user.Username,
+		Email:         user.Email,
+		SecretCode:    code,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+		ExpiredAt:     time.Now().Add(s.config.StepUp.CodeDuration),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create transfer challenge: %s", err)
+	}
+
+	// the code expires soon, retrying for longer is pointless
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Queue(worker.QueueCritical),
+	}
+	err = s.taskDistributor.DistributeTaskSendTransferCode(ctx, &worker.PayloadSendTransferCode{ChallengeID: challenge.ID}, opts...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send transfer code: %s", err)
+	}
+
+	return &pb.CreateTransferResponse{
+		StepUpRequired:     true,
+		ChallengeId:        challenge.ID,
+		ChallengeExpiresAt: timestamppb.New(challenge.ExpiredAt),
+	}, nil
+}
+
+// limitStepUpCodes caps the codes emailed to a user, on top of the rate limit of the method
+func (s *Server) limitStepUpCodes(ctx context.Context, username string) error {
+	if s.config.StepUp.MaxCodes <= 0 {
+		return nil
+	}
+
+	limit := ratelimit.Limit{Requests: s.config.StepUp.MaxCodes, Window: s.config.StepUp.CodeWindow}
+	result, err := s.rateLimiter.Allow(ctx, "step_up_code:user:"+username, limit)
+	if err != nil {
+		// an unavailable limiter must not block transfers
+		log.Ctx(ctx).Error().Err(err).Str("username", username).Msg("failed to check step-up code limit")
+		return nil
+	}
+	if !result.Allowed {
+		return resourceExhaustedError(result.RetryAfter)
+	}
+	return nil
+}
+
+// checkTransferChallenge counts an attempt at the challenge and accepts the code when the challenge was issued
+// to the user for this very transfer, TransferTx uses the challenge up
+func (s *Server) checkTransferChallenge(ctx context.Context, username string, req *pb.CreateTransferRequest) error {
+	challenge, err := s.store.AttemptTransferChallenge(ctx, db.AttemptTransferChallengeParams{
+		ID:          req.GetChallengeId(),
+		Username:    username,
+		MaxAttempts: stepUpMaxAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return status.Error(codes.PermissionDenied, "challenge is invalid, expired or used up")
+		}
+		return status.Errorf(codes.Internal, "failed to get transfer challenge: %s", err)
+	}
+
+	if challenge.FromAccountID != req.GetFromAccountId() ||
+		challenge.ToAccountID != req.GetToAccountId() ||
+		challenge.Amount != req.GetAmount() ||
+		challenge.Currency != req.GetCurrency() {
+		return status.Error(codes.PermissionDenied, "challenge was issued for another transfer")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(challenge.SecretCode), []byte(req.GetCode())) != 1 {
+		return status.Error(codes.PermissionDenied, "incorrect code")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package gapi
+
+import (
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// HttpSpanName names the HTTP server spans by the method alone, the raw path would give every
+// account a span name of its own. GatewayTracing adds the route once the gateway has matched one
+func HttpSpanName(_ string, r *http.Request) string {
+	return r.Method
+}
+
+// HttpTracing records the raw path of the request on the HTTP server span
+func HttpTracing(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		trace.SpanFromContext(req.Context()).SetAttributes(semconv.URLPath(req.URL.Path))
+		handler.ServeHTTP(res, req)
+	})
+}
+
+// GatewayTracing names the HTTP server span after the gateway route, e.g. GET /v1/accounts/{id}
+func GatewayTracing(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		route := gatewayRoute(r)
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+		next(w, r, pathParams)
+	}
+}
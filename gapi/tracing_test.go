@@ -0,0 +1,48 @@
+package gapi
+
+import (
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGatewayTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	mux := runtime.NewServeMux(runtime.WithMiddlewares(GatewayTracing))
+	err := mux.HandlePath(http.MethodGet, "/v1/accounts/{id}", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {})
+	require.NoError(t, err)
+
+	handler := otelhttp.NewHandler(HttpTracing(mux), "http-gateway",
+		otelhttp.WithSpanNameFormatter(HttpSpanName),
+		otelhttp.WithTracerProvider(provider),
+	)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/accounts/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/unknown", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "GET /v1/accounts/{id}", spans[0].Name())
+	attributes := attribute.NewSet(spans[0].Attributes()...)
+	require.Equal(t, "/v1/accounts/{id}", getAttribute(t, attributes, semconv.HTTPRouteKey))
+	require.Equal(t, "/v1/accounts/42", getAttribute(t, attributes, semconv.URLPathKey))
+
+	// no route matched, the raw path stays out of the name
+	require.Equal(t, "GET", spans[1].Name())
+	require.Equal(t, "/v1/unknown", getAttribute(t, attribute.NewSet(spans[1].Attributes()...), semconv.URLPathKey))
+}
+
+func getAttribute(t *testing.T, attributes attribute.Set, key attribute.Key) string {
+	value, ok := attributes.Value(key)
+	require.True(t, ok, "missing attribute %s", key)
+	return value.AsString()
+}
@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const checkTimeout = 3 * time.Second
+
+// Check reports why a dependency isn't usable, nil when it is
+type Check func(ctx context.Context) error
+
+// Checker tells the orchestrator if the process is alive and ready to serve,
+// over grpc.health.v1 and the HTTP /healthz and /readyz routes
+type Checker struct {
+	checks       map[string]Check
+	grpcServer   *health.Server
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(checks map[string]Check) *Checker {
+	grpcServer := health.NewServer()
+	grpcServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return &Checker{checks: checks, grpcServer: grpcServer}
+}
+
+// GrpcServer is the grpc.health.v1 service, it reports the status of the last Run check
+func (c *Checker) GrpcServer() *health.Server {
+	return c.grpcServer
+}
+
+// Check runs all checks in parallel and returns the error of each failed one
+func (c *Checker) Check(ctx context.Context) map[string]error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failed := map[string]error{}
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := check(ctx); err != nil {
+				mu.Lock()
+				failed[name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failed
+}
+
+// Ready is false once shutdown started, or while a dependency is failing
+func (c *Checker) Ready(ctx context.Context) (bool, map[string]error) {
+	if c.shuttingDown.Load() {
+		return false, nil
+	}
+	failed := c.Check(ctx)
+	return len(failed) == 0, failed
+}
+
+// Run keeps the grpc health status up to date until the context is done
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.update(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) update(ctx context.Context) {
+	ready, failed := c.Ready(ctx)
+	for name, err := range failed {
+		log.Error().Err(err).Str("check", name).Msg("health check failed")
+	}
+
+	if c.shuttingDown.Load() {
+		return
+	}
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	c.grpcServer.SetServingStatus("", status)
+}
+
+// Shutdown reports not ready from now on, call it when graceful shutdown starts
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+	c.grpcServer.Shutdown()
+}
+
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// LivenessHandler answers as long as the process can serve HTTP at all
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, http.StatusOK, response{Status: "ok"})
+	})
+}
+
+// ReadinessHandler runs the checks and lists the failing ones
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.shuttingDown.Load() {
+			writeResponse(w, http.StatusServiceUnavailable, response{Status: "shutting down"})
+			return
+		}
+
+		failed := c.Check(r.Context())
+		res := response{Status: "ok", Checks: map[string]string{}}
+		names := make([]string, 0, len(c.checks))
+		for name := range c.checks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			res.Checks[name] = "ok"
+			if err, ok := failed[name]; ok {
+				res.Checks[name] = err.Error()
+			}
+		}
+
+		code := http.StatusOK
+		if len(failed) > 0 {
+			res.Status = "not ready"
+			code = http.StatusServiceUnavailable
+		}
+		writeResponse(w, code, res)
+	})
+}
+
+func writeResponse(w http.ResponseWriter, code int, res response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Error().Err(err).Msg("cannot write health response")
+	}
+}
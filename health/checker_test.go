@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestChecker(dbErr error) *Checker {
+	return NewChecker(map[string]Check{
+		"postgres": func(ctx context.Context) error { return dbErr },
+		"redis":    func(ctx context.Context) error { return nil },
+	})
+}
+
+func serveReadiness(t *testing.T, checker *Checker) (int, response) {
+	recorder := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var res response
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&res))
+	return recorder.Code, res
+}
+
+func grpcStatus(t *testing.T, checker *Checker) healthpb.HealthCheckResponse_ServingStatus {
+	res, err := checker.GrpcServer().Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	return res.GetStatus()
+}
+
+func TestReadinessReady(t *testing.T) {
+	checker := newTestChecker(nil)
+
+	code, res := serveReadiness(t, checker)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", res.Status)
+	require.Equal(t, map[string]string{"postgres": "ok", "redis": "ok"}, res.Checks)
+
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, checker))
+	checker.update(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, grpcStatus(t, checker))
+}
+
+func TestReadinessCheckFailed(t *testing.T) {
+	checker := newTestChecker(errors.New("connection refused"))
+
+	code, res := serveReadiness(t, checker)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "not ready", res.Status)
+	require.Equal(t, "connection refused", res.Checks["postgres"])
+	require.Equal(t, "ok", res.Checks["redis"])
+
+	checker.update(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, checker))
+}
+
+func TestReadinessShuttingDown(t *testing.T) {
+	checker := newTestChecker(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return grpcStatus(t, checker) == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	checker.Shutdown()
+	cancel()
+	<-done
+
+	code, res := serveReadiness(t, checker)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "shutting down", res.Status)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, checker))
+}
+
+func TestLiveness(t *testing.T) {
+	checker := newTestChecker(errors.New("connection refused"))
+
+	recorder := httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
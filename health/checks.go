@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io/fs"
+	"os"
+)
+
+// PostgresCheck pings the database through the pool
+func PostgresCheck(pool *pgxpool.Pool) Check {
+	return func(ctx context.Context) error {
+		if err := pool.Ping(ctx); err != nil {
+			return fmt.Errorf("cannot ping postgres: %w", err)
+		}
+		return nil
+	}
+}
+
+// RedisCheck reaches redis through the asynq inspector, the queues can't be listed without it
+func RedisCheck(inspector *asynq.Inspector) Check {
+	return func(ctx context.Context) error {
+		if _, err := inspector.Queues(); err != nil {
+			return fmt.Errorf("cannot reach redis: %w", err)
+		}
+		return nil
+	}
+}
+
+// MigrationCheck verifies the database schema is at the expected migration version and not dirty
+func MigrationCheck(pool *pgxpool.Pool, expectedVersion uint) Check {
+	return func(ctx context.Context) error {
+		var version int64
+		var dirty bool
+		err := pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err != nil {
+			return fmt.Errorf("cannot get migration version: %w", err)
+		}
+		if dirty {
+			return fmt.Errorf("migration %d is dirty", version)
+		}
+		if uint(version) != expectedVersion {
+			return fmt.Errorf("migration version is %d, expected %d", version, expectedVersion)
+		}
+		return nil
+	}
+}
+
+// LatestMigrationVersion is the version of the last migration in the source, the one the database is expected at
+func LatestMigrationVersion(migrationURL string) (uint, error) {
+	src, err := source.Open(migrationURL)
+	if err != nil {
+		return 0, fmt.Errorf("cannot open migration source: %w", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("cannot read first migration: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("cannot read migration after %d: %w", version, err)
+		}
+		version = next
+	}
+}
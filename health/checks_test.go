@@ -0,0 +1,23 @@
+package health
+
+import (
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLatestMigrationVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"000001_init.up.sql", "000001_init.down.sql", "000003_next.up.sql", "000003_next.down.sql"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o600))
+	}
+
+	version, err := LatestMigrationVersion("file://" + dir)
+	require.NoError(t, err)
+	require.Equal(t, uint(3), version)
+
+	_, err = LatestMigrationVersion("file://" + filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -10,28 +11,54 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mariobasic/simplebank/api"
+	"github.com/mariobasic/simplebank/certs"
 	db "github.com/mariobasic/simplebank/db/sqlc"
 	_ "github.com/mariobasic/simplebank/doc/statik"
 	"github.com/mariobasic/simplebank/gapi"
+	"github.com/mariobasic/simplebank/health"
 	"github.com/mariobasic/simplebank/mail"
+	"github.com/mariobasic/simplebank/metrics"
 	"github.com/mariobasic/simplebank/pb"
+	"github.com/mariobasic/simplebank/ratelimit"
+	"github.com/mariobasic/simplebank/telemetry"
+	"github.com/mariobasic/simplebank/token"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/mariobasic/simplebank/worker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rakyll/statik/fs"
-	"github.com/rs/cors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/encoding/protojson"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// gatewayBufferSize is the buffer of the in-memory connection between the HTTP gateway and the grpc server
+const gatewayBufferSize = 1024 * 1024
+
+// healthCheckInterval is how often the grpc health status is refreshed
+const healthCheckInterval = 5 * time.Second
+
+// defaultCertReloadInterval is how often the certificate files are checked for changes when the config has no interval
+const defaultCertReloadInterval = time.Minute
+
+// tokenFormatJWT selects JWTs instead of the default PASETOs
+const tokenFormatJWT = "jwt"
+
 var interruptSignals = []os.Signal{
 	os.Interrupt,
 	syscall.SIGTERM,
@@ -47,29 +74,47 @@ func main() {
 	if config.Env == "dev" {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
+	// logs of a context without a request logger still go to the global one
+	zerolog.DefaultContextLogger = &log.Logger
 
 	ctx, stop := signal.NotifyContext(context.Background(), interruptSignals...)
 	defer stop()
 
-	pool, err := pgxpool.New(ctx, config.DB.Source)
+	shutdownTracing, err := telemetry.SetupTracing(ctx, config)
 	if err != nil {
-		log.Fatal().Msgf("cannot connect to db: %s", err)
+		log.Fatal().Err(err).Msg("cannot set up tracing")
 	}
 
+	pool := newPool(ctx, config.DB.Source)
+	prometheus.MustRegister(metrics.NewPoolCollector("primary", pool))
+
 	runDBMigration(config.DB.MigrationURL, config.DB.Source)
 
-	store := db.NewStore(pool)
+	waitGroup, ctx := errgroup.WithContext(ctx)
+
+	store := newStore(ctx, waitGroup, pool, config)
 
 	redisOpt := asynq.RedisClientOpt{Addr: config.Server.Redis}
 	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
+	inspector := asynq.NewInspector(redisOpt)
+	prometheus.MustRegister(metrics.NewQueueCollector(inspector))
 
-	waitGroup, ctx := errgroup.WithContext(ctx)
+	checker, servingCtx := newHealthChecker(ctx, waitGroup, pool, inspector, config)
 
-	//runGinServer(config, store) // left to show example of standalone gin server
+	//runGinServer(config, store, taskDistributor) // left to show example of standalone gin server
 	runTaskProcessor(ctx, waitGroup, redisOpt, store, config)
-	runGatewayServer(ctx, waitGroup, config, store, taskDistributor)
-	runGrpcServer(ctx, waitGroup, config, store, taskDistributor)
+	runTaskScheduler(ctx, waitGroup, redisOpt, config)
+	// the gateway calls the grpc server so HTTP requests go through the same interceptors
+	gatewayListener := bufconn.Listen(gatewayBufferSize)
+	tlsConfig := newTLSConfig(ctx, waitGroup, config)
+	tokenMaker, tokenKeys := newTokenMaker(config)
+	// the servers keep serving while the load balancers drain them
+	runGatewayServer(servingCtx, waitGroup, config, gatewayListener, checker, tlsConfig, tokenKeys)
+	runGrpcServer(servingCtx, waitGroup, config, store, taskDistributor, tokenMaker, gatewayListener, checker, tlsConfig)
 	err = waitGroup.Wait()
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		log.Error().Err(shutdownErr).Msg("cannot flush traces")
+	}
 	if err != nil {
 		log.Fatal().Err(err).Msg("error from wait group")
 	}
@@ -81,15 +126,20 @@ func runGrpcServer(
 	config util.Config,
 	store db.Store,
 	taskDistributor worker.TaskDistributor,
+	tokenMaker token.Maker,
+	gatewayListener *bufconn.Listener,
+	checker *health.Checker,
+	tlsConfig *tls.Config,
 ) {
-	server := gapi.NewServer(config, store, taskDistributor)
+	server := gapi.NewServer(config, store, taskDistributor, tokenMaker, newRateLimiter(config))
 
-	grpcLogger := grpc.UnaryInterceptor(gapi.GrpcLogger)
-	grpcServer := grpc.NewServer(grpcLogger)
-
-	//pb.RegisterSimpleBankServer(grpcServer, &pb.UnimplementedSimpleBankServer{})
-	pb.RegisterSimpleBankServer(grpcServer, server)
-	reflection.Register(grpcServer)
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := newGrpcServer(server, checker, opts...)
+	// the gateway connection never leaves the process, it stays in plaintext
+	gatewayServer := newGrpcServer(server, checker)
 
 	listener, err := net.Listen("tcp", config.Server.Grpc)
 	if err != nil {
@@ -109,26 +159,67 @@ func runGrpcServer(
 		return nil
 	})
 
+	waitGroup.Go(func() error {
+		err := gatewayServer.Serve(gatewayListener)
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Error().Err(err).Msg("grpc server failed to serve the gateway")
+			return err
+		}
+		return nil
+	})
+
 	waitGroup.Go(func() error {
 		<-ctx.Done()
 		log.Info().Msg("grpc server gracefully shutting down")
 		grpcServer.GracefulStop()
+		gatewayServer.GracefulStop()
 		log.Info().Msg("grpc server is stopped")
 		return nil
 	})
 
 }
 
+func newGrpcServer(server *gapi.Server, checker *health.Checker, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(gapi.GrpcRequestID, gapi.GrpcLogger, gapi.GrpcMetrics, gapi.ReadConsistency, server.AuthInterceptor, server.RateLimiter),
+		grpc.ChainStreamInterceptor(gapi.GrpcStreamRequestID, server.StreamAuthInterceptor),
+	)
+	grpcServer := grpc.NewServer(opts...)
+
+	//pb.RegisterSimpleBankServer(grpcServer, &pb.UnimplementedSimpleBankServer{})
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	healthpb.RegisterHealthServer(grpcServer, checker.GrpcServer())
+	reflection.Register(grpcServer)
+
+	return grpcServer
+}
+
 func runGatewayServer(
 	ctx context.Context,
 	waitGroup *errgroup.Group,
 	config util.Config,
-	store db.Store,
-	taskDistributor worker.TaskDistributor,
+	gatewayListener *bufconn.Listener,
+	checker *health.Checker,
+	tlsConfig *tls.Config,
+	tokenKeys *token.KeySet,
 ) {
-	server := gapi.NewServer(config, store, taskDistributor)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return gatewayListener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		log.Fatal().Msgf("cannot create gateway grpc client: %s", err)
+	}
 
 	grpcMux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gapi.GatewayHeaderMatcher),
+		runtime.WithErrorHandler(gapi.GatewayErrorHandler),
+		runtime.WithForwardResponseOption(gapi.GatewayETag),
+		runtime.WithMiddlewares(gapi.GatewayTracing, gapi.GatewayMetrics),
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 			MarshalOptions: protojson.MarshalOptions{
 				UseProtoNames: true,
@@ -138,7 +229,7 @@ func runGatewayServer(
 			},
 		}))
 
-	err := pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
+	err = pb.RegisterSimpleBankHandler(ctx, grpcMux, conn)
 	if err != nil {
 		log.Fatal().Msgf("cannot register gateway server handler: %s", err)
 	}
@@ -148,21 +239,43 @@ func runGatewayServer(
 		log.Fatal().Msgf("cannot create statik fs: %s", err)
 	}
 
+	connectHandler := gapi.NewConnectHandler(conn,
+		pb.File_service_simple_bank_proto.Services().ByName("SimpleBank"),
+		healthpb.File_grpc_health_v1_health_proto.Services().ByName("Health"),
+	)
+
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	// gRPC-Web and Connect for browsers
+	mux.Handle("/pb.SimpleBank/", connectHandler)
+	mux.Handle("/grpc.health.v1.Health/", connectHandler)
 	mux.Handle("/swagger-ui/", http.StripPrefix("/swagger-ui/", http.FileServer(swagFs)))
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", checker.LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
+	if tokenKeys != nil {
+		mux.Handle(gapi.JWKSPath, gapi.JWKSHandler(tokenKeys))
+	}
 
-	c := cors.Default()
-	handler := c.Handler(gapi.HttpLogger(mux))
+	c := gapi.NewCors(config.Cors.AllowedOrigins)
+	handler := otelhttp.NewHandler(gapi.HttpTracing(c.Handler(gapi.HttpRequestID(gapi.HttpLogger(gapi.HttpClientCert(mux))))), "http-gateway",
+		otelhttp.WithSpanNameFormatter(gapi.HttpSpanName),
+	)
 
 	httpServer := &http.Server{
-		Handler: handler,
-		Addr:    config.Server.Http,
+		Handler:   gapi.HttpClientIP(config.Server.TrustedProxies)(handler),
+		Addr:      config.Server.Http,
+		TLSConfig: tlsConfig,
 	}
 
 	waitGroup.Go(func() error {
 		log.Info().Msgf("start HTTP gateway server at %s", httpServer.Addr)
-		err = httpServer.ListenAndServe()
+		if tlsConfig != nil {
+			// the certificates come from the TLS config
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
 		if err != nil {
 			log.Err(err).Msg("cannot start HTTP gateway server")
 			return err
@@ -181,19 +294,173 @@ func runGatewayServer(
 			return err
 		}
 		log.Info().Msg("http gateway server is stopped")
+		return conn.Close()
+	})
+}
+
+// newTLSConfig is nil when TLS is disabled, otherwise the certificates reload when their files change
+func newTLSConfig(ctx context.Context, waitGroup *errgroup.Group, config util.Config) *tls.Config {
+	if !config.TLS.Enabled {
+		return nil
+	}
+
+	reloader, err := certs.NewReloader(config.TLS.CertFile, config.TLS.KeyFile, config.TLS.ClientCAFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load certificates")
+	}
+
+	tlsConfig, err := certs.NewServerConfig(reloader, config.TLS.MinVersion, config.TLS.RequireClientCert)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create TLS config")
+	}
+
+	interval := config.TLS.ReloadInterval
+	if interval <= 0 {
+		interval = defaultCertReloadInterval
+	}
+	waitGroup.Go(func() error {
+		reloader.Run(ctx, interval)
 		return nil
 	})
+
+	return tlsConfig
+}
+
+// newTokenMaker signs the tokens with the configured private keys and returns their set to publish as a JWKS.
+// Without keys the tokens use the symmetric key, and any service able to verify them could also mint them
+func newTokenMaker(config util.Config) (token.Maker, *token.KeySet) {
+	if len(config.Token.SigningKeys) == 0 {
+		var tokenMaker token.Maker
+		var err error
+		if config.Token.Format == tokenFormatJWT {
+			tokenMaker, err = token.NewJWTMaker(config.Token.SymmetricKey)
+		} else {
+			tokenMaker, err = token.NewPasetoMaker(config.Token.SymmetricKey)
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot create token maker")
+		}
+		return tokenMaker, nil
+	}
+
+	keys := make([]token.SigningKey, len(config.Token.SigningKeys))
+	for i, keyConfig := range config.Token.SigningKeys {
+		key, err := token.LoadSigningKey(keyConfig.ID, keyConfig.Algorithm, keyConfig.PrivateKeyFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot load token signing key")
+		}
+		key.ActiveFrom = keyConfig.ActiveFrom
+		key.RetireAt = keyConfig.RetireAt
+		keys[i] = key
+	}
+
+	keySet, err := token.NewKeySet(keys...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid token signing keys")
+	}
+
+	var tokenMaker token.Maker
+	if config.Token.Format == tokenFormatJWT {
+		tokenMaker, err = token.NewJWTPublicMaker(keySet)
+	} else {
+		tokenMaker, err = token.NewPasetoPublicMaker(keySet)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create token maker")
+	}
+	return tokenMaker, keySet
+}
+
+// newRateLimiter shares the limits between nodes through redis, the memory store is meant for a single node
+func newRateLimiter(config util.Config) ratelimit.Limiter {
+	if config.RateLimit.Store == "memory" {
+		return ratelimit.NewMemoryLimiter()
+	}
+	return ratelimit.NewRedisLimiter(config.Server.Redis, ratelimit.NewMemoryLimiter())
 }
 
 //goland:noinspection GoUnusedFunction
-func runGinServer(config util.Config, store db.Store) {
-	server := api.NewServer(config, store)
+func runGinServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
+	server := api.NewServer(config, store, taskDistributor)
 	err := server.Start(config.Server.Http)
 	if err != nil {
 		log.Fatal().Msgf("cannot start server: %s", err)
 	}
 }
 
+// newHealthChecker probes the dependencies until shutdown starts, from then on the process reports not ready.
+// The returned context is done once the drain period after that is over, the servers stop then
+func newHealthChecker(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	pool *pgxpool.Pool,
+	inspector *asynq.Inspector,
+	config util.Config,
+) (*health.Checker, context.Context) {
+	migrationVersion, err := health.LatestMigrationVersion(config.DB.MigrationURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot get latest migration version")
+	}
+
+	checker := health.NewChecker(map[string]health.Check{
+		"postgres":  health.PostgresCheck(pool),
+		"redis":     health.RedisCheck(inspector),
+		"migration": health.MigrationCheck(pool, migrationVersion),
+	})
+
+	waitGroup.Go(func() error {
+		checker.Run(ctx, healthCheckInterval)
+		return nil
+	})
+	servingCtx, stopServing := context.WithCancel(context.WithoutCancel(ctx))
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msgf("reporting not ready for shutdown, draining for %s", config.Server.ShutdownDrain)
+		checker.Shutdown()
+		time.Sleep(config.Server.ShutdownDrain)
+		stopServing()
+		return nil
+	})
+
+	return checker, servingCtx
+}
+
+// newPool traces every query of the pool
+func newPool(ctx context.Context, source string) *pgxpool.Pool {
+	poolConfig, err := pgxpool.ParseConfig(source)
+	if err != nil {
+		log.Fatal().Msgf("cannot parse db source: %s", err)
+	}
+	poolConfig.ConnConfig.Tracer = db.NewQueryTracer()
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		log.Fatal().Msgf("cannot connect to db: %s", err)
+	}
+	return pool
+}
+
+// newStore routes reads to the replica when one is configured and keeps measuring its lag
+func newStore(ctx context.Context, waitGroup *errgroup.Group, pool *pgxpool.Pool, config util.Config) db.Store {
+	if config.DB.ReplicaSource == "" {
+		return db.NewStore(pool)
+	}
+
+	replicaPool := newPool(ctx, config.DB.ReplicaSource)
+	prometheus.MustRegister(metrics.NewPoolCollector("replica", replicaPool))
+
+	store := db.NewStoreWithReplica(pool, replicaPool, config.DB.MaxReplicaLag)
+	waitGroup.Go(func() error {
+		log.Info().Msg("start replica lag monitor")
+		store.MonitorReplicaLag(ctx, config.DB.ReplicaLagInterval)
+		replicaPool.Close()
+		log.Info().Msg("replica lag monitor is stopped")
+		return nil
+	})
+
+	return store
+}
+
 func runDBMigration(migrationURL, dbSource string) {
 	migration, err := migrate.New(migrationURL, dbSource)
 	if err != nil {
@@ -230,3 +497,29 @@ func runTaskProcessor(
 		return nil
 	})
 }
+
+func runTaskScheduler(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	redisOpt asynq.RedisClientOpt,
+	config util.Config,
+) {
+	scheduler, err := worker.NewTaskScheduler(redisOpt, config.Partition.Schedule, config.Partition.MonthsAhead)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create task scheduler")
+	}
+
+	log.Info().Msg("start task scheduler")
+	err = scheduler.Start()
+	if err != nil {
+		log.Fatal().Err(err).Msg("task scheduler failed to start")
+	}
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("task scheduler gracefully shutting down")
+		scheduler.Shutdown()
+		log.Info().Msg("task scheduler is stopped")
+		return nil
+	})
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "simplebank"
+
+const (
+	LoginSucceeded = "succeeded"
+	LoginFailed    = "failed"
+	// LoginMfaRequired counts passwords accepted for users with a second factor, their logins finish on verify
+	LoginMfaRequired = "mfa_required"
+)
+
+var (
+	GrpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC requests by method and status code.",
+	}, []string{"method", "code"})
+
+	GrpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Duration of gRPC requests by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	HttpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP gateway requests by method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	HttpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP gateway requests by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	TransfersPosted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_posted_total",
+		Help:      "Transfers posted by currency.",
+	}, []string{"currency"})
+
+	TransferredAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transferred_amount_total",
+		Help:      "Amount transferred by currency, in the smallest unit of the currency.",
+	}, []string{"currency"})
+
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+
+	RefreshTokenReuses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refresh_token_reuses_total",
+		Help:      "Rotated refresh tokens presented again, each one revokes its session.",
+	})
+
+	EmailsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_sent_total",
+		Help:      "Emails sent by the task that sent them.",
+	}, []string{"task"})
+)
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolLabels = []string{"pool"}
+
+	poolAcquiredConns = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "acquired_connections"),
+		"Connections currently in use.", poolLabels, nil)
+	poolIdleConns = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "idle_connections"),
+		"Connections currently idle.", poolLabels, nil)
+	poolTotalConns = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "total_connections"),
+		"Connections currently open.", poolLabels, nil)
+	poolMaxConns = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "max_connections"),
+		"Maximum size of the pool.", poolLabels, nil)
+	poolAcquires = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "acquires_total"),
+		"Successful connection acquires.", poolLabels, nil)
+	poolEmptyAcquires = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "empty_acquires_total"),
+		"Acquires that had to wait for a connection.", poolLabels, nil)
+	poolCanceledAcquires = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "canceled_acquires_total"),
+		"Acquires canceled by their context.", poolLabels, nil)
+	poolAcquireDuration = prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", "acquire_duration_seconds_total"),
+		"Time spent acquiring connections.", poolLabels, nil)
+)
+
+// PoolCollector exports the stats of a pgx pool, name tells the primary and replica pools apart
+type PoolCollector struct {
+	name string
+	pool *pgxpool.Pool
+}
+
+func NewPoolCollector(name string, pool *pgxpool.Pool) *PoolCollector {
+	return &PoolCollector{name: name, pool: pool}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()), c.name)
+	ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stat.IdleConns()), c.name)
+	ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stat.TotalConns()), c.name)
+	ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stat.MaxConns()), c.name)
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()), c.name)
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()), c.name)
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()), c.name)
+	ch <- prometheus.MustNewConstMetric(poolAcquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds(), c.name)
+}
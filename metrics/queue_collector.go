@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	queueSize = prometheus.NewDesc(prometheus.BuildFQName(namespace, "task_queue", "size"),
+		"Tasks in the queue by state.", []string{"queue", "state"}, nil)
+	queueProcessed = prometheus.NewDesc(prometheus.BuildFQName(namespace, "task_queue", "processed_total"),
+		"Tasks processed, successfully or not.", []string{"queue"}, nil)
+	queueFailed = prometheus.NewDesc(prometheus.BuildFQName(namespace, "task_queue", "failed_total"),
+		"Tasks that failed processing.", []string{"queue"}, nil)
+	queueLatency = prometheus.NewDesc(prometheus.BuildFQName(namespace, "task_queue", "latency_seconds"),
+		"Time the oldest pending task has been waiting.", []string{"queue"}, nil)
+)
+
+// QueueCollector exports the depth and failures of the asynq queues
+type QueueCollector struct {
+	inspector *asynq.Inspector
+}
+
+func NewQueueCollector(inspector *asynq.Inspector) *QueueCollector {
+	return &QueueCollector{inspector: inspector}
+}
+
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueSize
+	ch <- queueProcessed
+	ch <- queueFailed
+	ch <- queueLatency
+}
+
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	queues, err := c.inspector.Queues()
+	if err != nil {
+		log.Error().Err(err).Msg("cannot list task queues")
+		return
+	}
+
+	for _, queue := range queues {
+		info, err := c.inspector.GetQueueInfo(queue)
+		if err != nil {
+			log.Error().Err(err).Str("queue", queue).Msg("cannot get task queue info")
+			continue
+		}
+
+		states := map[string]int{
+			"pending":   info.Pending,
+			"active":    info.Active,
+			"scheduled": info.Scheduled,
+			"retry":     info.Retry,
+			"archived":  info.Archived,
+		}
+		for state, size := range states {
+			ch <- prometheus.MustNewConstMetric(queueSize, prometheus.GaugeValue, float64(size), queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(queueProcessed, prometheus.CounterValue, float64(info.ProcessedTotal), queue)
+		ch <- prometheus.MustNewConstMetric(queueFailed, prometheus.CounterValue, float64(info.FailedTotal), queue)
+		ch <- prometheus.MustNewConstMetric(queueLatency, prometheus.GaugeValue, info.Latency.Seconds(), queue)
+	}
+}
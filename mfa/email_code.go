@@ -0,0 +1,18 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+var emailCodeMax = big.NewInt(1_000_000)
+
+// GenerateEmailCode returns a random code of Digits digits to send by email
+func GenerateEmailCode() (string, error) {
+	n, err := rand.Int(rand.Reader, emailCodeMax)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate email code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", Digits, n.Int64()), nil
+}
@@ -0,0 +1,56 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	// RecoveryCodeCount recovery codes are issued when TOTP is enabled, each works once
+	RecoveryCodeCount = 10
+	recoveryCodeSize  = 10 // 80 bits, 16 base32 characters
+	challengeSize     = 32
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes returns codes formatted as xxxx-xxxx-xxxx-xxxx, they are shown once and stored hashed
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("cannot generate recovery code: %w", err)
+		}
+
+		encoded := strings.ToLower(recoveryEncoding.EncodeToString(raw))
+		groups := make([]string, 0, 4)
+		for j := 0; j < len(encoded); j += 4 {
+			groups = append(groups, encoded[j:j+4])
+		}
+		codes[i] = strings.Join(groups, "-")
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode accepts a code typed in upper case, with spaces or without dashes
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.Join(strings.Fields(code), ""))
+	code = strings.ReplaceAll(code, "-", "")
+	if len(code) != 16 {
+		return code
+	}
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+}
+
+// GenerateChallengeToken returns the random token of a login that waits for its second factor
+func GenerateChallengeToken() (string, error) {
+	raw := make([]byte, challengeSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("cannot generate challenge token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
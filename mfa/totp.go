@@ -0,0 +1,96 @@
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TOTP parameters of RFC 6238, the defaults every authenticator app supports
+const (
+	Issuer     = "SimpleBank"
+	Digits     = 6
+	Period     = 30 * time.Second
+	secretSize = 20
+	// skew accepts the codes of the neighbouring steps, the clocks of phones drift
+	skew = 1
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 secret shared with the authenticator app
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("cannot generate TOTP secret: %w", err)
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI is the otpauth URI an authenticator app scans from a QR code
+func ProvisioningURI(secret, username string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + Issuer + ":" + username,
+		RawQuery: query.Encode(),
+	}
+	return uri.String()
+}
+
+// Step is the time step of a moment
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// GenerateCode returns the code of a time step
+func GenerateCode(secret string, step int64) (string, error) {
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation of RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1_000_000), nil
+}
+
+// ValidateCode returns the time step of a code that is valid at t, the caller rejects steps that were already used
+// so a code can't be replayed
+func ValidateCode(secret, code string, t time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - skew; step <= current+skew; step++ {
+		expected, err := GenerateCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
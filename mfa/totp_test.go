@@ -0,0 +1,90 @@
+package mfa
+
+import (
+	"encoding/base32"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// the SHA1 test vectors of RFC 6238 appendix B, truncated to 6 digits
+func TestGenerateCodeRFC6238(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := GenerateCode(secret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	now := time.Now()
+	for _, offset := range []time.Duration{-Period, 0, Period} {
+		code, err := GenerateCode(secret, Step(now.Add(offset)))
+		require.NoError(t, err)
+
+		step, ok := ValidateCode(secret, code, now)
+		require.True(t, ok)
+		require.Equal(t, Step(now.Add(offset)), step)
+	}
+
+	tooOld, err := GenerateCode(secret, Step(now.Add(-3*Period)))
+	require.NoError(t, err)
+	_, ok := ValidateCode(secret, tooOld, now)
+	require.False(t, ok)
+
+	_, ok = ValidateCode(secret, "12345", now)
+	require.False(t, ok)
+	_, ok = ValidateCode("not base32!", "123456", now)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("JBSWY3DPEHPK3PXP", "alice"))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/SimpleBank:alice", uri.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	require.Equal(t, "SimpleBank", uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+	require.Equal(t, "30", uri.Query().Get("period"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, code)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	require.Equal(t, "abcd-efgh-ijkl-mnop", NormalizeRecoveryCode(" ABCD EFGH-IJKL mnop "))
+	require.Equal(t, "abcdefgh", NormalizeRecoveryCode("abcd-efgh"))
+}
+
+func TestGenerateEmailCode(t *testing.T) {
+	for range 20 {
+		code, err := GenerateEmailCode()
+		require.NoError(t, err)
+		require.Regexp(t, `^[0-9]{6}$`, code)
+	}
+}
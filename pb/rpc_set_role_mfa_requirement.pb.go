@@ -0,0 +1,226 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        v5.28.3
+// source: rpc_set_role_mfa_requirement.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SetRoleMfaRequirementRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Role        string `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	MfaRequired bool   `protobuf:"varint,2,opt,name=mfa_required,proto3" json:"mfa_required,omitempty"`
+}
+
+func (x *SetRoleMfaRequirementRequest) Reset() {
+	*x = SetRoleMfaRequirementRequest{}
+	mi := &file_rpc_set_role_mfa_requirement_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetRoleMfaRequirementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetRoleMfaRequirementRequest) ProtoMessage() {}
+
+func (x *SetRoleMfaRequirementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_set_role_mfa_requirement_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetRoleMfaRequirementRequest.ProtoReflect.Descriptor instead.
+func (*SetRoleMfaRequirementRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_set_role_mfa_requirement_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SetRoleMfaRequirementRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *SetRoleMfaRequirementRequest) GetMfaRequired() bool {
+	if x != nil {
+		return x.MfaRequired
+	}
+	return false
+}
+
+type SetRoleMfaRequirementResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Role        string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	MfaRequired bool                   `protobuf:"varint,2,opt,name=mfa_required,proto3" json:"mfa_required,omitempty"`
+	UpdatedBy   string                 `protobuf:"bytes,3,opt,name=updated_by,proto3" json:"updated_by,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *SetRoleMfaRequirementResponse) Reset() {
+	*x = SetRoleMfaRequirementResponse{}
+	mi := &file_rpc_set_role_mfa_requirement_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetRoleMfaRequirementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetRoleMfaRequirementResponse) ProtoMessage() {}
+
+func (x *SetRoleMfaRequirementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_set_role_mfa_requirement_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetRoleMfaRequirementResponse.ProtoReflect.Descriptor instead.
+func (*SetRoleMfaRequirementResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_set_role_mfa_requirement_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetRoleMfaRequirementResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *SetRoleMfaRequirementResponse) GetMfaRequired() bool {
+	if x != nil {
+		return x.MfaRequired
+	}
+	return false
+}
+
+func (x *SetRoleMfaRequirementResponse) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *SetRoleMfaRequirementResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_rpc_set_role_mfa_requirement_proto protoreflect.FileDescriptor
+
+var file_rpc_set_role_mfa_requirement_proto_rawDesc = []byte{
+	0x0a, 0x22, 0x72, 0x70, 0x63, 0x5f, 0x73, 0x65, 0x74, 0x5f, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x6d,
+	0x66, 0x61, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x56, 0x0a, 0x1c, 0x53, 0x65, 0x74,
+	0x52, 0x6f, 0x6c, 0x65, 0x4d, 0x66, 0x61, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x22, 0x0a,
+	0x0c, 0x6d, 0x66, 0x61, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0c, 0x6d, 0x66, 0x61, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65,
+	0x64, 0x22, 0xb3, 0x01, 0x0a, 0x1d, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x6c, 0x65, 0x4d, 0x66, 0x61,
+	0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6d, 0x66, 0x61, 0x5f, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x6d,
+	0x66, 0x61, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x75,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x12, 0x3a, 0x0a, 0x0a, 0x75,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x42, 0x25, 0x5a, 0x23, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x61, 0x72, 0x69, 0x6f, 0x62, 0x61, 0x73, 0x69, 0x63,
+	0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x62, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_set_role_mfa_requirement_proto_rawDescOnce sync.Once
+	file_rpc_set_role_mfa_requirement_proto_rawDescData = file_rpc_set_role_mfa_requirement_proto_rawDesc
+)
+
+func file_rpc_set_role_mfa_requirement_proto_rawDescGZIP() []byte {
+	file_rpc_set_role_mfa_requirement_proto_rawDescOnce.Do(func() {
+		file_rpc_set_role_mfa_requirement_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_set_role_mfa_requirement_proto_rawDescData)
+	})
+	return file_rpc_set_role_mfa_requirement_proto_rawDescData
+}
+
+var file_rpc_set_role_mfa_requirement_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_set_role_mfa_requirement_proto_goTypes = []any{
+	(*SetRoleMfaRequirementRequest)(nil),  // 0: pb.SetRoleMfaRequirementRequest
+	(*SetRoleMfaRequirementResponse)(nil), // 1: pb.SetRoleMfaRequirementResponse
+	(*timestamppb.Timestamp)(nil),         // 2: google.protobuf.Timestamp
+}
+var file_rpc_set_role_mfa_requirement_proto_depIdxs = []int32{
+	2, // 0: pb.SetRoleMfaRequirementResponse.updated_at:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_set_role_mfa_requirement_proto_init() }
+func file_rpc_set_role_mfa_requirement_proto_init() {
+	if File_rpc_set_role_mfa_requirement_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_set_role_mfa_requirement_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_set_role_mfa_requirement_proto_goTypes,
+		DependencyIndexes: file_rpc_set_role_mfa_requirement_proto_depIdxs,
+		MessageInfos:      file_rpc_set_role_mfa_requirement_proto_msgTypes,
+	}.Build()
+	File_rpc_set_role_mfa_requirement_proto = out.File
+	file_rpc_set_role_mfa_requirement_proto_rawDesc = nil
+	file_rpc_set_role_mfa_requirement_proto_goTypes = nil
+	file_rpc_set_role_mfa_requirement_proto_depIdxs = nil
+}
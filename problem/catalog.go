@@ -0,0 +1,128 @@
+package problem
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"net/http"
+)
+
+// Domain is the ErrorInfo domain of the errors of the catalog
+const Domain = "simplebank"
+
+// TypeBaseURI prefixes the code of an error to make its problem type URI
+const TypeBaseURI = "/problems/"
+
+// Type is an entry of the error catalog shared by the gRPC and HTTP layers
+type Type struct {
+	Code       string
+	Title      string
+	HTTPStatus int
+	GrpcCode   codes.Code
+}
+
+// URI is the stable problem type URI of the error
+func (t Type) URI() string {
+	return TypeBaseURI + t.Code
+}
+
+// Error creates a gRPC status error of the type, its ErrorInfo detail lets the gateway render the exact type
+func (t Type) Error(message string, details ...protoadapt.MessageV1) error {
+	st := status.New(t.GrpcCode, message)
+
+	details = append(details, &errdetails.ErrorInfo{Reason: t.Code, Domain: Domain})
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// types of the gRPC codes, the HTTP statuses are the ones grpc-gateway maps them to
+var (
+	Canceled           = Type{Code: "canceled", Title: "Request canceled", HTTPStatus: 499, GrpcCode: codes.Canceled}
+	Unknown            = Type{Code: "unknown", Title: "Unknown error", HTTPStatus: http.StatusInternalServerError, GrpcCode: codes.Unknown}
+	InvalidArgument    = Type{Code: "invalid-argument", Title: "Invalid parameters", HTTPStatus: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	DeadlineExceeded   = Type{Code: "deadline-exceeded", Title: "Deadline exceeded", HTTPStatus: http.StatusGatewayTimeout, GrpcCode: codes.DeadlineExceeded}
+	NotFound           = Type{Code: "not-found", Title: "Resource not found", HTTPStatus: http.StatusNotFound, GrpcCode: codes.NotFound}
+	AlreadyExists      = Type{Code: "already-exists", Title: "Resource already exists", HTTPStatus: http.StatusConflict, GrpcCode: codes.AlreadyExists}
+	PermissionDenied   = Type{Code: "permission-denied", Title: "Permission denied", HTTPStatus: http.StatusForbidden, GrpcCode: codes.PermissionDenied}
+	ResourceExhausted  = Type{Code: "resource-exhausted", Title: "Resource exhausted", HTTPStatus: http.StatusTooManyRequests, GrpcCode: codes.ResourceExhausted}
+	FailedPrecondition = Type{Code: "failed-precondition", Title: "Failed precondition", HTTPStatus: http.StatusBadRequest, GrpcCode: codes.FailedPrecondition}
+	Aborted            = Type{Code: "aborted", Title: "Request aborted", HTTPStatus: http.StatusConflict, GrpcCode: codes.Aborted}
+	OutOfRange         = Type{Code: "out-of-range", Title: "Out of range", HTTPStatus: http.StatusBadRequest, GrpcCode: codes.OutOfRange}
+	Unimplemented      = Type{Code: "unimplemented", Title: "Not implemented", HTTPStatus: http.StatusNotImplemented, GrpcCode: codes.Unimplemented}
+	Internal           = Type{Code: "internal", Title: "Internal error", HTTPStatus: http.StatusInternalServerError, GrpcCode: codes.Internal}
+	Unavailable        = Type{Code: "unavailable", Title: "Service unavailable", HTTPStatus: http.StatusServiceUnavailable, GrpcCode: codes.Unavailable}
+	DataLoss           = Type{Code: "data-loss", Title: "Data loss", HTTPStatus: http.StatusInternalServerError, GrpcCode: codes.DataLoss}
+	Unauthenticated    = Type{Code: "unauthenticated", Title: "Unauthenticated", HTTPStatus: http.StatusUnauthorized, GrpcCode: codes.Unauthenticated}
+)
+
+// types of the errors of the bank
+var (
+	InsufficientFunds = Type{Code: "insufficient-funds", Title: "Insufficient funds", HTTPStatus: http.StatusBadRequest, GrpcCode: codes.FailedPrecondition}
+	RateLimited       = Type{Code: "rate-limited", Title: "Too many requests", HTTPStatus: http.StatusTooManyRequests, GrpcCode: codes.ResourceExhausted}
+	StaleVersion      = Type{Code: "stale-version", Title: "Resource changed since it was read", HTTPStatus: http.StatusPreconditionFailed, GrpcCode: codes.Aborted}
+)
+
+var grpcTypes = map[codes.Code]Type{}
+
+// httpTypes picks one type for the HTTP statuses several gRPC codes map to
+var httpTypes = map[int]Type{
+	499:                            Canceled,
+	http.StatusBadRequest:          InvalidArgument,
+	http.StatusUnauthorized:        Unauthenticated,
+	http.StatusForbidden:           PermissionDenied,
+	http.StatusNotFound:            NotFound,
+	http.StatusConflict:            AlreadyExists,
+	http.StatusTooManyRequests:     ResourceExhausted,
+	http.StatusInternalServerError: Internal,
+	http.StatusNotImplemented:      Unimplemented,
+	http.StatusServiceUnavailable:  Unavailable,
+	http.StatusGatewayTimeout:      DeadlineExceeded,
+}
+
+var catalog = map[string]Type{}
+
+func init() {
+	for _, t := range []Type{
+		Canceled, Unknown, InvalidArgument, DeadlineExceeded, NotFound, AlreadyExists, PermissionDenied,
+		ResourceExhausted, FailedPrecondition, Aborted, OutOfRange, Unimplemented, Internal, Unavailable,
+		DataLoss, Unauthenticated,
+	} {
+		grpcTypes[t.GrpcCode] = t
+		catalog[t.Code] = t
+	}
+
+	for _, t := range []Type{InsufficientFunds, RateLimited, StaleVersion} {
+		catalog[t.Code] = t
+	}
+}
+
+// Lookup finds the type of an error code of the catalog
+func Lookup(code string) (Type, bool) {
+	t, ok := catalog[code]
+	return t, ok
+}
+
+// ForGrpcCode is the type of errors without a code of the catalog
+func ForGrpcCode(code codes.Code) Type {
+	if t, ok := grpcTypes[code]; ok {
+		return t
+	}
+	return Unknown
+}
+
+// ForHTTPStatus is the type of errors that only have an HTTP status, like the ones of the gin API
+func ForHTTPStatus(httpStatus int) Type {
+	if t, ok := httpTypes[httpStatus]; ok {
+		return t
+	}
+
+	t := Unknown
+	t.Title = http.StatusText(httpStatus)
+	t.HTTPStatus = httpStatus
+	return t
+}
@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"encoding/json"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// ContentType is the media type of RFC 7807 problem details
+const ContentType = "application/problem+json"
+
+// InvalidParam is a field violation of a request
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Problem is the RFC 7807 body of every HTTP error response
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code"`
+	RequestID     string         `json:"request_id,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// New creates the problem of an error type
+func New(t Type, detail string) *Problem {
+	return &Problem{
+		Type:   t.URI(),
+		Title:  t.Title,
+		Status: t.HTTPStatus,
+		Detail: detail,
+		Code:   t.Code,
+	}
+}
+
+// FromStatus creates the problem of a gRPC status, the type comes from its ErrorInfo detail when
+// it has one of the catalog, otherwise from its code, field violations come from its BadRequest detail
+func FromStatus(st *status.Status) *Problem {
+	t := ForGrpcCode(st.Code())
+	var invalidParams []InvalidParam
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != Domain {
+				continue
+			}
+			if known, ok := Lookup(d.GetReason()); ok {
+				t = known
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range d.GetFieldViolations() {
+				invalidParams = append(invalidParams, InvalidParam{
+					Name:   violation.GetField(),
+					Reason: violation.GetDescription(),
+				})
+			}
+		}
+	}
+
+	p := New(t, st.Message())
+	p.InvalidParams = invalidParams
+	return p
+}
+
+// Write renders the problem as the response
+func (p *Problem) Write(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
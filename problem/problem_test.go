@@ -0,0 +1,73 @@
+package problem
+
+import (
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	codeTypes := map[string]Type{}
+	for code := codes.OK + 1; code <= codes.Unauthenticated; code++ {
+		typ := ForGrpcCode(code)
+		require.Equalf(t, code, typ.GrpcCode, "no type for %s", code)
+		require.NotEmpty(t, typ.Title)
+		require.NotZero(t, typ.HTTPStatus)
+
+		found, ok := Lookup(typ.Code)
+		require.True(t, ok)
+		require.Equal(t, typ, found)
+		codeTypes[typ.Code] = typ
+	}
+
+	// the codes of the types stay unique, they are the stable part of the type URIs
+	for _, typ := range []Type{InsufficientFunds, RateLimited, StaleVersion} {
+		require.NotContains(t, codeTypes, typ.Code)
+		found, ok := Lookup(typ.Code)
+		require.True(t, ok)
+		require.Equal(t, typ, found)
+	}
+}
+
+func TestTypeError(t *testing.T) {
+	err := InsufficientFunds.Error("account 1: insufficient funds")
+
+	st := status.Convert(err)
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+
+	p := FromStatus(st)
+	require.Equal(t, "/problems/insufficient-funds", p.Type)
+	require.Equal(t, InsufficientFunds.Title, p.Title)
+	require.Equal(t, http.StatusBadRequest, p.Status)
+	require.Equal(t, "account 1: insufficient funds", p.Detail)
+	require.Equal(t, InsufficientFunds.Code, p.Code)
+}
+
+func TestFromStatus(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "invalid parameters").WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "username", Description: "must contain only lowercase letters, digits, or underscore"},
+		}},
+		&errdetails.ErrorInfo{Reason: "unknown-reason", Domain: Domain},
+		&errdetails.ErrorInfo{Reason: RateLimited.Code, Domain: "other.domain"},
+	)
+	require.NoError(t, err)
+
+	p := FromStatus(st)
+	require.Equal(t, InvalidArgument.URI(), p.Type)
+	require.Equal(t, http.StatusBadRequest, p.Status)
+	require.Equal(t, []InvalidParam{{Name: "username", Reason: "must contain only lowercase letters, digits, or underscore"}}, p.InvalidParams)
+}
+
+func TestForHTTPStatus(t *testing.T) {
+	require.Equal(t, NotFound, ForHTTPStatus(http.StatusNotFound))
+	require.Equal(t, InvalidArgument, ForHTTPStatus(http.StatusBadRequest))
+
+	typ := ForHTTPStatus(http.StatusTeapot)
+	require.Equal(t, Unknown.Code, typ.Code)
+	require.Equal(t, http.StatusTeapot, typ.HTTPStatus)
+	require.Equal(t, http.StatusText(http.StatusTeapot), typ.Title)
+}
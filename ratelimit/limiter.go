@@ -0,0 +1,22 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Requests requests per Window
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Result tells if a request is allowed, and when a limited client may try again
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type window struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryLimiter counts requests in fixed windows kept in memory, limits are per node
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{windows: map[string]*window{}, now: time.Now}
+}
+
+func (m *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	w, ok := m.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &window{resetAt: now.Add(limit.Window)}
+		m.windows[key] = w
+	}
+
+	w.count++
+	if w.count > limit.Requests {
+		return Result{RetryAfter: w.resetAt.Sub(now)}, nil
+	}
+
+	return Result{Allowed: true}, nil
+}
+
+// sweep drops the expired windows, at most once a minute so a request doesn't pay for it every time
+func (m *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+
+	for key, w := range m.windows {
+		if !now.Before(w.resetAt) {
+			delete(m.windows, key)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Window: time.Minute}
+
+	for i := 0; i < limit.Requests; i++ {
+		result, err := limiter.Allow(context.Background(), "key", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+
+	now = now.Add(10 * time.Second)
+	result, err := limiter.Allow(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 50*time.Second, result.RetryAfter)
+
+	result, err = limiter.Allow(context.Background(), "other", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	now = now.Add(50 * time.Second)
+	result, err = limiter.Allow(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+
+	_, err := limiter.Allow(context.Background(), "key", Limit{Requests: 1, Window: time.Second})
+	require.NoError(t, err)
+	require.Len(t, limiter.windows, 1)
+
+	now = now.Add(2 * time.Minute)
+	_, err = limiter.Allow(context.Background(), "other", Limit{Requests: 1, Window: time.Second})
+	require.NoError(t, err)
+	require.Len(t, limiter.windows, 1)
+	require.Contains(t, limiter.windows, "other")
+}
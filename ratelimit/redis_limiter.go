@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const keyPrefix = "ratelimit:"
+
+// allowScript counts the request in the current window of the key, starting a new window when there is none
+var allowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// RedisLimiter counts requests in fixed windows shared by all nodes
+type RedisLimiter struct {
+	client   *redis.Client
+	fallback Limiter
+}
+
+// NewRedisLimiter uses the fallback limiter while redis is unavailable
+func NewRedisLimiter(address string, fallback Limiter) *RedisLimiter {
+	client := redis.NewClient(&redis.Options{Addr: address})
+	return &RedisLimiter{client: client, fallback: fallback}
+}
+
+func (r *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	result, err := r.allow(ctx, key, limit)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("redis rate limit failed, falling back to memory")
+		return r.fallback.Allow(ctx, key, limit)
+	}
+	return result, nil
+}
+
+func (r *RedisLimiter) allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := allowScript.Run(ctx, r.client, []string{keyPrefix + key}, limit.Window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to count request: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	count, ttl := values[0], values[1]
+	if count > int64(limit.Requests) {
+		return Result{RetryAfter: time.Duration(ttl) * time.Millisecond}, nil
+	}
+
+	return Result{Allowed: true}, nil
+}
+
+func (r *RedisLimiter) Close() error {
+	return r.client.Close()
+}
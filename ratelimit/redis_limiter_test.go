@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRedisLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	limiter := NewRedisLimiter(server.Addr(), NewMemoryLimiter())
+	defer limiter.Close()
+	limit := Limit{Requests: 2, Window: time.Minute}
+
+	for i := 0; i < limit.Requests; i++ {
+		result, err := limiter.Allow(context.Background(), "key", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+	require.True(t, server.Exists(keyPrefix+"key"))
+
+	result, err := limiter.Allow(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Greater(t, result.RetryAfter, time.Duration(0))
+	require.LessOrEqual(t, result.RetryAfter, limit.Window)
+
+	result, err = limiter.Allow(context.Background(), "other", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// the window expires with its key
+	server.FastForward(limit.Window)
+	result, err = limiter.Allow(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
+
+func TestRedisLimiterFallback(t *testing.T) {
+	server := miniredis.RunT(t)
+	limiter := NewRedisLimiter(server.Addr(), NewMemoryLimiter())
+	defer limiter.Close()
+	limit := Limit{Requests: 1, Window: time.Minute}
+
+	server.Close()
+
+	// the memory limiter counts the requests while redis is down
+	result, err := limiter.Allow(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	result, err = limiter.Allow(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+}
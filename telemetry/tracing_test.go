@@ -0,0 +1,33 @@
+package telemetry
+
+import (
+	"context"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSetupTracing(t *testing.T) {
+	for _, exporter := range []string{ExporterNone, ExporterStdout, ExporterOTLP} {
+		t.Run(exporter, func(t *testing.T) {
+			config := util.Config{}
+			config.Tracing.Exporter = exporter
+			config.Tracing.Endpoint = "localhost:4317"
+			config.Tracing.Insecure = true
+			config.Tracing.ServiceName = "simplebank"
+			config.Tracing.SampleRatio = 1
+
+			shutdown, err := SetupTracing(context.Background(), config)
+			require.NoError(t, err)
+			require.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestSetupTracingUnknownExporter(t *testing.T) {
+	config := util.Config{}
+	config.Tracing.Exporter = "jaeger"
+
+	_, err := SetupTracing(context.Background(), config)
+	require.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/base64"
+)
+
+// JSONWebKey is the public part of a signing key as defined by RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y,omitempty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+// JSONWebKeySet lets other services verify tokens without the private keys
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS publishes the public keys of the set that aren't retired
+func (s *KeySet) JWKS() JSONWebKeySet {
+	keys := s.publishedKeys()
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		set.Keys = append(set.Keys, newJSONWebKey(key))
+	}
+	return set
+}
+
+func newJSONWebKey(key SigningKey) JSONWebKey {
+	jwk := JSONWebKey{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+
+	switch publicKey := key.PrivateKey.Public().(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	case *ecdsa.PublicKey:
+		// the coordinates have the fixed size of the curve
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = publicKey.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+	}
+	return jwk
+}
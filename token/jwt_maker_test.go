@@ -2,6 +2,7 @@ package token
 
 import (
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -14,12 +15,13 @@ func TestJWTMaker_VerifyToken(t *testing.T) {
 
 	username := util.RandomOwner()
 	role := util.DepositorRole
+	sessionID := uuid.New()
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, role, duration)
+	token, payload, err := maker.CreateToken(username, role, sessionID, TokenTypeAccess, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -29,6 +31,8 @@ func TestJWTMaker_VerifyToken(t *testing.T) {
 	require.NotEmpty(t, payload)
 
 	require.NotZero(t, payload.ID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, TokenTypeAccess, payload.Type)
 	require.Equal(t, username, payload.Username)
 	require.Equal(t, role, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
@@ -39,7 +43,7 @@ func TestJWTMaker_VerifyTokenExpired(t *testing.T) {
 	maker, err := NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, -time.Minute)
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -51,7 +55,7 @@ func TestJWTMaker_VerifyTokenExpired(t *testing.T) {
 }
 
 func TestJWTMaker_VerifyTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute)
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, time.Minute)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
@@ -0,0 +1,72 @@
+package token
+
+import (
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"time"
+)
+
+var jwtSigningMethods = map[string]jwt.SigningMethod{
+	AlgorithmEdDSA: jwt.SigningMethodEdDSA,
+	AlgorithmES256: jwt.SigningMethodES256,
+}
+
+// JWTPublicMaker signs JSON Web Tokens with the EdDSA or ES256 keys of a key set, the kid header names the key
+type JWTPublicMaker struct {
+	keys *KeySet
+}
+
+func NewJWTPublicMaker(keys *KeySet) (Maker, error) {
+	for _, key := range keys.keys {
+		if _, ok := jwtSigningMethods[key.Algorithm]; !ok {
+			return nil, fmt.Errorf("unsupported JWT algorithm %s of key %s", key.Algorithm, key.ID)
+		}
+	}
+	return &JWTPublicMaker{keys: keys}, nil
+}
+
+func (j *JWTPublicMaker) CreateToken(username string, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	key, err := j.keys.signingKey()
+	if err != nil {
+		return "", payload, err
+	}
+
+	jwtToken := jwt.NewWithClaims(jwtSigningMethods[key.Algorithm], payload)
+	jwtToken.Header["kid"] = key.ID
+	token, err := jwtToken.SignedString(key.PrivateKey)
+	return token, payload, err
+}
+
+func (j *JWTPublicMaker) VerifyToken(token string) (*Payload, error) {
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, func(token *jwt.Token) (interface{}, error) {
+		keyID, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		key, err := j.keys.verificationKey(keyID)
+		if err != nil {
+			return nil, err
+		}
+		// a token can't pick another algorithm than the one of its key
+		if token.Method.Alg() != key.Algorithm {
+			return nil, ErrInvalidToken
+		}
+		return key.PrivateKey.Public(), nil
+	}, jwt.WithValidMethods(j.keys.Algorithms()))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
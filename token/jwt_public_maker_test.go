@@ -0,0 +1,112 @@
+package token
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestJWTPublicMaker_VerifyToken(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmES256} {
+		t.Run(algorithm, func(t *testing.T) {
+			keys, _ := newTestKeySet(t, newTestSigningKey(t, "key", algorithm, time.Now()))
+			maker, err := NewJWTPublicMaker(keys)
+			require.NoError(t, err)
+
+			username := util.RandomOwner()
+			sessionID := uuid.New()
+
+			token, _, err := maker.CreateToken(username, util.DepositorRole, sessionID, TokenTypeAccess, time.Minute)
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Payload{})
+			require.NoError(t, err)
+			require.Equal(t, algorithm, parsed.Header["alg"])
+			require.Equal(t, "key", parsed.Header["kid"])
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, sessionID, payload.SessionID)
+			require.Equal(t, TokenTypeAccess, payload.Type)
+			require.WithinDuration(t, time.Now().Add(time.Minute), payload.ExpiredAt, time.Second)
+		})
+	}
+}
+
+func TestJWTPublicMaker_VerifyTokenExpired(t *testing.T) {
+	keys, _ := newTestKeySet(t, newTestSigningKey(t, "key", AlgorithmEdDSA, time.Now()))
+	maker, err := NewJWTPublicMaker(keys)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTPublicMaker_Rotation(t *testing.T) {
+	edKey := newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now().Add(-time.Hour))
+	ecKey := newTestSigningKey(t, "ec", AlgorithmES256, time.Now().Add(time.Hour))
+	keys, now := newTestKeySet(t, edKey, ecKey)
+	maker, err := NewJWTPublicMaker(keys)
+	require.NoError(t, err)
+
+	edToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, 3*time.Hour)
+	require.NoError(t, err)
+
+	*now = ecKey.ActiveFrom
+	ecToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(ecToken, &Payload{})
+	require.NoError(t, err)
+	require.Equal(t, "ec", parsed.Header["kid"])
+
+	for _, token := range []string{edToken, ecToken} {
+		_, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+	}
+}
+
+func TestJWTPublicMaker_InvalidTokens(t *testing.T) {
+	edKey := newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now())
+	ecKey := newTestSigningKey(t, "ec", AlgorithmES256, time.Now())
+	keys, _ := newTestKeySet(t, edKey, ecKey)
+	maker, err := NewJWTPublicMaker(keys)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomOwner(), util.BankerRole, uuid.New(), TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, kid any, key any) string {
+		jwtToken := jwt.NewWithClaims(method, payload)
+		if kid != nil {
+			jwtToken.Header["kid"] = kid
+		}
+		token, err := jwtToken.SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+
+	otherKey := newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now())
+	for name, token := range map[string]string{
+		"AlgNone":       sign(jwt.SigningMethodNone, "ed", jwt.UnsafeAllowNoneSignatureType),
+		"Symmetric":     sign(jwt.SigningMethodHS256, "ed", []byte(util.RandomString(32))),
+		"WithoutKid":    sign(jwt.SigningMethodEdDSA, nil, edKey.PrivateKey),
+		"UnknownKid":    sign(jwt.SigningMethodEdDSA, "other", edKey.PrivateKey),
+		"KidOfOtherAlg": sign(jwt.SigningMethodEdDSA, "ec", edKey.PrivateKey),
+		"ForgedKey":     sign(jwt.SigningMethodEdDSA, "ed", otherKey.PrivateKey),
+	} {
+		t.Run(name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(token)
+			require.ErrorIs(t, err, ErrInvalidToken)
+			require.Nil(t, payload)
+		})
+	}
+}
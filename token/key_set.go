@@ -0,0 +1,170 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Algorithms of the signing keys, named like the JWT alg header
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmES256 = "ES256"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+	ErrUnknownKey   = errors.New("unknown key id")
+)
+
+// SigningKey is a private key that signs tokens from ActiveFrom on, until RetireAt its tokens still verify.
+// A zero RetireAt keeps the key until it is removed from the set
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	ActiveFrom time.Time
+	RetireAt   time.Time
+}
+
+// LoadSigningKey reads a PKCS #8 PEM private key, an Ed25519 one for EdDSA or a P-256 one for ES256
+func LoadSigningKey(id, algorithm, privateKeyFile string) (SigningKey, error) {
+	data, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("cannot read signing key %s: %w", id, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("no PEM data in signing key file %s", privateKeyFile)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("cannot parse signing key %s: %w", id, err)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return SigningKey{}, fmt.Errorf("signing key %s can't sign", id)
+	}
+	return SigningKey{ID: id, Algorithm: algorithm, PrivateKey: signer}, nil
+}
+
+func (k SigningKey) validate() error {
+	if k.ID == "" {
+		return errors.New("signing key without id")
+	}
+
+	switch key := k.PrivateKey.(type) {
+	case ed25519.PrivateKey:
+		if k.Algorithm != AlgorithmEdDSA {
+			return fmt.Errorf("signing key %s: %s needs an Ed25519 key", k.ID, k.Algorithm)
+		}
+	case *ecdsa.PrivateKey:
+		if k.Algorithm != AlgorithmES256 || key.Curve != elliptic.P256() {
+			return fmt.Errorf("signing key %s: %s needs a P-256 key", k.ID, k.Algorithm)
+		}
+	default:
+		return fmt.Errorf("signing key %s: unsupported key type %T", k.ID, k.PrivateKey)
+	}
+
+	if !k.RetireAt.IsZero() && !k.RetireAt.After(k.ActiveFrom) {
+		return fmt.Errorf("signing key %s retires before it is active", k.ID)
+	}
+	return nil
+}
+
+func (k SigningKey) activeAt(now time.Time) bool {
+	return !now.Before(k.ActiveFrom) && !k.retiredAt(now)
+}
+
+func (k SigningKey) retiredAt(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// KeySet holds the signing keys of a rotation, the most recently activated key signs new tokens
+// while the tokens of the older ones verify until they retire.
+// A key scheduled with a later ActiveFrom is published before it signs, so verifiers know it in advance
+type KeySet struct {
+	keys []SigningKey
+	now  func() time.Time
+}
+
+// NewKeySet checks the keys, their ids must be unique
+func NewKeySet(keys ...SigningKey) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("key set without keys")
+	}
+
+	ids := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if err := key.validate(); err != nil {
+			return nil, err
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("duplicate signing key id %s", key.ID)
+		}
+		ids[key.ID] = true
+	}
+
+	sorted := append([]SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.After(sorted[j].ActiveFrom)
+	})
+	return &KeySet{keys: sorted, now: time.Now}, nil
+}
+
+// signingKey is the active key that was activated last
+func (s *KeySet) signingKey() (SigningKey, error) {
+	now := s.now()
+	for _, key := range s.keys {
+		if key.activeAt(now) {
+			return key, nil
+		}
+	}
+	return SigningKey{}, ErrNoSigningKey
+}
+
+// verificationKey finds the key of a token, tokens of retired keys are rejected
+func (s *KeySet) verificationKey(id string) (SigningKey, error) {
+	now := s.now()
+	for _, key := range s.keys {
+		if key.ID == id && !key.retiredAt(now) {
+			return key, nil
+		}
+	}
+	return SigningKey{}, ErrUnknownKey
+}
+
+// publishedKeys are the keys that aren't retired, including the ones scheduled to sign later
+func (s *KeySet) publishedKeys() []SigningKey {
+	now := s.now()
+	keys := make([]SigningKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if !key.retiredAt(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Algorithms are the algorithms of the keys
+func (s *KeySet) Algorithms() []string {
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, key := range s.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+	return algorithms
+}
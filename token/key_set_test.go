@@ -0,0 +1,150 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestSigningKey(t *testing.T, id string, algorithm string, activeFrom time.Time) SigningKey {
+	key := SigningKey{ID: id, Algorithm: algorithm, ActiveFrom: activeFrom}
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		key.PrivateKey = privateKey
+	case AlgorithmES256:
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		key.PrivateKey = privateKey
+	}
+	return key
+}
+
+// newTestKeySet returns the set with a clock the test can move
+func newTestKeySet(t *testing.T, keys ...SigningKey) (*KeySet, *time.Time) {
+	set, err := NewKeySet(keys...)
+	require.NoError(t, err)
+
+	now := time.Now()
+	set.now = func() time.Time { return now }
+	return set, &now
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	oldKey := newTestSigningKey(t, "old", AlgorithmEdDSA, start)
+	oldKey.RetireAt = start.Add(3 * time.Hour)
+	newKey := newTestSigningKey(t, "new", AlgorithmEdDSA, start.Add(2*time.Hour))
+	set, now := newTestKeySet(t, oldKey, newKey)
+
+	// the new key is published before it signs
+	key, err := set.signingKey()
+	require.NoError(t, err)
+	require.Equal(t, "old", key.ID)
+	require.Len(t, set.JWKS().Keys, 2)
+
+	*now = newKey.ActiveFrom
+	key, err = set.signingKey()
+	require.NoError(t, err)
+	require.Equal(t, "new", key.ID)
+	_, err = set.verificationKey("old")
+	require.NoError(t, err)
+
+	*now = oldKey.RetireAt
+	_, err = set.verificationKey("old")
+	require.ErrorIs(t, err, ErrUnknownKey)
+	require.Len(t, set.JWKS().Keys, 1)
+	require.Equal(t, "new", set.JWKS().Keys[0].KeyID)
+}
+
+func TestKeySet_NoSigningKey(t *testing.T) {
+	set, _ := newTestKeySet(t, newTestSigningKey(t, "later", AlgorithmEdDSA, time.Now().Add(time.Hour)))
+
+	_, err := set.signingKey()
+	require.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestNewKeySet_Invalid(t *testing.T) {
+	now := time.Now()
+	edKey := newTestSigningKey(t, "ed", AlgorithmEdDSA, now)
+	ecKey := newTestSigningKey(t, "ec", AlgorithmES256, now)
+
+	mismatched := edKey
+	mismatched.Algorithm = AlgorithmES256
+	retired := ecKey
+	retired.RetireAt = now.Add(-time.Minute)
+	unnamed := ecKey
+	unnamed.ID = ""
+
+	for name, keys := range map[string][]SigningKey{
+		"NoKeys":           nil,
+		"DuplicateID":      {edKey, edKey},
+		"WrongAlgorithm":   {mismatched},
+		"RetiresBefore":    {retired},
+		"MissingID":        {unnamed},
+		"UnknownAlgorithm": {{ID: "hs", Algorithm: "HS256", PrivateKey: edKey.PrivateKey}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewKeySet(keys...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	key := newTestSigningKey(t, "ec", AlgorithmES256, time.Now())
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "ec.pem")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	loaded, err := LoadSigningKey("ec", AlgorithmES256, file)
+	require.NoError(t, err)
+	require.Equal(t, "ec", loaded.ID)
+	require.True(t, key.PrivateKey.(*ecdsa.PrivateKey).Equal(loaded.PrivateKey))
+
+	_, err = LoadSigningKey("missing", AlgorithmES256, filepath.Join(t.TempDir(), "missing.pem"))
+	require.Error(t, err)
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	edKey := newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now())
+	ecKey := newTestSigningKey(t, "ec", AlgorithmES256, time.Now().Add(-time.Minute))
+	set, _ := newTestKeySet(t, edKey, ecKey)
+
+	jwks := set.JWKS()
+	require.Len(t, jwks.Keys, 2)
+
+	ed := jwks.Keys[0]
+	require.Equal(t, JSONWebKey{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(edKey.PrivateKey.Public().(ed25519.PublicKey)),
+		KeyID:     "ed",
+		Algorithm: AlgorithmEdDSA,
+		Use:       "sig",
+	}, ed)
+
+	ec := jwks.Keys[1]
+	require.Equal(t, "EC", ec.KeyType)
+	require.Equal(t, "P-256", ec.Curve)
+	require.Equal(t, AlgorithmES256, ec.Algorithm)
+	x, err := base64.RawURLEncoding.DecodeString(ec.X)
+	require.NoError(t, err)
+	y, err := base64.RawURLEncoding.DecodeString(ec.Y)
+	require.NoError(t, err)
+	publicKey := ecKey.PrivateKey.Public().(*ecdsa.PublicKey)
+	require.Equal(t, publicKey.X.FillBytes(make([]byte, 32)), x)
+	require.Equal(t, publicKey.Y.FillBytes(make([]byte, 32)), y)
+}
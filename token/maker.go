@@ -1,8 +1,11 @@
 package token
 
-import "time"
+import (
+	"github.com/google/uuid"
+	"time"
+)
 
 type Maker interface {
-	CreateToken(username string, role string, duration time.Duration) (string, *Payload, error)
+	CreateToken(username string, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
@@ -2,6 +2,7 @@ package token
 
 import (
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/o1egl/paseto"
 	"golang.org/x/crypto/chacha20poly1305"
 	"time"
@@ -19,8 +20,8 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 	return &PasetoMaker{symmetricKey: []byte(symmetricKey), paseto: paseto.NewV2()}, nil
 }
 
-func (p *PasetoMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, role, duration)
+func (p *PasetoMaker) CreateToken(username string, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, tokenType, duration)
 	if err != nil {
 		return "", payload, err
 	}
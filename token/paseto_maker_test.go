@@ -1,6 +1,7 @@
 package token
 
 import (
+	"github.com/google/uuid"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -13,12 +14,13 @@ func TestPasetoMaker_CreateAndVerifyToken(t *testing.T) {
 
 	username := util.RandomOwner()
 	role := util.DepositorRole
+	sessionID := uuid.New()
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, role, duration)
+	token, payload, err := maker.CreateToken(username, role, sessionID, TokenTypeAccess, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -28,6 +30,10 @@ func TestPasetoMaker_CreateAndVerifyToken(t *testing.T) {
 	require.NotEmpty(t, payload)
 
 	require.NotZero(t, payload.ID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, TokenTypeAccess, payload.Type)
+	require.NoError(t, payload.CheckType(TokenTypeAccess))
+	require.ErrorIs(t, payload.CheckType(TokenTypeRefresh), ErrWrongTokenType)
 	require.Equal(t, username, payload.Username)
 	require.Equal(t, role, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
@@ -38,7 +44,7 @@ func TestPasetoMaker_CreateAndVerifyTokenExpired(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, -time.Minute)
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
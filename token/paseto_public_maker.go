@@ -0,0 +1,139 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const pasetoV4PublicHeader = "v4.public."
+
+// PasetoPublicMaker signs v4.public PASETOs with the Ed25519 keys of a key set,
+// the key id is in the footer so verifiers pick the key without trying them all
+type PasetoPublicMaker struct {
+	keys *KeySet
+}
+
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+func NewPasetoPublicMaker(keys *KeySet) (Maker, error) {
+	for _, key := range keys.keys {
+		if key.Algorithm != AlgorithmEdDSA {
+			return nil, fmt.Errorf("v4.public needs Ed25519 keys, key %s is %s", key.ID, key.Algorithm)
+		}
+	}
+	return &PasetoPublicMaker{keys: keys}, nil
+}
+
+func (p *PasetoPublicMaker) CreateToken(username string, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	key, err := p.keys.signingKey()
+	if err != nil {
+		return "", payload, err
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+	footer, err := json.Marshal(pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", payload, err
+	}
+
+	signature := ed25519.Sign(key.PrivateKey.(ed25519.PrivateKey), preAuthEncode([]byte(pasetoV4PublicHeader), message, footer, nil))
+	token := pasetoV4PublicHeader +
+		base64.RawURLEncoding.EncodeToString(append(message, signature...)) + "." +
+		base64.RawURLEncoding.EncodeToString(footer)
+	return token, payload, nil
+}
+
+func (p *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	message, err := p.verify(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	if err = json.Unmarshal(message, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err = payload.isValid(); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// verify checks the signature and returns the signed message
+func (p *PasetoPublicMaker) verify(token string) ([]byte, error) {
+	if !strings.HasPrefix(token, pasetoV4PublicHeader) {
+		return nil, errors.New("not a v4.public token")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(token, pasetoV4PublicHeader), ".")
+	if len(parts) != 2 {
+		return nil, errors.New("token without footer")
+	}
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	footer, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, errors.New("token too short")
+	}
+
+	// the footer isn't trusted until the signature is checked, it only names the key to check it with
+	var keyFooter pasetoFooter
+	if err = json.Unmarshal(footer, &keyFooter); err != nil {
+		return nil, err
+	}
+	key, err := p.keys.verificationKey(keyFooter.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+	publicKey := key.PrivateKey.Public().(ed25519.PublicKey)
+	if !ed25519.Verify(publicKey, preAuthEncode([]byte(pasetoV4PublicHeader), message, footer, nil), signature) {
+		return nil, errors.New("invalid signature")
+	}
+	return message, nil
+}
+
+// preAuthEncode is the PAE of the PASETO spec, the signature covers the pieces unambiguously
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	writeLength := func(n int) {
+		var length [8]byte
+		// the most significant bit is cleared for compatibility with languages without unsigned integers
+		binary.LittleEndian.PutUint64(length[:], uint64(n)&^(1<<63))
+		buf.Write(length[:])
+	}
+
+	writeLength(len(pieces))
+	for _, piece := range pieces {
+		writeLength(len(piece))
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
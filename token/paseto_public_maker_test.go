@@ -0,0 +1,134 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasetoPublicMaker_CreateAndVerifyToken(t *testing.T) {
+	keys, _ := newTestKeySet(t, newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now()))
+	maker, err := NewPasetoPublicMaker(keys)
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	role := util.DepositorRole
+	sessionID := uuid.New()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, role, sessionID, TokenTypeAccess, duration)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, TokenTypeAccess, payload.Type)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestPasetoPublicMaker_CreateAndVerifyTokenExpired(t *testing.T) {
+	keys, _ := newTestKeySet(t, newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now()))
+	maker, err := NewPasetoPublicMaker(keys)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicMaker_Rotation(t *testing.T) {
+	oldKey := newTestSigningKey(t, "old", AlgorithmEdDSA, time.Now().Add(-time.Hour))
+	newKey := newTestSigningKey(t, "new", AlgorithmEdDSA, time.Now().Add(time.Hour))
+	keys, now := newTestKeySet(t, oldKey, newKey)
+	maker, err := NewPasetoPublicMaker(keys)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, 3*time.Hour)
+	require.NoError(t, err)
+
+	*now = newKey.ActiveFrom
+	newToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// both keys verify until the old one is removed or retired
+	for _, token := range []string{oldToken, newToken} {
+		_, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+	}
+
+	rotated, _ := newTestKeySet(t, newKey)
+	maker, err = NewPasetoPublicMaker(rotated)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(oldToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestPasetoPublicMaker_InvalidTokens(t *testing.T) {
+	keys, _ := newTestKeySet(t, newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now()))
+	maker, err := NewPasetoPublicMaker(keys)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	otherKeys, _ := newTestKeySet(t, newTestSigningKey(t, "ed", AlgorithmEdDSA, time.Now()))
+	otherMaker, err := NewPasetoPublicMaker(otherKeys)
+	require.NoError(t, err)
+	forged, _, err := otherMaker.CreateToken(util.RandomOwner(), util.BankerRole, uuid.New(), TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	for name, invalid := range map[string]string{
+		"Empty":           "",
+		"OtherVersion":    strings.Replace(token, "v4.public.", "v2.public.", 1),
+		"WithoutFooter":   strings.Join(parts[:3], "."),
+		"UnknownKey":      strings.Join(parts[:3], ".") + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"other"}`)),
+		"TamperedMessage": parts[0] + "." + parts[1] + "." + "A" + parts[2][1:] + "." + parts[3],
+		"ForgedKey":       forged,
+	} {
+		t.Run(name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(invalid)
+			require.ErrorIs(t, err, ErrInvalidToken)
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestNewPasetoPublicMaker_ES256(t *testing.T) {
+	keys, _ := newTestKeySet(t, newTestSigningKey(t, "ec", AlgorithmES256, time.Now()))
+	_, err := NewPasetoPublicMaker(keys)
+	require.Error(t, err)
+}
+
+// TestPreAuthEncode signs the 4-S-1 test vector of the PASETO spec
+func TestPreAuthEncode(t *testing.T) {
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	message := []byte(`{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`)
+
+	signature := ed25519.Sign(secretKey, preAuthEncode([]byte(pasetoV4PublicHeader), message, nil, nil))
+	token := pasetoV4PublicHeader + base64.RawURLEncoding.EncodeToString(append(message, signature...))
+	require.Equal(t, "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9"+
+		"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA", token)
+}
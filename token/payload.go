@@ -8,12 +8,23 @@ import (
 )
 
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrExpiredToken = errors.New("token has expired")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrExpiredToken   = errors.New("token has expired")
+	ErrWrongTokenType = errors.New("wrong token type")
+)
+
+// TokenType tells what a token is for, access and refresh tokens of a session can't be used in place of each other
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
 )
 
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
+	SessionID uuid.UUID `json:"session_id"`
+	Type      TokenType `json:"type"`
 	Username  string    `json:"username"`
 	Role      string    `json:"role"`
 	IssuedAt  time.Time `json:"issued_at"`
@@ -22,7 +33,7 @@ type Payload struct {
 
 }
 
-func NewPayload(username string, role string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -30,6 +41,8 @@ func NewPayload(username string, role string, duration time.Duration) (*Payload,
 
 	return &Payload{
 		ID:        tokenID,
+		SessionID: sessionID,
+		Type:      tokenType,
 		Username:  username,
 		Role:      role,
 		IssuedAt:  time.Now(),
@@ -61,6 +74,14 @@ func (p *Payload) GetAudience() (jwt.ClaimStrings, error) {
 	return jwt.ClaimStrings{"audience"}, nil
 }
 
+// CheckType rejects a valid token issued for another purpose
+func (p *Payload) CheckType(tokenType TokenType) error {
+	if p.Type != tokenType {
+		return ErrWrongTokenType
+	}
+	return nil
+}
+
 func (p *Payload) isValid() error {
 	if time.Now().After(p.ExpiredAt) {
 		return ErrExpiredToken
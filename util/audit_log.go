@@ -0,0 +1,13 @@
+package util
+
+// actions a banker can take on other users, recorded in the audit log
+const (
+	AuditSearchUsers        = "search_users"
+	AuditViewUser           = "view_user"
+	AuditLockUser           = "lock_user"
+	AuditUnlockUser         = "unlock_user"
+	AuditChangeRole         = "change_role"
+	AuditForcePasswordReset = "force_password_reset"
+	AuditSetRoleMfa         = "set_role_mfa"
+	AuditUpdateUser         = "update_user"
+)
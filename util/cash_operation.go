@@ -0,0 +1,9 @@
+package util
+
+// BankCashUsername owns the bank's cash accounts, one per supported currency
+const BankCashUsername = "bank_cash"
+
+const (
+	DepositOperation    = "deposit"    // cash handed in at the counter, moved from the bank cash account
+	WithdrawalOperation = "withdrawal" // cash paid out at the counter, moved to the bank cash account
+)
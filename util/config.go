@@ -1,6 +1,7 @@
 package util
 
 import (
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"strings"
 	"time"
@@ -9,19 +10,74 @@ import (
 type Config struct {
 	Env string `mapstructure:"env"`
 	DB  struct {
-		Source       string `mapstructure:"source"`
-		MigrationURL string `mapstructure:"migration_url"`
+		Source             string        `mapstructure:"source"`
+		MigrationURL       string        `mapstructure:"migration_url"`
+		ReplicaSource      string        `mapstructure:"replica_source"`
+		MaxReplicaLag      time.Duration `mapstructure:"max_replica_lag"`
+		ReplicaLagInterval time.Duration `mapstructure:"replica_lag_interval"`
 	} `mapstructure:"db"`
 	Server struct {
 		Http  string `mapstructure:"http_address"`
 		Grpc  string `mapstructure:"grpc_address"`
 		Redis string `mapstructure:"redis_address"`
+		// TrustedProxies is the number of proxies in front of the HTTP server whose X-Forwarded-For hops are trusted
+		TrustedProxies int `mapstructure:"trusted_proxies"`
+		// ShutdownDrain is how long the servers keep serving after they start reporting not ready
+		ShutdownDrain time.Duration `mapstructure:"shutdown_drain"`
 	} `mapstructure:"server"`
+	Cors struct {
+		AllowedOrigins []string `mapstructure:"allowed_origins"`
+	} `mapstructure:"cors"`
+	TLS struct {
+		Enabled           bool          `mapstructure:"enabled"`
+		CertFile          string        `mapstructure:"cert_file"`
+		KeyFile           string        `mapstructure:"key_file"`
+		ClientCAFile      string        `mapstructure:"client_ca_file"`
+		RequireClientCert bool          `mapstructure:"require_client_cert"`
+		MinVersion        string        `mapstructure:"min_version"`
+		ReloadInterval    time.Duration `mapstructure:"reload_interval"`
+		// Services are the client certificate subjects trusted to call the methods open to services
+		Services []string `mapstructure:"services"`
+	} `mapstructure:"tls"`
 	Token struct {
 		SymmetricKey    string        `mapstructure:"symmetric_key"`
 		AccessDuration  time.Duration `mapstructure:"access_duration"`
 		RefreshDuration time.Duration `mapstructure:"refresh_duration"`
+		// MfaDuration is how long the token of a login waiting for its second factor can be exchanged
+		MfaDuration time.Duration `mapstructure:"mfa_duration"`
+		// Format is paseto or jwt, with signing keys they are v4.public PASETOs or EdDSA/ES256 JWTs
+		Format string `mapstructure:"format"`
+		// SigningKeys sign the tokens instead of the symmetric key, their public keys are published as a JWKS
+		SigningKeys []SigningKeyConfig `mapstructure:"signing_keys"`
 	} `mapstructure:"token"`
+	StepUp struct {
+		// TransferThreshold is the amount above which a transfer needs a code sent by email, 0 turns step-up off
+		TransferThreshold int64         `mapstructure:"transfer_threshold"`
+		CodeDuration      time.Duration `mapstructure:"code_duration"`
+		// MaxCodes codes can be sent to a user per CodeWindow
+		MaxCodes   int           `mapstructure:"max_codes"`
+		CodeWindow time.Duration `mapstructure:"code_window"`
+	} `mapstructure:"step_up"`
+	Partition struct {
+		Schedule        string `mapstructure:"schedule"`
+		MonthsAhead     int    `mapstructure:"months_ahead"`
+		RetentionMonths int    `mapstructure:"retention_months"`
+		ArchiveDir      string `mapstructure:"archive_dir"`
+	} `mapstructure:"partition"`
+	RateLimit struct {
+		Store   string `mapstructure:"store"`
+		Methods map[string]struct {
+			Requests int           `mapstructure:"requests"`
+			Window   time.Duration `mapstructure:"window"`
+		} `mapstructure:"methods"`
+	} `mapstructure:"rate_limit"`
+	Tracing struct {
+		Exporter    string  `mapstructure:"exporter"`
+		Endpoint    string  `mapstructure:"endpoint"`
+		Insecure    bool    `mapstructure:"insecure"`
+		ServiceName string  `mapstructure:"service_name"`
+		SampleRatio float64 `mapstructure:"sample_ratio"`
+	} `mapstructure:"tracing"`
 	Email struct {
 		Sender struct {
 			Name     string `mapstructure:"name"`
@@ -31,6 +87,15 @@ type Config struct {
 	} `mapstructure:"email"`
 }
 
+// SigningKeyConfig schedules a token signing key, it signs from ActiveFrom on and its tokens verify until RetireAt
+type SigningKeyConfig struct {
+	ID             string    `mapstructure:"id"`
+	Algorithm      string    `mapstructure:"algorithm"`
+	PrivateKeyFile string    `mapstructure:"private_key_file"`
+	ActiveFrom     time.Time `mapstructure:"active_from"`
+	RetireAt       time.Time `mapstructure:"retire_at"`
+}
+
 func LoadConfig(path string) (config Config, err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigName("app")
@@ -43,6 +108,10 @@ func LoadConfig(path string) (config Config, err error) {
 		return
 	}
 
-	err = viper.Unmarshal(&config)
+	err = viper.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	)))
 	return
 }
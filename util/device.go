@@ -0,0 +1,25 @@
+package util
+
+import "github.com/mssola/useragent"
+
+// Device is the client a session was created from, parsed from its user agent
+type Device struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	Platform       string
+	Mobile         bool
+}
+
+func ParseDevice(userAgent string) Device {
+	ua := useragent.New(userAgent)
+	browser, version := ua.Browser()
+
+	return Device{
+		Browser:        browser,
+		BrowserVersion: version,
+		OS:             ua.OS(),
+		Platform:       ua.Platform(),
+		Mobile:         ua.Mobile(),
+	}
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseDevice(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		userAgent string
+		want      Device
+	}{
+		{
+			name:      "desktop",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want:      Device{Browser: "Chrome", BrowserVersion: "120.0.0.0", OS: "Intel Mac OS X 10_15_7", Platform: "Macintosh"},
+		},
+		{
+			name:      "mobile",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+			want:      Device{Browser: "Safari", BrowserVersion: "17.0", OS: "CPU iPhone OS 17_0 like Mac OS X", Platform: "iPhone", Mobile: true},
+		},
+		{
+			name:      "grpc client",
+			userAgent: "grpc-go/1.64.0",
+			want:      Device{Browser: "grpc-go", BrowserVersion: "1.64.0"},
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ParseDevice(tt.userAgent))
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -17,3 +19,10 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
+
+// HashToken hashes a random token like a refresh token before it is stored, unlike a password
+// it has too much entropy to be guessed so a fast hash that can be looked up is enough
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
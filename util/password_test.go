@@ -35,3 +35,13 @@ func Test_checkPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestHashToken(t *testing.T) {
+	token := RandomString(32)
+
+	hash := HashToken(token)
+	require.Len(t, hash, 64)
+	require.NotContains(t, hash, token)
+	require.Equal(t, hash, HashToken(token))
+	require.NotEqual(t, hash, HashToken(RandomString(32)))
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"regexp"
+)
+
+type requestIDKey struct{}
+
+var isValidRequestID = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`).MatchString
+
+// RequestIDOrNew keeps a request id sent by the client when it is safe to log, otherwise generates a new one
+func RequestIDOrNew(requestID string) string {
+	if isValidRequestID(requestID) {
+		return requestID
+	}
+	return uuid.NewString()
+}
+
+// WithRequestID stores the request id in the context, along with a logger that adds it to every log
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return log.With().Str("request_id", requestID).Logger().WithContext(ctx)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDOrNew(t *testing.T) {
+	require.Equal(t, "abc-123_x.y", RequestIDOrNew("abc-123_x.y"))
+
+	for _, requestID := range []string{"", "has space", "new\nline", strings.Repeat("a", 129)} {
+		generated := RequestIDOrNew(requestID)
+		require.NotEqual(t, requestID, generated)
+		_, err := uuid.Parse(generated)
+		require.NoError(t, err)
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	require.Empty(t, RequestIDFromContext(context.Background()))
+
+	ctx := WithRequestID(context.Background(), "request-id")
+	require.Equal(t, "request-id", RequestIDFromContext(ctx))
+}
@@ -4,3 +4,25 @@ const (
 	DepositorRole = "depositor" // can only update their own info
 	BankerRole    = "banker"    // can update theirs and other users info
 )
+
+const (
+	AccountOwnerRole  = "owner"  // can transfer money and manage account members
+	AccountSignerRole = "signer" // can view the account and transfer money from it
+	AccountViewerRole = "viewer" // can only view the account
+)
+
+func IsRoleValid(role string) bool {
+	switch role {
+	case DepositorRole, BankerRole:
+		return true
+	}
+	return false
+}
+
+func IsAccountRoleValid(role string) bool {
+	switch role {
+	case AccountOwnerRole, AccountSignerRole, AccountViewerRole:
+		return true
+	}
+	return false
+}
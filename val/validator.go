@@ -2,13 +2,16 @@ package val
 
 import (
 	"fmt"
+	"github.com/mariobasic/simplebank/util"
 	"net/mail"
 	"regexp"
 )
 
 var (
-	isValidUsername = regexp.MustCompile("^[a-z0-9_]+$").MatchString
-	isValidFullName = regexp.MustCompile("^[a-zA-Z -]+$").MatchString
+	isValidUsername    = regexp.MustCompile("^[a-z0-9_]+$").MatchString
+	isValidFullName    = regexp.MustCompile("^[a-zA-Z -]+$").MatchString
+	isValidReference   = regexp.MustCompile("^[a-zA-Z0-9_-]+$").MatchString
+	isValidOneTimeCode = regexp.MustCompile("^[0-9]{6}$").MatchString
 )
 
 func ValidateString(value string, minLength int, maxLength int) error {
@@ -66,3 +69,106 @@ func ValidateEmailId(value int64) error {
 func ValidateSecretCode(value string) error {
 	return ValidateString(value, 32, 128)
 }
+
+func ValidateTotpCode(value string) error {
+	return validateOneTimeCode(value)
+}
+
+// ValidateStepUpCode checks the code emailed to confirm a sensitive operation
+func ValidateStepUpCode(value string) error {
+	return validateOneTimeCode(value)
+}
+
+func validateOneTimeCode(value string) error {
+	if !isValidOneTimeCode(value) {
+		return fmt.Errorf("must contain 6 digits")
+	}
+	return nil
+}
+
+// ValidateMfaCode accepts a TOTP code or a recovery code
+func ValidateMfaCode(value string) error {
+	return ValidateString(value, 6, 32)
+}
+
+func ValidateMfaToken(value string) error {
+	return ValidateString(value, 32, 128)
+}
+
+func ValidateAccountID(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateAmount(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateGoalAmount(value int64) error {
+	if value < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	return nil
+}
+
+func ValidatePocketName(value string) error {
+	return ValidateString(value, 1, 64)
+}
+
+func ValidateReference(value string) error {
+	if err := ValidateString(value, 3, 64); err != nil {
+		return err
+	}
+	if !isValidReference(value) {
+		return fmt.Errorf("must contain only letters, digits, dash, or underscore")
+	}
+	return nil
+}
+
+func ValidateMemo(value string) error {
+	return ValidateString(value, 0, 200)
+}
+
+func ValidateCurrency(value string) error {
+	if !util.IsCurrencyValid(value) {
+		return fmt.Errorf("unsupported currency")
+	}
+	return nil
+}
+
+func ValidatePageID(value int32) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidatePageSize(value int32) error {
+	if value < 1 || value > 10 {
+		return fmt.Errorf("must be from 1 to 10")
+	}
+	return nil
+}
+
+func ValidateRole(value string) error {
+	if !util.IsRoleValid(value) {
+		return fmt.Errorf("unsupported role")
+	}
+	return nil
+}
+
+func ValidateAccountRole(value string) error {
+	if !util.IsAccountRoleValid(value) {
+		return fmt.Errorf("unsupported account role")
+	}
+	return nil
+}
+
+func ValidateSearch(value string) error {
+	return ValidateString(value, 0, 100)
+}
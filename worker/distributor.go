@@ -7,6 +7,9 @@ import (
 
 type TaskDistributor interface {
 	DistributeTaskSendVerifyEmail(ctx context.Context, payload *PayloadSendVerifyEmail, opts ...asynq.Option) error
+	DistributeTaskSendAccountInvitation(ctx context.Context, payload *PayloadSendAccountInvitation, opts ...asynq.Option) error
+	DistributeTaskSendLoginAlert(ctx context.Context, payload *PayloadSendLoginAlert, opts ...asynq.Option) error
+	DistributeTaskSendTransferCode(ctx context.Context, payload *PayloadSendTransferCode, opts ...asynq.Option) error
 }
 
 type RedisTaskDistributor struct {
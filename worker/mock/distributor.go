@@ -36,6 +36,63 @@ func (m *MockTaskDistributor) EXPECT() *MockTaskDistributorMockRecorder {
 	return m.recorder
 }
 
+// DistributeTaskSendAccountInvitation mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendAccountInvitation(arg0 context.Context, arg1 *worker.PayloadSendAccountInvitation, arg2 ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendAccountInvitation", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendAccountInvitation indicates an expected call of DistributeTaskSendAccountInvitation.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendAccountInvitation(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendAccountInvitation", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendAccountInvitation), varargs...)
+}
+
+// DistributeTaskSendLoginAlert mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendLoginAlert(arg0 context.Context, arg1 *worker.PayloadSendLoginAlert, arg2 ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendLoginAlert", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendLoginAlert indicates an expected call of DistributeTaskSendLoginAlert.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendLoginAlert(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendLoginAlert", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendLoginAlert), varargs...)
+}
+
+// DistributeTaskSendTransferCode mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendTransferCode(arg0 context.Context, arg1 *worker.PayloadSendTransferCode, arg2 ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendTransferCode", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendTransferCode indicates an expected call of DistributeTaskSendTransferCode.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendTransferCode(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendTransferCode", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendTransferCode), varargs...)
+}
+
 // DistributeTaskSendVerifyEmail mocks base method.
 func (m *MockTaskDistributor) DistributeTaskSendVerifyEmail(arg0 context.Context, arg1 *worker.PayloadSendVerifyEmail, arg2 ...asynq.Option) error {
 	m.ctrl.T.Helper()
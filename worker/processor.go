@@ -17,6 +17,10 @@ type TaskProcessor interface {
 	Start() error
 	Shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendAccountInvitation(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendLoginAlert(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendTransferCode(ctx context.Context, task *asynq.Task) error
+	ProcessTaskCreatePartitions(ctx context.Context, task *asynq.Task) error
 }
 type RedisTaskProcessor struct {
 	server *asynq.Server
@@ -30,7 +34,7 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer
 		asynq.Config{
 			Queues: map[string]int{QueueCritical: 6, QueueDefault: 5},
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
-				log.Err(err).
+				log.Ctx(taskContext(ctx, task)).Err(err).
 					Str("type", task.Type()).
 					Bytes("payload", task.Payload()).
 					Msg("process task failed")
@@ -47,8 +51,13 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer
 
 func (r *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
+	mux.Use(withTaskContext)
 
 	mux.HandleFunc(TaskSendVerifyEmail, r.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskSendAccountInvitation, r.ProcessTaskSendAccountInvitation)
+	mux.HandleFunc(TaskSendLoginAlert, r.ProcessTaskSendLoginAlert)
+	mux.HandleFunc(TaskSendTransferCode, r.ProcessTaskSendTransferCode)
+	mux.HandleFunc(TaskCreatePartitions, r.ProcessTaskCreatePartitions)
 	return r.server.Start(mux)
 }
 
@@ -0,0 +1,23 @@
+package worker
+
+import (
+	"github.com/hibiken/asynq"
+)
+
+// NewTaskScheduler enqueues the periodic partition maintenance task on the given cron spec
+func NewTaskScheduler(redisOpt asynq.RedisClientOpt, cronspec string, monthsAhead int) (*asynq.Scheduler, error) {
+	scheduler := asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		Logger: NewLogger(),
+	})
+
+	task, err := NewTaskCreatePartitions(&PayloadCreatePartitions{MonthsAhead: monthsAhead}, asynq.Queue(QueueDefault))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = scheduler.Register(cronspec, task); err != nil {
+		return nil, err
+	}
+
+	return scheduler, nil
+}
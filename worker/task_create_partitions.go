@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hibiken/asynq"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const TaskCreatePartitions = "task:create_partitions"
+
+type PayloadCreatePartitions struct {
+	MonthsAhead int `json:"months_ahead"`
+}
+
+func NewTaskCreatePartitions(payload *PayloadCreatePartitions, opts ...asynq.Option) (*asynq.Task, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to jsonPayload payload: %w", err)
+	}
+	return asynq.NewTask(TaskCreatePartitions, jsonPayload, opts...), nil
+}
+
+// ProcessTaskCreatePartitions makes sure the partitions of the current month and the next MonthsAhead months exist,
+// so new rows never end up in the default partition
+func (r *RedisTaskProcessor) ProcessTaskCreatePartitions(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadCreatePartitions
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("cannot unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	now := time.Now().UTC()
+	for i := 0; i <= payload.MonthsAhead; i++ {
+		month := time.Date(now.Year(), now.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		for _, parent := range db.PartitionedTables {
+			partition, err := r.store.CreateMonthlyPartition(ctx, db.CreateMonthlyPartitionParams{
+				Parent: parent,
+				Month:  month,
+			})
+			if err != nil {
+				return fmt.Errorf("cannot create %s partition for %s: %w", parent, month.Format("2006-01"), err)
+			}
+
+			log.Ctx(ctx).Info().
+				Str("type", task.Type()).
+				Str("partition", partition).
+				Msg("ensured partition exists")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hibiken/asynq"
+	"github.com/mariobasic/simplebank/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/mariobasic/simplebank/worker"
+
+var tracer = otel.Tracer(tracerName)
+
+// TaskMetadata is embedded in the payloads of tasks enqueued by a request,
+// it carries the request id and the trace context into the worker
+type TaskMetadata struct {
+	RequestID    string            `json:"request_id,omitempty"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+func newTaskMetadata(ctx context.Context) TaskMetadata {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	return TaskMetadata{RequestID: util.RequestIDFromContext(ctx), TraceContext: carrier}
+}
+
+// taskContext restores the request id and the trace context of the task payload into the context
+func taskContext(ctx context.Context, task *asynq.Task) context.Context {
+	var metadata TaskMetadata
+	if err := json.Unmarshal(task.Payload(), &metadata); err != nil {
+		return ctx
+	}
+
+	if metadata.RequestID != "" {
+		ctx = util.WithRequestID(ctx, metadata.RequestID)
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(metadata.TraceContext))
+}
+
+// withTaskContext processes every task in a span under the one that enqueued it,
+// with the id of the request that enqueued it in the logs
+func withTaskContext(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		ctx, span := tracer.Start(taskContext(ctx, task), "process "+task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(semconv.MessagingSystemKey.String("asynq"), semconv.MessagingOperationTypeDeliver),
+		)
+
+		err := next.ProcessTask(ctx, task)
+		endSpan(span, err)
+		return err
+	})
+}
+
+func startEnqueueSpan(ctx context.Context, taskType string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "enqueue "+taskType,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingSystemKey.String("asynq"), semconv.MessagingOperationTypePublish),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hibiken/asynq"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func TestTaskContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	ctx, parent := otel.Tracer("test").Start(util.WithRequestID(context.Background(), "request-id"), "CreateUser")
+	payload := PayloadSendVerifyEmail{TaskMetadata: newTaskMetadata(ctx), Username: util.RandomOwner()}
+	parent.End()
+
+	jsonPayload, err := json.Marshal(payload)
+	require.NoError(t, err)
+	task := asynq.NewTask(TaskSendVerifyEmail, jsonPayload)
+
+	handler := withTaskContext(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		require.Equal(t, "request-id", util.RequestIDFromContext(ctx))
+		return nil
+	}))
+	require.NoError(t, handler.ProcessTask(context.Background(), task))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	process := spans[1]
+	require.Equal(t, "process "+TaskSendVerifyEmail, process.Name())
+	require.Equal(t, parent.SpanContext().TraceID(), process.SpanContext().TraceID())
+	require.Equal(t, parent.SpanContext().SpanID(), process.Parent().SpanID())
+}
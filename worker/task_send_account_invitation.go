@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hibiken/asynq"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/rs/zerolog/log"
+	"html"
+)
+
+const TaskSendAccountInvitation = "task:send_account_invitation"
+
+type PayloadSendAccountInvitation struct {
+	TaskMetadata
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+}
+
+func (r *RedisTaskDistributor) DistributeTaskSendAccountInvitation(ctx context.Context, payload *PayloadSendAccountInvitation, opts ...asynq.Option) (err error) {
+	ctx, span := startEnqueueSpan(ctx, TaskSendAccountInvitation)
+	defer func() { endSpan(span, err) }()
+
+	payload.TaskMetadata = newTaskMetadata(ctx)
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to jsonPayload payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendAccountInvitation, jsonPayload, opts...)
+	info, err := r.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Bytes("payload", jsonPayload).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+func (r *RedisTaskProcessor) ProcessTaskSendAccountInvitation(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendAccountInvitation
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("cannot unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	// the member row is created in the same tx that enqueues this task,
+	// so a missing row most likely means the tx is not committed yet and we want to retry
+	member, err := r.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: payload.AccountID,
+		Username:  payload.Username,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get member %s of account %d: %w", payload.Username, payload.AccountID, err)
+	}
+
+	account, err := r.store.GetAccount(ctx, member.AccountID)
+	if err != nil {
+		return fmt.Errorf("cannot get account %d: %w", member.AccountID, err)
+	}
+
+	user, err := r.store.GetUser(ctx, member.Username)
+	if err != nil {
+		return fmt.Errorf("cannot get user %s: %w", member.Username, err)
+	}
+
+	err = r.sendAccountInvitationEmail(user, account, member)
+	if err != nil {
+		return fmt.Errorf("cannot send account invitation email: %w", err)
+	}
+	metrics.EmailsSent.WithLabelValues(task.Type()).Inc()
+
+	log.Ctx(ctx).Info().
+		Str("task", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("email", user.Email).
+		Msg("processed task")
+
+	return nil
+}
+
+func (r *RedisTaskProcessor) sendAccountInvitationEmail(user db.User, account db.Account, member db.AccountMember) error {
+	subject := "You have been added to a Simple Bank account"
+	accountUrl := fmt.Sprintf("http://localhost:8080/accounts/%d", account.ID)
+	content := fmt.Sprintf(`Hello %s,<br/>
+	%s has added you as %s of the %s account #%d.<br/>
+	You can <a href="%s">view the account here</a> after you log in.<br/>`,
+		html.EscapeString(user.FullName), html.EscapeString(member.InvitedBy), member.Role, account.Currency, account.ID, accountUrl)
+	to := []string{user.Email}
+
+	return r.mailer.Send(subject, content, to, nil, nil, nil)
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSendAccountInvitationEmailEscapesNames(t *testing.T) {
+	mailer := &recordingMailer{}
+	processor := &RedisTaskProcessor{mailer: mailer}
+
+	user := db.User{Username: util.RandomOwner(), FullName: `<a href="https://evil.example">Bob</a>`, Email: util.RandomEmail()}
+	account := db.Account{ID: util.RandomInt(1, 1000), Currency: util.USD}
+	member := db.AccountMember{AccountID: account.ID, Username: user.Username, Role: util.AccountViewerRole, InvitedBy: `<img src=x>`}
+
+	err := processor.sendAccountInvitationEmail(user, account, member)
+	require.NoError(t, err)
+	require.Len(t, mailer.bodies, 1)
+
+	body := mailer.bodies[0]
+	require.NotContains(t, body, `<a href="https://evil.example">`)
+	require.Contains(t, body, `&lt;a href=&#34;https://evil.example&#34;&gt;Bob&lt;/a&gt;`)
+	require.Contains(t, body, `&lt;img src=x&gt;`)
+}
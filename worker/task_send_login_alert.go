@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/rs/zerolog/log"
+	"html"
+	"time"
+)
+
+const TaskSendLoginAlert = "task:send_login_alert"
+
+type PayloadSendLoginAlert struct {
+	TaskMetadata
+	SessionID   uuid.UUID `json:"session_id"`
+	NewDevice   bool      `json:"new_device"`
+	NewClientIP bool      `json:"new_client_ip"`
+}
+
+func (r *RedisTaskDistributor) DistributeTaskSendLoginAlert(ctx context.Context, payload *PayloadSendLoginAlert, opts ...asynq.Option) (err error) {
+	ctx, span := startEnqueueSpan(ctx, TaskSendLoginAlert)
+	defer func() { endSpan(span, err) }()
+
+	payload.TaskMetadata = newTaskMetadata(ctx)
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to jsonPayload payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendLoginAlert, jsonPayload, opts...)
+	info, err := r.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Bytes("payload", jsonPayload).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+func (r *RedisTaskProcessor) ProcessTaskSendLoginAlert(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendLoginAlert
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("cannot unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	session, err := r.store.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		return fmt.Errorf("cannot get session %s: %w", payload.SessionID, err)
+	}
+
+	user, err := r.store.GetUser(ctx, session.Username)
+	if err != nil {
+		return fmt.Errorf("cannot get user %s: %w", session.Username, err)
+	}
+
+	err = r.sendLoginAlertEmail(user, session, payload)
+	if err != nil {
+		return fmt.Errorf("cannot send login alert email: %w", err)
+	}
+	metrics.EmailsSent.WithLabelValues(task.Type()).Inc()
+
+	log.Ctx(ctx).Info().
+		Str("task", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("email", user.Email).
+		Msg("processed task")
+
+	return nil
+}
+
+func (r *RedisTaskProcessor) sendLoginAlertEmail(user db.User, session db.Session, payload PayloadSendLoginAlert) error {
+	source := "a new device"
+	switch {
+	case payload.NewDevice && payload.NewClientIP:
+		source = "a new device and IP address"
+	case payload.NewClientIP:
+		source = "a new IP address"
+	}
+
+	// the user agent is whatever the client sent, anything that ends up in the body is escaped
+	device := util.ParseDevice(session.UserAgent)
+	subject := "New login to your Simple Bank account"
+	sessionsUrl := "http://localhost:8080/sessions"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	your account was accessed from %s on %s.<br/>
+	Device: %s %s on %s %s<br/>
+	IP address: %s<br/>
+	If this wasn't you, <a href="%s">revoke the session here</a> and change your password.<br/>`,
+		html.EscapeString(user.FullName), source, session.CreatedAt.UTC().Format(time.RFC1123),
+		html.EscapeString(device.Browser), html.EscapeString(device.BrowserVersion),
+		html.EscapeString(device.Platform), html.EscapeString(device.OS),
+		html.EscapeString(session.ClientIp), sessionsUrl)
+	to := []string{user.Email}
+
+	return r.mailer.Send(subject, content, to, nil, nil, nil)
+}
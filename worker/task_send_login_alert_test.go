@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"github.com/google/uuid"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// recordingMailer keeps the emails instead of sending them
+type recordingMailer struct {
+	bodies []string
+}
+
+func (m *recordingMailer) Send(_, body string, _, _, _, _ []string) error {
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+func TestSendLoginAlertEmailEscapesUserAgent(t *testing.T) {
+	mailer := &recordingMailer{}
+	processor := &RedisTaskProcessor{mailer: mailer}
+
+	user := db.User{Username: util.RandomOwner(), FullName: `<b>Bob</b>`, Email: util.RandomEmail()}
+	session := db.Session{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		UserAgent: `Mozilla/5.0 (<a href="https://evil.example">Windows</a>) Firefox/1.0`,
+		ClientIp:  `<img src=x>`,
+		CreatedAt: time.Now(),
+	}
+
+	err := processor.sendLoginAlertEmail(user, session, PayloadSendLoginAlert{NewDevice: true})
+	require.NoError(t, err)
+	require.Len(t, mailer.bodies, 1)
+
+	body := mailer.bodies[0]
+	require.NotContains(t, body, `<a href="https://evil.example">`)
+	require.Contains(t, body, `&lt;a href=&#34;https://evil.example&#34;&gt;Windows&lt;/a&gt;`)
+	require.Contains(t, body, `&lt;b&gt;Bob&lt;/b&gt;`)
+	require.Contains(t, body, `&lt;img src=x&gt;`)
+}
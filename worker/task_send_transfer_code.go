@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hibiken/asynq"
+	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const TaskSendTransferCode = "task:send_transfer_code"
+
+// PayloadSendTransferCode only carries the challenge id, the code stays in the database
+type PayloadSendTransferCode struct {
+	TaskMetadata
+	ChallengeID int64 `json:"challenge_id"`
+}
+
+func (r *RedisTaskDistributor) DistributeTaskSendTransferCode(ctx context.Context, payload *PayloadSendTransferCode, opts ...asynq.Option) (err error) {
+	ctx, span := startEnqueueSpan(ctx, TaskSendTransferCode)
+	defer func() { endSpan(span, err) }()
+
+	payload.TaskMetadata = newTaskMetadata(ctx)
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to jsonPayload payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendTransferCode, jsonPayload, opts...)
+	info, err := r.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Bytes("payload", jsonPayload).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+func (r *RedisTaskProcessor) ProcessTaskSendTransferCode(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendTransferCode
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("cannot unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	challenge, err := r.store.GetTransferChallenge(ctx, payload.ChallengeID)
+	if err != nil {
+		return fmt.Errorf("cannot get transfer challenge %d: %w", payload.ChallengeID, err)
+	}
+
+	// a retry after the code expired would only send a useless code
+	if challenge.IsUsed || !time.Now().Before(challenge.ExpiredAt) {
+		log.Ctx(ctx).Info().
+			Str("task", task.Type()).
+			Int64("challenge_id", challenge.ID).
+			Msg("skipped expired transfer code")
+		return nil
+	}
+
+	err = r.sendTransferCodeEmail(challenge)
+	if err != nil {
+		return fmt.Errorf("cannot send transfer code email: %w", err)
+	}
+	metrics.EmailsSent.WithLabelValues(task.Type()).Inc()
+
+	log.Ctx(ctx).Info().
+		Str("task", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("email", challenge.Email).
+		Msg("processed task")
+
+	return nil
+}
+
+func (r *RedisTaskProcessor) sendTransferCodeEmail(challenge db.TransferChallenge) error {
+	subject := "Confirm your Simple Bank transfer"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	to confirm the transfer of %d %s from account %d to account %d, enter this code:<br/>
+	<b>%s</b><br/>
+	The code expires at %s. If you didn't start this transfer, change your password.<br/>`,
+		challenge.Username, challenge.Amount, challenge.Currency, challenge.FromAccountID, challenge.ToAccountID,
+		challenge.SecretCode, challenge.ExpiredAt.UTC().Format(time.RFC1123))
+	to := []string{challenge.Email}
+
+	return r.mailer.Send(subject, content, to, nil, nil, nil)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/hibiken/asynq"
 	db "github.com/mariobasic/simplebank/db/sqlc"
+	"github.com/mariobasic/simplebank/metrics"
 	"github.com/mariobasic/simplebank/util"
 	"github.com/rs/zerolog/log"
 )
@@ -13,10 +14,15 @@ import (
 const TaskSendVerifyEmail = "task:send_verify_email"
 
 type PayloadSendVerifyEmail struct {
+	TaskMetadata
 	Username string `json:"username"`
 }
 
-func (r *RedisTaskDistributor) DistributeTaskSendVerifyEmail(ctx context.Context, payload *PayloadSendVerifyEmail, opts ...asynq.Option) error {
+func (r *RedisTaskDistributor) DistributeTaskSendVerifyEmail(ctx context.Context, payload *PayloadSendVerifyEmail, opts ...asynq.Option) (err error) {
+	ctx, span := startEnqueueSpan(ctx, TaskSendVerifyEmail)
+	defer func() { endSpan(span, err) }()
+
+	payload.TaskMetadata = newTaskMetadata(ctx)
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to jsonPayload payload: %w", err)
@@ -27,7 +33,7 @@ func (r *RedisTaskDistributor) DistributeTaskSendVerifyEmail(ctx context.Context
 		return fmt.Errorf("failed to enqueue task: %w", err)
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("type", task.Type()).
 		Bytes("payload", jsonPayload).
 		Str("queue", info.Queue).
@@ -66,8 +72,9 @@ func (r *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, tas
 	if err != nil {
 		return fmt.Errorf("cannot send verify email: %w", err)
 	}
+	metrics.EmailsSent.WithLabelValues(task.Type()).Inc()
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("task", task.Type()).
 		Bytes("payload", task.Payload()).
 		Str("email", user.Email).